@@ -0,0 +1,119 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoField is returned by the accessors of Value for a column missing
+// from the header or the record.
+var ErrNoField = errors.New("no such field")
+
+// A Value is a field of a Record, returned by Record.Field and
+// Record.Named, with accessors converting it. Conversion errors are
+// ConversionErrors.
+type Value struct {
+	s     string
+	index int  // index of the field, or -1 for an unknown column
+	ok    bool // whether the record has the field
+}
+
+// Field returns the i'th field of the record.
+func (r Record) Field(i int) Value {
+	if i < 0 || i >= len(r.Fields) {
+		return Value{index: i}
+	}
+	return Value{s: r.Fields[i], index: i, ok: true}
+}
+
+// Named returns the field of the column called name.
+func (r Record) Named(name string) Value {
+	return r.Field(r.Index(name))
+}
+
+// Int returns the i'th field as an integer.
+func (r Record) Int(i int) (int64, error) { return r.Field(i).Int() }
+
+// Float returns the i'th field as a floating-point number.
+func (r Record) Float(i int) (float64, error) { return r.Field(i).Float() }
+
+// Bool returns the i'th field as a boolean.
+func (r Record) Bool(i int) (bool, error) { return r.Field(i).Bool() }
+
+// Time returns the i'th field as a time in the given layout.
+func (r Record) Time(i int, layout string) (time.Time, error) { return r.Field(i).Time(layout) }
+
+// IsNull reports whether the i'th field is missing or empty.
+func (r Record) IsNull(i int) bool { return r.Field(i).IsNull() }
+
+// String returns the field, or "" if it is missing.
+func (v Value) String() string { return v.s }
+
+// Exists reports whether the record has the field.
+func (v Value) Exists() bool { return v.ok }
+
+// IsNull reports whether the field is missing or empty.
+func (v Value) IsNull() bool { return v.s == "" }
+
+// Int returns the field as an integer. White space around it is ignored.
+func (v Value) Int() (int64, error) {
+	if !v.ok {
+		return 0, v.error(int64Type, ErrNoField)
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(v.s), 10, 64)
+	if err != nil {
+		return 0, v.error(int64Type, err)
+	}
+	return n, nil
+}
+
+// Float returns the field as a floating-point number. White space around
+// it is ignored.
+func (v Value) Float() (float64, error) {
+	if !v.ok {
+		return 0, v.error(float64Type, ErrNoField)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v.s), 64)
+	if err != nil {
+		return 0, v.error(float64Type, err)
+	}
+	return f, nil
+}
+
+// Bool returns the field as a boolean, as accepted by strconv.ParseBool.
+func (v Value) Bool() (bool, error) {
+	if !v.ok {
+		return false, v.error(boolType, ErrNoField)
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(v.s))
+	if err != nil {
+		return false, v.error(boolType, err)
+	}
+	return b, nil
+}
+
+// Time returns the field parsed as a time in the given layout.
+func (v Value) Time(layout string) (time.Time, error) {
+	if !v.ok {
+		return time.Time{}, v.error(timeType, ErrNoField)
+	}
+	t, err := time.Parse(layout, v.s)
+	if err != nil {
+		return time.Time{}, v.error(timeType, err)
+	}
+	return t, nil
+}
+
+var (
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+	boolType    = reflect.TypeOf(false)
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+func (v Value) error(t reflect.Type, err error) error {
+	return &ConversionError{Field: v.index, Value: v.s, Type: t, Err: err}
+}
@@ -0,0 +1,50 @@
+package csv
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAccessors(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("id,price,ok,when,note\n 7 ,1.5,true,2024-02-29,\n"))
+	dec.ReadHeader()
+	r, err := dec.DecodeRecord()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := r.Int(0); n != 7 || err != nil {
+		t.Errorf("Int(0) = %d, %v", n, err)
+	}
+	if f, err := r.Named("price").Float(); f != 1.5 || err != nil {
+		t.Errorf("price = %v, %v", f, err)
+	}
+	if b, err := r.Bool(2); !b || err != nil {
+		t.Errorf("Bool(2) = %v, %v", b, err)
+	}
+	when, err := r.Named("when").Time("2006-01-02")
+	if err != nil || !when.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("when = %v, %v", when, err)
+	}
+	if !r.IsNull(4) || !r.IsNull(9) || r.IsNull(0) {
+		t.Error("IsNull")
+	}
+	if r.Named("note").Exists() != true || r.Named("other").Exists() {
+		t.Error("Exists")
+	}
+
+	_, err = r.Named("ok").Int()
+	var cerr *ConversionError
+	if !errors.As(err, &cerr) || cerr.Field != 2 || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("bad int: %v", err)
+	}
+	if _, err := r.Named("other").Float(); !errors.Is(err, ErrNoField) {
+		t.Errorf("missing column: %v", err)
+	}
+	if _, err := r.Time(7, time.RFC3339); !errors.Is(err, ErrNoField) {
+		t.Errorf("missing field: %v", err)
+	}
+}
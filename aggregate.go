@@ -0,0 +1,383 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An AggFunc is an aggregate function computed over the records of a group.
+type AggFunc int
+
+const (
+	Count AggFunc = iota // number of records
+	Sum                  // sum of the numeric values
+	Min                  // smallest numeric value
+	Max                  // largest numeric value
+	Avg                  // mean of the numeric values
+)
+
+var aggNames = [...]string{"count", "sum", "min", "max", "avg"}
+
+func (f AggFunc) String() string {
+	if f < 0 || int(f) >= len(aggNames) {
+		return "AggFunc(" + strconv.Itoa(int(f)) + ")"
+	}
+	return aggNames[f]
+}
+
+// An Aggregation computes one output column of an aggregate.
+type Aggregation struct {
+	Func   AggFunc
+	Column int    // input column; ignored by Count
+	Name   string // output column name, such as "sum(price)" when empty
+}
+
+// AggregateOptions configures an Aggregator.
+type AggregateOptions struct {
+	// GroupBy selects the key columns. Without keys all records form a
+	// single group.
+	GroupBy      []int
+	Aggregations []Aggregation
+
+	// Dialect describes the syntax of the input of Aggregate.
+	Dialect Dialect
+
+	// MaxGroups, if positive, is the number of groups kept in memory.
+	// Beyond it partial results are spilled to Spill, or to temporary files
+	// in TmpDir if Spill is nil, and merged when the results are read.
+	MaxGroups int
+	TmpDir    string
+	Spill     SpillStore
+}
+
+// An Aggregator groups records by key columns and computes aggregates for
+// each group using hash aggregation.
+//
+// Empty fields are ignored by Sum, Min, Max and Avg; other fields must be
+// numbers.
+type Aggregator struct {
+	opts   AggregateOptions
+	store  SpillStore
+	groups map[string]*aggGroup
+	runs   []string
+}
+
+// An aggGroup holds the key and running state of one group.
+type aggGroup struct {
+	key    []string
+	states []aggState
+}
+
+// An aggState is the running state of one aggregation.
+type aggState struct {
+	rows     int64 // records seen
+	n        int64 // numeric values seen
+	sum      float64
+	min, max float64
+}
+
+// NewAggregator returns an aggregator computing opts.Aggregations.
+func NewAggregator(opts AggregateOptions) *Aggregator {
+	store := opts.Spill
+	if store == nil {
+		store = TempDirStore(opts.TmpDir)
+	}
+	return &Aggregator{
+		opts:   opts,
+		store:  store,
+		groups: make(map[string]*aggGroup),
+	}
+}
+
+// Aggregate reads records from r, whose first record is a header, and
+// writes a header and one record per group to w, sorted by key.
+func Aggregate(r io.Reader, w io.Writer, opts AggregateOptions) error {
+	dec := NewDecoderDialect(r, opts.Dialect)
+	dec.FieldsPerRecord = -1
+	enc := NewEncoder(w)
+
+	if !dec.More() {
+		return nil
+	}
+	header, err := dec.Decode()
+	if err != nil {
+		return err
+	}
+
+	a := NewAggregator(opts)
+	defer a.Close()
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			return err
+		}
+		if err := a.Add(record); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.Encode(a.Header(header)); err != nil {
+		return err
+	}
+	if err := a.Results(enc.Encode); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// Header returns the output header given the input header.
+func (a *Aggregator) Header(header []string) []string {
+	out := make([]string, 0, len(a.opts.GroupBy)+len(a.opts.Aggregations))
+	for _, col := range a.opts.GroupBy {
+		out = append(out, fieldAt(header, col))
+	}
+	for _, agg := range a.opts.Aggregations {
+		name := agg.Name
+		switch {
+		case name != "":
+		case agg.Func == Count:
+			name = agg.Func.String()
+		default:
+			name = agg.Func.String() + "(" + fieldAt(header, agg.Column) + ")"
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// Add adds record to its group.
+func (a *Aggregator) Add(record []string) error {
+	key := make([]string, len(a.opts.GroupBy))
+	for i, col := range a.opts.GroupBy {
+		key[i] = fieldAt(record, col)
+	}
+	id := groupID(key)
+
+	g, ok := a.groups[id]
+	if !ok {
+		if a.opts.MaxGroups > 0 && len(a.groups) >= a.opts.MaxGroups {
+			if err := a.spill(); err != nil {
+				return err
+			}
+		}
+		g = &aggGroup{key: key, states: make([]aggState, len(a.opts.Aggregations))}
+		a.groups[id] = g
+	}
+
+	for i, agg := range a.opts.Aggregations {
+		s := &g.states[i]
+		s.rows++
+		if agg.Func == Count {
+			continue
+		}
+		field := strings.TrimSpace(fieldAt(record, agg.Column))
+		if field == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return &ConversionError{Field: agg.Column, Value: field, Type: reflect.TypeOf(v), Err: err}
+		}
+		s.add(v)
+	}
+	return nil
+}
+
+// Results calls fn with one record per group, sorted by key. The results
+// include every record added so far.
+func (a *Aggregator) Results(fn func([]string) error) error {
+	if len(a.runs) == 0 {
+		for _, g := range a.sorted() {
+			if err := fn(a.format(g)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := a.spill(); err != nil {
+		return err
+	}
+	var cur *aggGroup
+	err := mergeRuns(a.store, a.runs, a.keys(), func(record []string) error {
+		g, err := a.parseSpilled(record)
+		if err != nil {
+			return err
+		}
+		if cur != nil && compareRecords(cur.key, g.key, a.keys()) == 0 {
+			for i := range cur.states {
+				cur.states[i].merge(g.states[i])
+			}
+			return nil
+		}
+		if cur != nil {
+			if err := fn(a.format(cur)); err != nil {
+				return err
+			}
+		}
+		cur = g
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if cur != nil {
+		return fn(a.format(cur))
+	}
+	return nil
+}
+
+// Close removes the spilled groups from the spill store.
+func (a *Aggregator) Close() error {
+	for _, name := range a.runs {
+		a.store.Remove(name)
+	}
+	a.runs = nil
+	return nil
+}
+
+// keys returns the sort keys ordering groups in spilled runs.
+func (a *Aggregator) keys() []SortKey {
+	keys := make([]SortKey, len(a.opts.GroupBy))
+	for i := range keys {
+		keys[i].Column = i
+	}
+	return keys
+}
+
+// sorted returns the in-memory groups sorted by key.
+func (a *Aggregator) sorted() []*aggGroup {
+	groups := make([]*aggGroup, 0, len(a.groups))
+	for _, g := range a.groups {
+		groups = append(groups, g)
+	}
+	keys := a.keys()
+	sort.Slice(groups, func(i, j int) bool {
+		return compareRecords(groups[i].key, groups[j].key, keys) < 0
+	})
+	return groups
+}
+
+// spill writes the partial state of the in-memory groups to a sorted run
+// and forgets them.
+func (a *Aggregator) spill() error {
+	if len(a.groups) == 0 {
+		return nil
+	}
+	groups := a.sorted()
+	records := make([][]string, len(groups))
+	for i, g := range groups {
+		record := append([]string(nil), g.key...)
+		for _, s := range g.states {
+			record = append(record,
+				strconv.FormatInt(s.rows, 10),
+				strconv.FormatInt(s.n, 10),
+				formatFloat(s.sum),
+				formatFloat(s.min),
+				formatFloat(s.max))
+		}
+		records[i] = record
+	}
+	name, err := spillRun(a.store, records, a.keys())
+	if err != nil {
+		return err
+	}
+	a.runs = append(a.runs, name)
+	a.groups = make(map[string]*aggGroup)
+	return nil
+}
+
+// parseSpilled reads back a group written by spill.
+func (a *Aggregator) parseSpilled(record []string) (*aggGroup, error) {
+	nkeys := len(a.opts.GroupBy)
+	g := &aggGroup{key: record[:nkeys], states: make([]aggState, len(a.opts.Aggregations))}
+	var err error
+	for i := range g.states {
+		f := record[nkeys+5*i:]
+		s := &g.states[i]
+		if s.rows, err = strconv.ParseInt(f[0], 10, 64); err != nil {
+			return nil, err
+		}
+		if s.n, err = strconv.ParseInt(f[1], 10, 64); err != nil {
+			return nil, err
+		}
+		if s.sum, err = strconv.ParseFloat(f[2], 64); err != nil {
+			return nil, err
+		}
+		if s.min, err = strconv.ParseFloat(f[3], 64); err != nil {
+			return nil, err
+		}
+		if s.max, err = strconv.ParseFloat(f[4], 64); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// format returns the output record of g.
+func (a *Aggregator) format(g *aggGroup) []string {
+	record := append([]string(nil), g.key...)
+	for i, agg := range a.opts.Aggregations {
+		s := g.states[i]
+		var v string
+		switch {
+		case agg.Func == Count:
+			v = strconv.FormatInt(s.rows, 10)
+		case agg.Func == Sum:
+			v = formatFloat(s.sum)
+		case s.n == 0:
+		case agg.Func == Min:
+			v = formatFloat(s.min)
+		case agg.Func == Max:
+			v = formatFloat(s.max)
+		case agg.Func == Avg:
+			v = formatFloat(s.sum / float64(s.n))
+		}
+		record = append(record, v)
+	}
+	return record
+}
+
+func (s *aggState) add(v float64) {
+	if s.n == 0 || v < s.min {
+		s.min = v
+	}
+	if s.n == 0 || v > s.max {
+		s.max = v
+	}
+	s.n++
+	s.sum += v
+}
+
+func (s *aggState) merge(o aggState) {
+	if o.n > 0 {
+		if s.n == 0 || o.min < s.min {
+			s.min = o.min
+		}
+		if s.n == 0 || o.max > s.max {
+			s.max = o.max
+		}
+	}
+	s.rows += o.rows
+	s.n += o.n
+	s.sum += o.sum
+}
+
+// groupID returns a map key identifying the group with the given key
+// fields.
+func groupID(key []string) string {
+	var b strings.Builder
+	for _, field := range key {
+		b.WriteString(strconv.Itoa(len(field)))
+		b.WriteByte(':')
+		b.WriteString(field)
+	}
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
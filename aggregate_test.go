@@ -0,0 +1,52 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const aggregateInput = `country,city,amount
+US,nyc,10
+FR,paris,5
+US,sf,
+US,nyc,2.5
+DE,berlin,7
+FR,lyon,1
+`
+
+func TestAggregate(t *testing.T) {
+	aggs := []Aggregation{
+		{Func: Count},
+		{Func: Sum, Column: 2},
+		{Func: Min, Column: 2},
+		{Func: Max, Column: 2},
+		{Func: Avg, Column: 2, Name: "mean"},
+	}
+	want := `country,count,sum(amount),min(amount),max(amount),mean
+DE,1,7,7,7,7
+FR,2,6,1,5,3
+US,3,12.5,2.5,10,6.25
+`
+	for _, maxGroups := range []int{0, 1, 2} {
+		var out bytes.Buffer
+		err := Aggregate(strings.NewReader(aggregateInput), &out, AggregateOptions{
+			GroupBy:      []int{0},
+			Aggregations: aggs,
+			MaxGroups:    maxGroups,
+		})
+		if err != nil {
+			t.Errorf("MaxGroups=%d: unexpected error %v", maxGroups, err)
+		} else if out.String() != want {
+			t.Errorf("MaxGroups=%d: out=%q want %q", maxGroups, out.String(), want)
+		}
+	}
+}
+
+func TestAggregateBadNumber(t *testing.T) {
+	a := NewAggregator(AggregateOptions{Aggregations: []Aggregation{{Func: Sum, Column: 1}}})
+	err := a.Add([]string{"x", "ten"})
+	if cerr, ok := err.(*ConversionError); !ok || cerr.Field != 1 {
+		t.Errorf("error %v, want ConversionError for field 1", err)
+	}
+}
@@ -0,0 +1,137 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// OpenAppend opens the named file for appending records with the given
+// header, creating it with the header if it does not exist or is empty.
+// The header of an existing file is read with a decoder set up with opts
+// and must have the same column names as header, in any order: records
+// passed to Encode are in the order of header and are written in the
+// order of the file. A file whose header has other columns is left
+// untouched and a *MismatchError is returned, whose report lists the
+// file's columns missing from header as Missing and the columns of header
+// not in the file as Extra.
+//
+// The encoder writes with the delimiter and the line terminator of the
+// file: \r\n if its last record ends with one, or its header if the last
+// record is unterminated. A new file is written with the delimiter of the
+// dialect set by opts. Close closes the file.
+func OpenAppend(path string, header []string, opts ...Option) (*Encoder, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := newAppendEncoder(f, header, opts)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	enc.closer = f
+	return enc, nil
+}
+
+// newAppendEncoder returns an encoder appending to f, whose header it
+// checks against header.
+func newAppendEncoder(f *os.File, header []string, opts []Option) (*Encoder, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	enc := NewEncoder(f)
+	dec := NewDecoder(io.NewSectionReader(f, 0, fi.Size()))
+	for _, opt := range opts {
+		opt(dec)
+	}
+	if fi.Size() == 0 {
+		enc.Delimiter = dec.Dialect().Delimiter
+		if err := enc.Encode(header); err != nil {
+			return nil, err
+		}
+		return enc, nil
+	}
+
+	existing, err := dec.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+	columns, report := appendColumns(existing, header)
+	if !report.OK() || len(report.Extra) > 0 {
+		return nil, &MismatchError{Report: report}
+	}
+	for i, col := range columns {
+		if col != i {
+			enc.columns = columns
+			break
+		}
+	}
+	enc.Delimiter = dec.Dialect().Delimiter
+	enc.UseCRLF = dec.LineEnding() == "\r\n"
+
+	// end an unterminated last record before appending
+	tail := make([]byte, 2)
+	if fi.Size() < 2 {
+		tail = tail[:1]
+	}
+	if _, err := f.ReadAt(tail, fi.Size()-int64(len(tail))); err != nil {
+		return nil, err
+	}
+	if tail[len(tail)-1] == '\n' {
+		enc.UseCRLF = bytes.HasSuffix(tail, []byte("\r\n"))
+		return enc, nil
+	}
+	nl := "\n"
+	if enc.UseCRLF {
+		nl = "\r\n"
+	}
+	if _, err := io.WriteString(f, nl); err != nil {
+		return nil, err
+	}
+	return enc, nil
+}
+
+// appendColumns returns, for each column of the file header existing, the
+// index of the same column in header.
+func appendColumns(existing, header []string) ([]int, *MismatchReport) {
+	report := &MismatchReport{Duplicates: duplicates(header)}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+	columns := make([]int, len(existing))
+	found := make(map[string]bool, len(existing))
+	for j, name := range existing {
+		i, ok := index[name]
+		if !ok {
+			report.Missing = append(report.Missing, name)
+			continue
+		}
+		columns[j] = i
+		found[name] = true
+	}
+	for _, name := range header {
+		if !found[name] {
+			report.Extra = append(report.Extra, name)
+		}
+	}
+	return columns, report
+}
+
+// mapRecord reorders record, in the order of the header given to
+// OpenAppend, into the order of the file.
+func (e *Encoder) mapRecord(record []string) []string {
+	e.mapped = e.mapped[:0]
+	for _, i := range e.columns {
+		field := ""
+		if i < len(record) {
+			field = record[i]
+		}
+		e.mapped = append(e.mapped, field)
+	}
+	return e.mapped
+}
@@ -0,0 +1,97 @@
+package csv
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenAppend(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.csv")
+	appendRecords := func(header []string, records ...[]string) error {
+		enc, err := OpenAppend(name, header, WithDialect(Dialect{SepDirective: true}))
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			enc.Encode(record)
+		}
+		return enc.Close()
+	}
+
+	if err := appendRecords([]string{"id", "name"}, []string{"1", "ann"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendRecords([]string{"name", "id"}, []string{"bob", "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "id,name\n1,ann\n2,bob\n" {
+		t.Errorf("file %q", b)
+	}
+
+	err := appendRecords([]string{"id", "email"}, []string{"3", "c@example.com"})
+	var merr *MismatchError
+	if !errors.As(err, &merr) {
+		t.Fatalf("error %v, want a MismatchError", err)
+	}
+	if want := (&MismatchReport{Missing: []string{"name"}, Extra: []string{"email"}}); !reflect.DeepEqual(merr.Report, want) {
+		t.Errorf("report %+v, want %+v", merr.Report, want)
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "id,name\n1,ann\n2,bob\n" {
+		t.Errorf("file %q after a mismatch", b)
+	}
+
+	// the delimiter of the file is kept and its last record terminated
+	if err := ioutil.WriteFile(name, []byte("sep=;\nname;id\nann;1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendRecords([]string{"id", "name"}, []string{"2", "b;c"}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "sep=;\nname;id\nann;1\n\"b;c\";2\n" {
+		t.Errorf("file %q", b)
+	}
+}
+
+func TestOpenAppendCRLF(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.csv")
+	for _, tt := range []struct{ in, want string }{
+		{"id,name\r\n1,ann\r\n", "id,name\r\n1,ann\r\n2,bob\r\n"},
+		{"id,name\r\n1,ann", "id,name\r\n1,ann\r\n2,bob\r\n"},
+		{"id,name\n1,ann\n", "id,name\n1,ann\n2,bob\n"},
+	} {
+		if err := ioutil.WriteFile(name, []byte(tt.in), 0644); err != nil {
+			t.Fatal(err)
+		}
+		enc, err := OpenAppend(name, []string{"id", "name"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc.Encode([]string{"2", "bob"})
+		if err := enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := ioutil.ReadFile(name); string(b) != tt.want {
+			t.Errorf("%q: file %q, want %q", tt.in, b, tt.want)
+		}
+	}
+}
+
+func TestOpenAppendDialect(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.csv")
+	for _, record := range [][]string{{"1", "x,y"}, {"2", "z"}} {
+		enc, err := OpenAppend(name, []string{"a", "b"}, WithDialect(Dialect{Delimiter: ';'}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc.Encode(record)
+		if err := enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "a;b\n1;x,y\n2;z\n" {
+		t.Errorf("file %q", b)
+	}
+}
@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes the named file with the records encode writes to
+// the encoder it is given. The records go to a temporary file in the same
+// directory, which is synced to disk and renamed over the named file only
+// once encode has returned nil and the encoder is closed. A job failing or
+// crashing half way therefore leaves either the previous file or none,
+// never a partial one for downstream readers to pick up.
+//
+// The file keeps the permissions of the file it replaces, and is created
+// with mode 0644 otherwise.
+func WriteFileAtomic(path string, encode func(*Encoder) error) error {
+	return writeAtomic(path, func(w io.Writer) error {
+		enc := NewEncoder(w)
+		if err := encode(enc); err != nil {
+			return err
+		}
+		return enc.Close()
+	})
+}
+
+// writeAtomic calls write with a temporary file next to the named file and
+// renames it over the named file if write succeeds. The temporary file is
+// removed otherwise.
+func writeAtomic(name string, write func(io.Writer) error) error {
+	perm := os.FileMode(0644)
+	if fi, err := os.Stat(name); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	dir := filepath.Dir(name)
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	// make the rename durable; not all systems can sync a directory
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package csv
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "out.csv")
+	if err := ioutil.WriteFile(name, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	errStop := errors.New("stop")
+	err := WriteFileAtomic(name, func(enc *Encoder) error {
+		enc.Encode([]string{"a", "b"})
+		enc.Flush()
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("error %v, want %v", err, errStop)
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "old\n" {
+		t.Errorf("file %q after a failed write, want the old contents", b)
+	}
+
+	err = WriteFileAtomic(name, func(enc *Encoder) error {
+		return enc.Encode([]string{"a", "b"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "a,b\n" {
+		t.Errorf("file %q, want %q", b, "a,b\n")
+	}
+	if fi, err := os.Stat(name); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("mode %v, %v, want 0600", fi.Mode(), err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("%d files in the directory, want 1", len(files))
+	}
+}
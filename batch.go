@@ -0,0 +1,120 @@
+package csv
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// batchBuffer holds the records of a batch being decoded: their field
+// bytes, back to back, the offset of each field and the number of fields
+// of each record.
+type batchBuffer struct {
+	buf    []byte
+	starts []int
+	counts []int
+}
+
+// DecodeBatch reads up to n records and returns their fields. The fields
+// of a batch share a few allocations rather than a few per record, which
+// suits forwarding records in bulk to a database or a message queue.
+//
+// If an error stops the batch, DecodeBatch returns the records read before
+// it together with the error. At the end of the input it returns the
+// records read and a nil error, and io.EOF once there are none left.
+func (d *Decoder) DecodeBatch(n int) ([][]string, error) {
+	if n <= 0 {
+		n = 1
+	}
+	b := &d.batch
+	b.buf, b.starts, b.counts = b.buf[:0], b.starts[:0], b.counts[:0]
+	var err error
+	for len(b.counts) < n {
+		if err = d.next(); err != nil {
+			break
+		}
+		if err = d.checkFieldCount(len(d.fieldIndexes)); err != nil {
+			if d.observing() {
+				d.observe(time.Time{}, err)
+			}
+			break
+		}
+		start := len(b.buf)
+		b.buf = append(b.buf, d.lineBuffer.Bytes()...)
+		for _, idx := range d.fieldIndexes {
+			b.starts = append(b.starts, start+idx)
+		}
+		b.counts = append(b.counts, len(d.fieldIndexes))
+	}
+	if len(b.counts) == 0 {
+		return nil, err
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return b.records(), err
+}
+
+// records materializes the records of the batch.
+func (b *batchBuffer) records() [][]string {
+	line := string(b.buf)
+	fields := make([]string, len(b.starts))
+	for i, start := range b.starts {
+		end := len(line)
+		if i+1 < len(b.starts) {
+			end = b.starts[i+1]
+		}
+		fields[i] = line[start:end]
+	}
+	records := make([][]string, len(b.counts))
+	for i, count := range b.counts {
+		records[i] = fields[:count:count]
+		fields = fields[count:]
+	}
+	return records
+}
+
+// A Batch is a batch of records sent by Batches, or the error that
+// stopped decoding.
+type Batch struct {
+	Records [][]string
+	Err     error
+}
+
+// Batches decodes the rest of the input in a goroutine and sends its
+// records on the returned channel in batches of up to n, as DecodeBatch
+// returns them. An error other than io.EOF is sent in the last batch,
+// with the records read before it. The channel is closed at the end of
+// the input or after an error.
+//
+// The caller must receive from the channel until it is closed, or cancel
+// the decoder's Context, which stops the goroutine and closes the channel
+// once the batch being decoded is done. It must not use the decoder
+// meanwhile.
+func (d *Decoder) Batches(n int) <-chan Batch {
+	ctx := d.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out := make(chan Batch, 1)
+	go func() {
+		defer close(out)
+		for {
+			records, err := d.DecodeBatch(n)
+			if err == io.EOF {
+				return
+			}
+			if records != nil || err != nil {
+				select {
+				case out <- Batch{Records: records, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,80 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeBatch(t *testing.T) {
+	in := "id,name\n1,ann\n2,\"b,o\"\n3,\n4,eve\n"
+	for _, size := range []int{1, 4096} {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.SetBufferSize(size)
+		var got [][][]string
+		for {
+			records, err := dec.DecodeBatch(2)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("size %d: %v", size, err)
+			}
+			got = append(got, records)
+		}
+		want := [][][]string{
+			{{"id", "name"}, {"1", "ann"}},
+			{{"2", "b,o"}, {"3", ""}},
+			{{"4", "eve"}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("size %d: batches %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestDecodeBatchError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b\n1,2\n3\n4,5\n"))
+	records, err := dec.DecodeBatch(10)
+	if !errors.Is(err, ErrFieldCount) {
+		t.Errorf("error %v, want %v", err, ErrFieldCount)
+	}
+	if want := [][]string{{"a", "b"}, {"1", "2"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("records %q, want %q", records, want)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a\n1\n2\n3\n\"x\"y\n"))
+	var n int
+	var last error
+	for b := range dec.Batches(2) {
+		n += len(b.Records)
+		last = b.Err
+	}
+	if n != 4 {
+		t.Errorf("%d records, want 4", n)
+	}
+	if !errors.Is(last, ErrQuote) {
+		t.Errorf("last error %v, want %v", last, ErrQuote)
+	}
+}
+
+func TestBatchesCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	dec := NewDecoder(strings.NewReader(strings.Repeat("a\n", 1000)))
+	dec.Context = ctx
+	ch := dec.Batches(1)
+	<-ch
+	cancel()
+	n := 1
+	for range ch {
+		n++
+	}
+	if n >= 1000 {
+		t.Errorf("received all %d batches after cancel", n)
+	}
+}
@@ -0,0 +1,79 @@
+package csv
+
+import "bytes"
+
+// boundaryRecords is the number of records FindRecordBoundary parses to
+// verify a candidate boundary.
+const boundaryRecords = 16
+
+// FindRecordBoundary returns the index of the first byte of a record in
+// buf, which may start anywhere in a stream, even inside a quoted field
+// holding newlines. It returns -1 if no boundary can be established, in
+// which case a larger buf may succeed. A chunk reader can split a stream
+// at the offsets it finds and decode the chunks in parallel.
+//
+// Whether buf starts inside quotes cannot be known, so both cases are
+// tried: each gives the first newline outside quotes as a candidate, and
+// a candidate is verified by decoding the complete records that follow it
+// in buf with the dialect, requiring every one to parse and to have the
+// same number of fields. The candidate that verifies is returned. When
+// both do, the one followed by more records is returned, and -1 if they
+// tie. Like ScanRecords, the heuristic relies on RFC 4180 quoting.
+func FindRecordBoundary(buf []byte, dialect Dialect) int {
+	var best, bestRecords = -1, -1
+	tie := false
+	for _, quoted := range []bool{false, true} {
+		start, end := boundaries(buf, quoted)
+		if start < 0 {
+			continue
+		}
+		n := verifyRecords(buf[start:end], dialect)
+		switch {
+		case n < 0:
+		case n > bestRecords:
+			best, bestRecords, tie = start, n, false
+		case n == bestRecords:
+			tie = true
+		}
+	}
+	if tie {
+		return -1
+	}
+	return best
+}
+
+// boundaries returns the index after the first and the last newline outside
+// quotes in buf, assuming buf starts inside quotes if quoted is true. It
+// returns -1, -1 if there is none.
+func boundaries(buf []byte, quoted bool) (first, last int) {
+	first, last = -1, -1
+	for i, c := range buf {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case '\n':
+			if !quoted {
+				if first < 0 {
+					first = i + 1
+				}
+				last = i + 1
+			}
+		}
+	}
+	return first, last
+}
+
+// verifyRecords decodes the complete records of buf and returns how many
+// were read, up to boundaryRecords, or -1 if they do not all parse with
+// the same number of fields.
+func verifyRecords(buf []byte, dialect Dialect) int {
+	dec := NewDecoderDialect(bytes.NewReader(buf), dialect)
+	n := 0
+	for n < boundaryRecords && dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			return -1
+		}
+		n++
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindRecordBoundary(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 20; i++ {
+		b.WriteString("1,\"multi\nline, \"\"quoted\"\"\nnote\",x\n")
+	}
+	in := b.String()
+	recordLen := len(in) / 20
+
+	for off := 0; off < recordLen; off++ {
+		i := FindRecordBoundary([]byte(in[off:]), Dialect{})
+		if i < 0 {
+			t.Errorf("offset %d: no boundary", off)
+			continue
+		}
+		if (off+i)%recordLen != 0 {
+			t.Errorf("offset %d: boundary at %d, inside a record", off, off+i)
+		}
+	}
+}
+
+func TestFindRecordBoundaryEdges(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"abc", -1},
+		{"c\nd,e\nf,g\n", 2},
+		{"b;c\nd;e\n", 4},
+	}
+	for _, tt := range tests {
+		dialect := Dialect{}
+		if strings.Contains(tt.in, ";") {
+			dialect.Delimiter = ';'
+		}
+		if got := FindRecordBoundary([]byte(tt.in), dialect); got != tt.want {
+			t.Errorf("FindRecordBoundary(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
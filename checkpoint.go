@@ -0,0 +1,270 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// ErrSourceChanged is returned by ResumeFromCheckpoint when the input
+// file differs from the one the checkpoint was written for.
+var ErrSourceChanged = errors.New("csv: input changed since checkpoint")
+
+// errCheckpointManifest is returned by NewCheckpointWriter for a decoder
+// computing a Manifest, whose digest covers input read past the records
+// checkpointed and so cannot be resumed.
+var errCheckpointManifest = errors.New("csv: a decoder computing a manifest cannot be checkpointed")
+
+// checkpointPrefix is the number of bytes at the start of the input whose
+// hash identifies it in a checkpoint.
+const checkpointPrefix = 64 << 10
+
+// A Checkpoint records how far a job has read an input file, so that it
+// can be resumed after a crash. It is stored as JSON.
+type Checkpoint struct {
+	Input   string // name of the input file
+	Offset  int64  // input offset of the next record
+	Records int64  // records read before Offset, including the header
+	Line    int    // lines read before Offset
+
+	// Header is the header of the stream, if one was read. Delimiter is
+	// the delimiter in use, which a sep= line may have set, and
+	// FieldsPerRecord the field count learned from the first record.
+	Header          []string `json:",omitempty"`
+	Delimiter       byte
+	FieldsPerRecord int `json:",omitempty"`
+
+	// TrailerCount and TrailerTotal are the progress of the Trailer check:
+	// the data records counted and the total of its column, a fraction.
+	TrailerCount int64  `json:",omitempty"`
+	TrailerTotal string `json:",omitempty"`
+
+	// Section is the name of the current section of a decoder reading
+	// Sections, and InSection whether a section has started.
+	Section   string `json:",omitempty"`
+	InSection bool   `json:",omitempty"`
+
+	// Metadata is the metadata read from the Frontmatter of the input.
+	Metadata map[string]string `json:",omitempty"`
+
+	// State is the partial state of the job, as returned by the
+	// CheckpointWriter's State function.
+	State json.RawMessage `json:",omitempty"`
+
+	// Size and Prefix identify the input: its size and the SHA-256 hash
+	// of its first 64 KiB.
+	Size   int64
+	Prefix string
+}
+
+// A CheckpointWriter periodically saves the progress of a decoder reading
+// a file to a checkpoint file. The job calls Commit once it is done with
+// each record, so a checkpoint never covers records that were read but
+// not processed.
+type CheckpointWriter struct {
+	// Every is the number of records between checkpoints. Every record
+	// is checkpointed when it is not positive.
+	Every int64
+
+	// State, if not nil, returns the partial state of the job, such as
+	// running totals, saved with each checkpoint. It is marshalled as
+	// JSON.
+	State func() interface{}
+
+	dec   *Decoder
+	path  string
+	input string
+	size  int64
+	hash  string
+	saved int64 // records covered by the last checkpoint
+}
+
+// NewCheckpointWriter returns a CheckpointWriter saving the progress of
+// dec, which reads the file named input, to the file at path. The decoder
+// must not compute a Manifest.
+func NewCheckpointWriter(dec *Decoder, input, path string, every int64) (*CheckpointWriter, error) {
+	if dec.manifest != nil {
+		return nil, errCheckpointManifest
+	}
+	size, hash, err := fingerprintFile(input)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointWriter{
+		Every: every,
+		dec:   dec,
+		path:  path,
+		input: input,
+		size:  size,
+		hash:  hash,
+		saved: dec.RecordNumber(),
+	}, nil
+}
+
+// Commit marks the records decoded so far as processed and saves a
+// checkpoint if Every records were committed since the last one.
+func (w *CheckpointWriter) Commit() error {
+	if w.dec.RecordNumber()-w.saved < w.Every {
+		return nil
+	}
+	return w.Save()
+}
+
+// Save saves a checkpoint of the records decoded so far. The checkpoint
+// file is replaced atomically, so a crash leaves the previous one intact.
+func (w *CheckpointWriter) Save() error {
+	d := w.dec
+	cp := Checkpoint{
+		Input:     w.input,
+		Offset:    d.offset + int64(d.scanp),
+		Records:   d.records,
+		Line:      d.line,
+		Header:    d.header,
+		Delimiter: d.scan.Delimiter,
+		Size:      w.size,
+		Prefix:    w.hash,
+
+		TrailerCount: d.trailerCount,
+		Section:      d.sectionName,
+		InSection:    d.sectionStarted,
+		Metadata:     d.metadata,
+	}
+	if d.trailerTotal.Sign() != 0 {
+		cp.TrailerTotal = d.trailerTotal.RatString()
+	}
+	if d.fieldsLearned {
+		cp.FieldsPerRecord = d.FieldsPerRecord
+	}
+	if w.State != nil {
+		state, err := json.Marshal(w.State())
+		if err != nil {
+			return err
+		}
+		cp.State = state
+	}
+	b, err := json.Marshal(&cp)
+	if err != nil {
+		return err
+	}
+	err = writeAtomic(w.path, func(f io.Writer) error {
+		_, err := f.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	w.saved = cp.Records
+	d.traceEvent("csv.checkpoint", map[string]interface{}{"csv.offset": cp.Offset, "csv.records": cp.Records})
+	d.debug("csv: checkpoint saved", "offset", cp.Offset)
+	return nil
+}
+
+// ReadCheckpoint reads the checkpoint file at path.
+func ReadCheckpoint(path string) (*Checkpoint, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := new(Checkpoint)
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// ResumeFromCheckpoint reads the checkpoint file at path and returns a
+// source continuing to read its input from the first record the
+// checkpoint does not cover, together with the checkpoint. The decoder of
+// the source is set up with opts, as it was for the interrupted job, and
+// carries on with the header, record numbers and line numbers, the
+// progress of the Trailer check, the current section and the metadata of
+// the Frontmatter it had.
+//
+// It fails with ErrSourceChanged if the size of the input or the start of
+// its contents differ from when the checkpoint was written.
+func ResumeFromCheckpoint(path string, opts ...Option) (*DecoderSource, *Checkpoint, error) {
+	cp, err := ReadCheckpoint(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	size, hash, err := fingerprintFile(cp.Input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if size != cp.Size || hash != cp.Prefix {
+		return nil, nil, ErrSourceChanged
+	}
+
+	f, err := os.Open(cp.Input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := f.Seek(cp.Offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	dec := NewDecoder(f)
+	for _, opt := range opts {
+		opt(dec)
+	}
+	if err := dec.resume(cp); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	s := NewDecoderSource(dec, false)
+	s.closer = f
+	return s, cp, nil
+}
+
+// resume sets up the decoder, whose input starts at the checkpoint's
+// offset, to continue the stream the checkpoint was written for.
+func (d *Decoder) resume(cp *Checkpoint) error {
+	d.started = true // the preamble was read before the checkpoint
+	d.offset = cp.Offset
+	d.lineStart = cp.Offset
+	d.line = cp.Line
+	d.records = cp.Records
+	if cp.Delimiter != 0 {
+		d.scan.Delimiter = cp.Delimiter
+	}
+	if cp.FieldsPerRecord > 0 && d.FieldsPerRecord == 0 {
+		d.FieldsPerRecord = cp.FieldsPerRecord
+		d.fieldsLearned = true
+	}
+	if cp.Header != nil {
+		d.setHeader(cp.Header)
+	}
+	d.trailerCount = cp.TrailerCount
+	if cp.TrailerTotal != "" {
+		if _, ok := d.trailerTotal.SetString(cp.TrailerTotal); !ok {
+			return fmt.Errorf("csv: checkpoint: invalid trailer total %q", cp.TrailerTotal)
+		}
+	}
+	d.sectionName, d.sectionStarted = cp.Section, cp.InSection
+	d.metadata = cp.Metadata
+	return nil
+}
+
+// fingerprintFile returns the size of the named file and the hex SHA-256
+// hash of its first checkpointPrefix bytes.
+func fingerprintFile(name string) (int64, string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, io.LimitReader(f, checkpointPrefix)); err != nil {
+		return 0, "", err
+	}
+	return fi.Size(), hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,180 @@
+package csv
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.csv")
+	path := filepath.Join(dir, "job.checkpoint")
+	data := "sep=;\nn;name\n"
+	for i := 1; i <= 10; i++ {
+		data += strconv.Itoa(i) + ";\"row\n" + strconv.Itoa(i) + "\"\n"
+	}
+	if err := ioutil.WriteFile(input, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := OpenFileSource(input, true, WithDialect(Dialect{SepDirective: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := src.Decoder()
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	cw, err := NewCheckpointWriter(dec, input, path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := 0
+	cw.State = func() interface{} { return sum }
+	for i := 1; i <= 5; i++ {
+		var n int
+		if err := dec.DecodeValues(&n, new(string)); err != nil {
+			t.Fatal(err)
+		}
+		sum += n
+		if err := cw.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src.Close() // the job stops after row 5, checkpointed up to row 4
+
+	src, cp, err := ResumeFromCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	if err := json.Unmarshal(cp.State, &sum); err != nil || sum != 10 {
+		t.Errorf("state %s, want 10", cp.State)
+	}
+	var rows []string
+	for {
+		r, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) == 0 {
+			dec := src.Decoder()
+			if dec.RecordNumber() != 6 {
+				t.Errorf("record number %d, want 6", dec.RecordNumber())
+			}
+			if line, _ := dec.FieldPos(1); line != 11 {
+				t.Errorf("line %d, want 11", line)
+			}
+		}
+		n, _ := r.Get("n")
+		rows = append(rows, n)
+	}
+	if want := []string{"5", "6", "7", "8", "9", "10"}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows %q, want %q", rows, want)
+	}
+
+	if err := ioutil.WriteFile(input, []byte("sep=;\nn;name\n0;x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ResumeFromCheckpoint(path); err != ErrSourceChanged {
+		t.Errorf("error %v, want ErrSourceChanged", err)
+	}
+}
+
+func TestCheckpointSaveFailure(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.csv")
+	if err := ioutil.WriteFile(input, []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cw, err := NewCheckpointWriter(NewDecoder(f), input, filepath.Join(dir, "missing", "cp"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cw.Save(); err == nil {
+		t.Error("Save into a missing directory succeeded")
+	}
+	if _, err := NewCheckpointWriter(NewDecoder(f), filepath.Join(dir, "none.csv"), "cp", 1); err == nil {
+		t.Error("NewCheckpointWriter for a missing input succeeded")
+	}
+}
+
+func TestCheckpointResumeState(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.csv")
+	path := filepath.Join(dir, "job.checkpoint")
+	data := "# source: crm\nid,amount\n1,1.5\n2,2\n3,0.25\n4,1\nTRAILER,4,4.75\n"
+	if err := ioutil.WriteFile(input, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setup := func(d *Decoder) {
+		d.Frontmatter = true
+		d.Trailer = &Trailer{Tag: "TRAILER", CountField: 1, TotalField: 2, TotalColumn: 1}
+	}
+
+	src, err := OpenFileSource(input, true, setup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := src.Decoder()
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	cw, err := NewCheckpointWriter(dec, input, path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+		if err := cw.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src.Close()
+
+	src, _, err = ResumeFromCheckpoint(path, setup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	n := 0
+	for {
+		_, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("%d records after resuming, want 2", n)
+	}
+	if m := src.Decoder().Metadata(); m["source"] != "crm" {
+		t.Errorf("metadata %v after resuming", m)
+	}
+
+	dec = NewDecoder(strings.NewReader(data))
+	dec.EnableManifest()
+	if _, err := NewCheckpointWriter(dec, input, path, 1); err == nil {
+		t.Error("checkpointing a decoder computing a manifest succeeded")
+	}
+}
@@ -0,0 +1,65 @@
+package csv
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"strconv"
+)
+
+// ErrChecksum is returned in a ParseError for a record whose checksum
+// field does not match its other fields.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// A RowChecksum computes the checksum of a record. It is given the fields
+// other than the checksum, unquoted and joined by the delimiter, and
+// returns the checksum as written in the file.
+type RowChecksum func(data []byte) string
+
+// CRC32Checksum is the IEEE CRC-32 of a record as 8 hexadecimal digits.
+func CRC32Checksum(data []byte) string {
+	s := strconv.FormatUint(uint64(crc32.ChecksumIEEE(data)), 16)
+	return "00000000"[len(s):] + s
+}
+
+// MD5Checksum is the MD5 of a record as 32 hexadecimal digits.
+func MD5Checksum(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkChecksum verifies and strips the checksum field of the current
+// record. The checksum column of the header is stripped without being
+// verified. A mismatch does not stop the decoder: the record is skipped
+// and the next one can be decoded.
+func (d *Decoder) checkChecksum() error {
+	if d.Checksum == nil {
+		return nil
+	}
+	last := len(d.fieldIndexes) - 1
+	want := d.field(last)
+	if !d.inHeader {
+		d.sumBuf = d.sumBuf[:0]
+		for i := 0; i < last; i++ {
+			if i > 0 {
+				d.sumBuf = append(d.sumBuf, d.scan.Delimiter)
+			}
+			d.sumBuf = append(d.sumBuf, d.field(i)...)
+		}
+		if !bytes.EqualFold(bytes.TrimSpace(want), []byte(d.Checksum(d.sumBuf))) {
+			return &ParseError{
+				Record: d.records,
+				Line:   d.recordLine,
+				Field:  last,
+				Err:    ErrChecksum,
+			}
+		}
+	}
+	if last > 0 {
+		d.lineBuffer.Truncate(d.fieldIndexes[last])
+		d.fieldIndexes = d.fieldIndexes[:last]
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	row := func(fields ...string) string {
+		return strings.Join(fields, ",") + "," + CRC32Checksum([]byte(strings.Join(fields, ","))) + "\n"
+	}
+	input := "id,name,crc\n" +
+		row("1", "Ann") +
+		"2,Bob,00000000\n" +
+		"3,Cy," + strings.ToUpper(CRC32Checksum([]byte("3,Cy"))) + "\n"
+
+	dec := NewDecoder(strings.NewReader(input))
+	dec.Checksum = CRC32Checksum
+	header, err := dec.ReadHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"id", "name"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header %q, want %q", header, want)
+	}
+
+	var out [][]string
+	var bad []int64
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			var perr *ParseError
+			if !errors.As(err, &perr) || !errors.Is(err, ErrChecksum) {
+				t.Fatalf("error %v, want ErrChecksum", err)
+			}
+			bad = append(bad, perr.Record)
+			continue
+		}
+		out = append(out, record)
+	}
+	if want := [][]string{{"1", "Ann"}, {"3", "Cy"}}; !reflect.DeepEqual(out, want) {
+		t.Errorf("records %q, want %q", out, want)
+	}
+	if want := []int64{3}; !reflect.DeepEqual(bad, want) {
+		t.Errorf("mismatches at records %v, want %v", bad, want)
+	}
+}
+
+func TestMD5Checksum(t *testing.T) {
+	in := `"a;1";b;` + MD5Checksum([]byte("a;1;b")) + "\n"
+	dec := NewDecoderDialect(strings.NewReader(in), Dialect{Delimiter: ';'})
+	dec.Checksum = MD5Checksum
+	record, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a;1", "b"}; !reflect.DeepEqual(record, want) {
+		t.Errorf("record %q, want %q", record, want)
+	}
+}
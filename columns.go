@@ -0,0 +1,88 @@
+package csv
+
+// AddColumn returns a transform appending a column called name, whose
+// fields are computed by fn from each record.
+func AddColumn(name string, fn func(Record) string) Transform {
+	return &addColumn{name: name, fn: fn}
+}
+
+type addColumn struct {
+	name  string
+	fn    func(Record) string
+	width int
+}
+
+func (t *addColumn) Header(header []string) ([]string, error) {
+	t.width = len(header)
+	return append(header[:len(header):len(header)], t.name), nil
+}
+
+func (t *addColumn) Apply(r Record) ([]string, error) {
+	v := t.fn(r)
+	for len(r.Fields) < t.width {
+		r.Fields = append(r.Fields, "")
+	}
+	return append(r.Fields[:t.width], v), nil
+}
+
+// DropColumns returns a transform removing the columns called names.
+func DropColumns(names ...string) Transform {
+	return &dropColumns{names: names}
+}
+
+type dropColumns struct {
+	names []string
+	keep  []int
+}
+
+func (t *dropColumns) Header(header []string) ([]string, error) {
+	cols, err := columnIndexes(header, t.names)
+	if err != nil {
+		return nil, err
+	}
+	drop := make(map[int]bool, len(cols))
+	for _, col := range cols {
+		drop[col] = true
+	}
+	var out []string
+	t.keep = t.keep[:0]
+	for i, name := range header {
+		if !drop[i] {
+			t.keep = append(t.keep, i)
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+
+func (t *dropColumns) Apply(r Record) ([]string, error) {
+	out := r.Fields[:0]
+	for _, col := range t.keep {
+		out = append(out, fieldAt(r.Fields, col))
+	}
+	return out, nil
+}
+
+// RenameColumn returns a transform renaming the column called old to
+// name. Records are not changed.
+func RenameColumn(old, name string) Transform {
+	return &renameColumn{old: old, name: name}
+}
+
+type renameColumn struct {
+	old, name string
+}
+
+func (t *renameColumn) Header(header []string) ([]string, error) {
+	cols, err := columnIndexes(header, []string{t.old})
+	if err != nil {
+		return nil, err
+	}
+	out := append([]string(nil), header...)
+	out[cols[0]] = t.name
+	return out, nil
+}
+
+func (t *renameColumn) Apply(r Record) ([]string, error) {
+	return r.Fields, nil
+}
@@ -0,0 +1,50 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestColumnTransforms(t *testing.T) {
+	in := "id,name,email,ssn\n1,Ann,ann@x.org,123\n2,Bob,bob@x.org\n"
+	var out bytes.Buffer
+	err := Reencode(strings.NewReader(in), &out, ReencodeOptions{},
+		DropColumns("ssn", "email"),
+		RenameColumn("name", "first_name"),
+		AddColumn("greeting", func(r Record) string {
+			name, _ := r.Get("first_name")
+			return "hi " + name
+		}),
+		AddColumn("source", func(Record) string { return "crm" }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,first_name,greeting,source\n1,Ann,hi Ann,crm\n2,Bob,hi Bob,crm\n"
+	if got := out.String(); got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+}
+
+func TestAddColumnShortRecord(t *testing.T) {
+	var out bytes.Buffer
+	err := Reencode(strings.NewReader("a,b\n1\n"), &out, ReencodeOptions{},
+		AddColumn("c", func(Record) string { return "x" }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "a,b,c\n1,,x\n"; got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+}
+
+func TestColumnTransformsUnknownColumn(t *testing.T) {
+	for _, tr := range []Transform{DropColumns("x"), RenameColumn("x", "y")} {
+		var out bytes.Buffer
+		err := Reencode(strings.NewReader("a\n1\n"), &out, ReencodeOptions{}, tr)
+		if _, ok := err.(*MismatchError); !ok {
+			t.Errorf("%T: error %v, want MismatchError", tr, err)
+		}
+	}
+}
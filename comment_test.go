@@ -0,0 +1,99 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var commentTests = []struct {
+	Name    string
+	Dialect Dialect
+	Trim    TrimMode
+	Input   string
+	Output  [][]string
+}{
+	{
+		Name:    "Prefix",
+		Dialect: Dialect{CommentPrefix: "//"},
+		Input:   "// header comment\na,b\n/not,a comment\n//\nc,d\n// trailing",
+		Output:  [][]string{{"a", "b"}, {"/not", "a comment"}, {"c", "d"}},
+	},
+	{
+		Name:    "PrefixSplitAcrossReads",
+		Dialect: Dialect{CommentPrefix: "--"},
+		Input:   strings.Repeat("x,y\n", 127) + "a,b\n--c,d\n-e,f\n",
+		Output:  append(repeatRecord([]string{"x", "y"}, 127), []string{"a", "b"}, []string{"-e", "f"}),
+	},
+	{
+		Name:    "NotAfterSpace",
+		Dialect: Dialect{Comment: '#'},
+		Input:   "\t#a,b\n",
+		Output:  [][]string{{"\t#a", "b"}},
+	},
+	{
+		Name:    "Inline",
+		Dialect: Dialect{CommentPrefix: "--", InlineComments: true},
+		Input:   "a,b -- note\n-- full line\nc,-1,\"d--e\"\nf,-\n",
+		Output:  [][]string{{"a", "b "}, {"c", "-1", "d--e"}, {"f", "-"}},
+	},
+	{
+		Name:    "InlineTrimmed",
+		Dialect: Dialect{Comment: '#', InlineComments: true},
+		Trim:    TrimBoth,
+		Input:   "a , b # note\n#x\nc,#\nd",
+		Output:  [][]string{{"a", "b"}, {"c", ""}, {"d"}},
+	},
+	{
+		Name:    "InlinePrefixAtEOF",
+		Dialect: Dialect{CommentPrefix: "//", InlineComments: true},
+		Input:   "a,b/",
+		Output:  [][]string{{"a", "b/"}},
+	},
+}
+
+func repeatRecord(record []string, n int) [][]string {
+	out := make([][]string, n)
+	for i := range out {
+		out[i] = record
+	}
+	return out
+}
+
+func TestComments(t *testing.T) {
+	for _, tt := range commentTests {
+		dec := NewDecoderDialect(strings.NewReader(tt.Input), tt.Dialect)
+		dec.FieldsPerRecord = -1
+		dec.Trim = tt.Trim
+		var out [][]string
+		for dec.More() {
+			record, err := dec.Decode()
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.Name, err)
+				break
+			}
+			out = append(out, record)
+		}
+		if !reflect.DeepEqual(out, tt.Output) {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, tt.Output)
+		}
+		want := tt.Dialect
+		want.Delimiter = ','
+		if d := dec.Dialect(); d != want {
+			t.Errorf("%s: Dialect() = %+v, want %+v", tt.Name, d, want)
+		}
+	}
+}
+
+func TestCommentLineNumbers(t *testing.T) {
+	dec := NewDecoderDialect(strings.NewReader("# one\n# two\na,b\n# three\nc\n"), Dialect{Comment: '#'})
+	var err error
+	for dec.More() {
+		if _, err = dec.Decode(); err != nil {
+			break
+		}
+	}
+	if perr, ok := err.(*ParseError); !ok || perr.Line != 5 || perr.Record != 2 {
+		t.Errorf("error %v, want field count error at record 2, line 5", err)
+	}
+}
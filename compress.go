@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"compress/gzip"
+	"errors"
+	"strconv"
+)
+
+// ErrUnsupportedCompression is returned by Encoder.Compress for an unknown
+// compression format. Formats outside the standard library, such as
+// Zstandard, are written by wrapping the output writer instead.
+var ErrUnsupportedCompression = errors.New("csv: unsupported compression")
+
+// A Compression is a compression format for the output of an Encoder.
+type Compression int
+
+const (
+	NoCompression Compression = iota
+	Gzip
+)
+
+var compressionNames = [...]string{"none", "gzip"}
+
+func (c Compression) String() string {
+	if c < 0 || int(c) >= len(compressionNames) {
+		return "Compression(" + strconv.Itoa(int(c)) + ")"
+	}
+	return compressionNames[c]
+}
+
+// CompressOptions configures the compression of an Encoder's output.
+type CompressOptions struct {
+	// Level is the compression level, from gzip.NoCompression to
+	// gzip.BestCompression, or gzip.DefaultCompression. As with
+	// compress/gzip, 0 stores the records without compressing them.
+	Level int
+	// FlushEvery, if positive, flushes the output after every FlushEvery
+	// records. Each flush ends a compressed block, so a reader of the
+	// output so far can decompress it up to a record boundary.
+	FlushEvery int
+}
+
+// Compress makes the encoder compress its output with c. It must be
+// called before the first record is encoded, and Close must be called
+// after the last one to complete the compressed stream. The Manifest of
+// the encoder describes the compressed output.
+func (e *Encoder) Compress(c Compression, opts CompressOptions) error {
+	e.zw = nil
+	e.flushEvery = opts.FlushEvery
+	switch c {
+	case NoCompression:
+	case Gzip:
+		zw, err := gzip.NewWriterLevel(nil, opts.Level)
+		if err != nil {
+			return err
+		}
+		e.zw = zw
+	default:
+		return ErrUnsupportedCompression
+	}
+	e.setWriter()
+	return nil
+}
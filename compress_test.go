@@ -0,0 +1,84 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+)
+
+func TestEncoderCompress(t *testing.T) {
+	var out bytes.Buffer
+	enc := NewEncoder(&out)
+	if err := enc.Compress(Gzip, CompressOptions{Level: gzip.BestCompression, FlushEvery: 2}); err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range [][]string{{"id", "name"}, {"1", "ann"}, {"2", "bob"}} {
+		if err := enc.Encode(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the first two records were flushed as a complete block
+	zr, err := gzip.NewReader(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(zr)
+	if got, want := string(b), "id,name\n1,ann\n"; got != want {
+		t.Errorf("flushed output %q, want %q", got, want)
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err = gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = ioutil.ReadAll(zr)
+	if got, want := string(b), "id,name\n1,ann\n2,bob\n"; err != nil || got != want {
+		t.Errorf("output %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestEncoderCompressLevel(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 1000)
+	sizes := make(map[int]int)
+	for _, level := range []int{gzip.NoCompression, gzip.DefaultCompression} {
+		var out bytes.Buffer
+		enc := NewEncoder(&out)
+		if err := enc.Compress(Gzip, CompressOptions{Level: level}); err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Encode([]string{string(data)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+		sizes[level] = out.Len()
+	}
+	if sizes[gzip.NoCompression] <= len(data) {
+		t.Errorf("level 0 wrote %d bytes for %d bytes of records, want them stored", sizes[gzip.NoCompression], len(data))
+	}
+	if sizes[gzip.DefaultCompression] >= len(data)/10 {
+		t.Errorf("default level wrote %d bytes, want them compressed", sizes[gzip.DefaultCompression])
+	}
+}
+
+func TestEncoderCompressErrors(t *testing.T) {
+	enc := NewEncoder(ioutil.Discard)
+	if err := enc.Compress(Compression(42), CompressOptions{}); err != ErrUnsupportedCompression {
+		t.Errorf("Compression(42): error %v, want ErrUnsupportedCompression", err)
+	}
+	if err := enc.Compress(Gzip, CompressOptions{Level: 42}); err == nil {
+		t.Error("gzip level 42 accepted")
+	}
+	if s := Gzip.String(); s != "gzip" {
+		t.Errorf("Gzip.String() = %q", s)
+	}
+	if s := Compression(7).String(); s != "Compression(7)" {
+		t.Errorf("Compression(7).String() = %q", s)
+	}
+}
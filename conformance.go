@@ -0,0 +1,111 @@
+package csv
+
+import (
+	"bytes"
+	stdcsv "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ConformanceOptions configures Conformance. The options are those
+// encoding/csv shares with this package.
+type ConformanceOptions struct {
+	Delimiter        byte // comma when 0
+	Comment          byte
+	LazyQuotes       bool
+	TrimLeadingSpace bool
+	FieldsPerRecord  int
+}
+
+// A Divergence is the first record an input is read differently by
+// encoding/csv and by a Decoder.
+type Divergence struct {
+	Record int // 1-based number of the record
+
+	Std    []string // fields read by encoding/csv
+	StdErr error
+	Got    []string // fields read by the Decoder
+	Err    error
+
+	// Known, if not empty, describes the deliberate difference between
+	// the packages the divergence comes from.
+	Known string
+}
+
+// The deliberate differences between the packages.
+const (
+	knownCRLF      = "line breaks in quoted fields are kept as \\r\\n"
+	knownOpenQuote = "a quoted field left open at the end of the input is read"
+)
+
+func (d *Divergence) String() string {
+	s := fmt.Sprintf("record %d: encoding/csv read %q (error %v), Decoder read %q (error %v)",
+		d.Record, d.Std, d.StdErr, d.Got, d.Err)
+	if d.Known != "" {
+		s += " (known: " + d.Known + ")"
+	}
+	return s
+}
+
+// Conformance reads input with encoding/csv and with a Decoder set up with
+// the same options, and returns the first record they read differently,
+// or nil if they agree. Reading stops at the first error; the readers
+// agree on it if both fail on the same record, whatever the error.
+//
+// It is meant to check that a corpus of files reads the same before
+// moving from encoding/csv to this package.
+func Conformance(input []byte, opts ConformanceOptions) *Divergence {
+	std := stdcsv.NewReader(bytes.NewReader(input))
+	if opts.Delimiter != 0 {
+		std.Comma = rune(opts.Delimiter)
+	}
+	std.Comment = rune(opts.Comment)
+	std.LazyQuotes = opts.LazyQuotes
+	std.TrimLeadingSpace = opts.TrimLeadingSpace
+	std.FieldsPerRecord = opts.FieldsPerRecord
+
+	dec := NewDecoderDialect(bytes.NewReader(input), Dialect{
+		Delimiter:        opts.Delimiter,
+		Comment:          opts.Comment,
+		LazyQuotes:       opts.LazyQuotes,
+		TrimLeadingSpace: opts.TrimLeadingSpace,
+	})
+	dec.FieldsPerRecord = opts.FieldsPerRecord
+
+	for n := 1; ; n++ {
+		want, stdErr := std.Read()
+		got, err := dec.Decode()
+		if stdErr == io.EOF && err == io.EOF {
+			return nil
+		}
+		if (stdErr != nil) != (err != nil) || stdErr == io.EOF || err == io.EOF {
+			d := &Divergence{Record: n, Std: want, StdErr: stdErr, Got: got, Err: err}
+			var perr *stdcsv.ParseError
+			if errors.As(stdErr, &perr) && perr.Err == stdcsv.ErrQuote && err == nil && !dec.More() {
+				d.Known = knownOpenQuote
+			}
+			return d
+		}
+		if err != nil {
+			return nil
+		}
+		if !equalRecords(want, got) {
+			d := &Divergence{Record: n, Std: want, Got: got}
+			if equalRecords(want, crlfToLF(got)) {
+				d.Known = knownCRLF
+			}
+			return d
+		}
+	}
+}
+
+// crlfToLF returns fields with \r\n rewritten to \n.
+func crlfToLF(fields []string) []string {
+	out := make([]string, len(fields))
+	for i, f := range fields {
+		out[i] = strings.Replace(f, "\r\n", "\n", -1)
+	}
+	return out
+}
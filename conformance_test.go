@@ -0,0 +1,57 @@
+package csv
+
+import "testing"
+
+var conformanceInputs = []string{
+	"a,b,c\n1,2,3\n",
+	"a,\"b,c\"\n\"x\"\"y\",z\n",
+	"a,b\r\nc,d\r\n",
+	"a,b\n\nc,d",
+	"\"multi\nline\",x\n",
+	"a,b\n1\n",
+	"a,\"b\n",
+	"a\"b,c\n",
+	"# comment\na,b\n",
+	" a, b\n",
+	"\"a\r\nb\",c\n",
+}
+
+var conformanceOptions = []ConformanceOptions{
+	{},
+	{Comment: '#', TrimLeadingSpace: true},
+	{LazyQuotes: true, FieldsPerRecord: -1},
+	{Delimiter: ';'},
+}
+
+func TestConformance(t *testing.T) {
+	for _, in := range conformanceInputs {
+		for _, opts := range conformanceOptions {
+			if d := Conformance([]byte(in), opts); d != nil && d.Known == "" {
+				t.Errorf("%q, %+v: %v", in, opts, d)
+			}
+		}
+	}
+}
+
+func TestConformanceKnown(t *testing.T) {
+	for in, known := range map[string]string{
+		"\"a\r\nb\",c\n": knownCRLF,
+		"a,\"b\n":        knownOpenQuote,
+	} {
+		if d := Conformance([]byte(in), ConformanceOptions{}); d == nil || d.Known != known {
+			t.Errorf("%q: divergence %v, want %q", in, d, known)
+		}
+	}
+}
+
+func FuzzConformance(f *testing.F) {
+	for _, in := range conformanceInputs {
+		f.Add([]byte(in), uint8(0))
+	}
+	f.Fuzz(func(t *testing.T, input []byte, mode uint8) {
+		opts := conformanceOptions[int(mode)%len(conformanceOptions)]
+		if d := Conformance(input, opts); d != nil && d.Known == "" {
+			t.Errorf("%q, %+v: %v", input, opts, d)
+		}
+	})
+}
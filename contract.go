@@ -0,0 +1,252 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// These are the errors of the violations of a Contract. A
+// ContractViolation wraps them, and errors.Is finds them.
+var (
+	ErrMissingColumn = errors.New("missing column")
+	ErrFieldType     = errors.New("wrong type")
+	ErrNoMatch       = errors.New("no match")
+	ErrNotInEnum     = errors.New("value not allowed")
+	ErrRowCount      = errors.New("row count out of range")
+)
+
+// A Contract declares what a stream whose first record is a header must
+// hold, for Enforce to check. Contracts are meant to be written as JSON,
+// or YAML converted to JSON, and kept with the code ingesting the data.
+type Contract struct {
+	// Columns are the columns the header must have. Other columns are
+	// allowed, unless Strict is true.
+	Columns []ContractColumn
+
+	// If Strict is true, the header must have the columns and only them,
+	// in order.
+	Strict bool `json:",omitempty"`
+
+	// MinRows and MaxRows, if positive, bound the number of records
+	// after the header.
+	MinRows int64 `json:",omitempty"`
+	MaxRows int64 `json:",omitempty"`
+
+	// MaxViolations, if positive, is the number of violations kept.
+	MaxViolations int `json:",omitempty"`
+}
+
+// A ContractColumn declares the fields of a column. Empty fields, and
+// fields of only white space, are only checked against Nullable.
+type ContractColumn struct {
+	Name string
+
+	// Type, if not TypeUnknown, is the type every field must have.
+	Type FieldType `json:",omitempty"`
+
+	// Nullable allows empty fields.
+	Nullable bool `json:",omitempty"`
+
+	// Pattern, if not empty, is a regular expression every field must
+	// match whole.
+	Pattern string `json:",omitempty"`
+
+	// Enum, if not empty, lists the values fields may have.
+	Enum []string `json:",omitempty"`
+}
+
+// A ContractViolation is a breach of a Contract. Record and Line are 0
+// for violations of the stream as a whole.
+type ContractViolation struct {
+	Record int64 // 1-based number of the record, as returned by RecordNumber
+	Line   int   // line the record starts on
+	Column string
+	Value  string
+	Err    error
+}
+
+func (v ContractViolation) Error() string {
+	s := ""
+	if v.Record > 0 {
+		s = fmt.Sprintf("record %d, line %d: ", v.Record, v.Line)
+	}
+	if v.Column != "" {
+		s += "column " + v.Column + ": "
+	}
+	if v.Value != "" {
+		s += strconv.Quote(v.Value) + ": "
+	}
+	return s + v.Err.Error()
+}
+
+func (v ContractViolation) Unwrap() error { return v.Err }
+
+// A ContractReport lists the violations of a Contract found by Enforce.
+type ContractReport struct {
+	Rows       int64 // records after the header
+	Violations []ContractViolation
+	// Omitted is the number of violations beyond MaxViolations.
+	Omitted int
+}
+
+// OK reports whether the stream met the contract.
+func (r *ContractReport) OK() bool {
+	return len(r.Violations) == 0 && r.Omitted == 0
+}
+
+// Enforce reads r, whose first record is a header, decoded with opts, and
+// reports where it breaks c. Records may have any number of fields unless
+// opts set FieldsPerRecord; missing fields are empty. Numbers are read in
+// the NumberFormat of their schema column, if opts set a Schema, or of the
+// Decoder. Violations are reported, not returned: the error is that of
+// an invalid contract or of reading r.
+func Enforce(r io.Reader, c Contract, opts ...Option) (*ContractReport, error) {
+	patterns := make([]*regexp.Regexp, len(c.Columns))
+	enums := make([]map[string]bool, len(c.Columns))
+	for i, col := range c.Columns {
+		if col.Pattern != "" {
+			re, err := regexp.Compile("^(?:" + col.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("csv: contract column %q: %v", col.Name, err)
+			}
+			patterns[i] = re
+		}
+		if len(col.Enum) > 0 {
+			enums[i] = make(map[string]bool, len(col.Enum))
+			for _, v := range col.Enum {
+				enums[i][v] = true
+			}
+		}
+	}
+
+	dec := NewDecoder(r)
+	dec.FieldsPerRecord = -1
+	for _, opt := range opts {
+		opt(dec)
+	}
+	rep := &ContractReport{}
+	report := func(v ContractViolation) {
+		if c.MaxViolations > 0 && len(rep.Violations) >= c.MaxViolations {
+			rep.Omitted++
+			return
+		}
+		rep.Violations = append(rep.Violations, v)
+	}
+
+	header, err := dec.ReadHeader()
+	if err == io.EOF {
+		header, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// index[i] is the field of column i, or -1 if it is missing
+	index := make([]int, len(c.Columns))
+	for i, col := range c.Columns {
+		index[i] = -1
+		for j, name := range header {
+			if name == col.Name {
+				index[i] = j
+				break
+			}
+		}
+		if index[i] < 0 {
+			report(ContractViolation{Column: col.Name, Err: ErrMissingColumn})
+		}
+	}
+	if c.Strict {
+		if err := headerDiff(header, c.names()); err != nil {
+			report(ContractViolation{Err: err})
+		}
+	}
+
+	for {
+		fields, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rep, err
+		}
+		rep.Rows++
+		for i, col := range c.Columns {
+			if index[i] < 0 {
+				continue
+			}
+			field := fieldAt(fields, index[i])
+			format := dec.NumberFormat
+			if sc := dec.schemaColumn(index[i]); sc != nil && sc.NumberFormat != nil {
+				format = *sc.NumberFormat
+			}
+			var verr error
+			switch {
+			case strings.TrimSpace(field) == "":
+				if !col.Nullable {
+					verr = ErrEmptyField
+				}
+			case !hasType(field, col.Type, format):
+				verr = fmt.Errorf("%w: want %s", ErrFieldType, col.Type)
+			case patterns[i] != nil && !patterns[i].MatchString(field):
+				verr = fmt.Errorf("%w for %s", ErrNoMatch, col.Pattern)
+			case enums[i] != nil && !enums[i][field]:
+				verr = ErrNotInEnum
+			}
+			if verr != nil {
+				report(ContractViolation{
+					Record: dec.RecordNumber(),
+					Line:   dec.recordLine,
+					Column: col.Name,
+					Value:  field,
+					Err:    verr,
+				})
+			}
+		}
+	}
+
+	if rep.Rows < c.MinRows || c.MaxRows > 0 && rep.Rows > c.MaxRows {
+		report(ContractViolation{Err: fmt.Errorf("%w: %d records, want %s", ErrRowCount, rep.Rows, c.rowRange())})
+	}
+	return rep, nil
+}
+
+// hasType reports whether field is a value of type t, with numbers in
+// format. Booleans are those accepted by strconv.ParseBool, including 1
+// and 0, and every field is a string or of unknown type.
+func hasType(field string, t FieldType, format NumberFormat) bool {
+	var err error
+	switch t {
+	case TypeBool:
+		_, err = strconv.ParseBool(strings.TrimSpace(field))
+	case TypeInt:
+		_, err = strconv.ParseInt(number([]byte(field), format), 10, 64)
+	case TypeFloat:
+		_, err = strconv.ParseFloat(number([]byte(field), format), 64)
+	case TypeTime:
+		_, err = parseTime(field, "", nil)
+	}
+	return err == nil
+}
+
+// names returns the names of the columns of c.
+func (c *Contract) names() []string {
+	names := make([]string, len(c.Columns))
+	for i, col := range c.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// rowRange describes the number of records c allows.
+func (c *Contract) rowRange() string {
+	switch {
+	case c.MaxRows <= 0:
+		return "at least " + strconv.FormatInt(c.MinRows, 10)
+	case c.MinRows <= 0:
+		return "at most " + strconv.FormatInt(c.MaxRows, 10)
+	}
+	return fmt.Sprintf("%d to %d", c.MinRows, c.MaxRows)
+}
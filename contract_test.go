@@ -0,0 +1,117 @@
+package csv
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testContract = `{
+	"Columns": [
+		{"Name": "id", "Type": "int"},
+		{"Name": "status", "Enum": ["open", "closed"]},
+		{"Name": "email", "Nullable": true, "Pattern": "[^@]+@[^@]+"},
+		{"Name": "score", "Type": "float", "Nullable": true}
+	],
+	"MinRows": 1,
+	"MaxRows": 3
+}`
+
+func TestEnforce(t *testing.T) {
+	var c Contract
+	if err := json.Unmarshal([]byte(testContract), &c); err != nil {
+		t.Fatal(err)
+	}
+	in := "id,status,email,score,extra\n" +
+		"1,open,a@example.com,2.5,x\n" +
+		"2,closed,,3,y\n" +
+		"x3,pending,nope,high\n" +
+		" ,open,b@example.com,\n"
+	rep, err := Enforce(strings.NewReader(in), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.OK() || rep.Rows != 4 {
+		t.Fatalf("report %+v", rep)
+	}
+	var got []string
+	for _, v := range rep.Violations {
+		got = append(got, v.Error())
+	}
+	want := []string{
+		`record 4, line 4: column id: "x3": wrong type: want int`,
+		`record 4, line 4: column status: "pending": value not allowed`,
+		`record 4, line 4: column email: "nope": no match for [^@]+@[^@]+`,
+		`record 4, line 4: column score: "high": wrong type: want float`,
+		`record 5, line 5: column id: " ": empty field`,
+		`row count out of range: 4 records, want 1 to 3`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations\n%q\nwant\n%q", got, want)
+	}
+	if !errors.Is(rep.Violations[0], ErrFieldType) {
+		t.Errorf("%v is not ErrFieldType", rep.Violations[0])
+	}
+}
+
+func TestEnforceTypes(t *testing.T) {
+	c := Contract{Columns: []ContractColumn{
+		{Name: "ok", Type: TypeBool},
+		{Name: "n", Type: TypeFloat},
+		{Name: "at", Type: TypeTime, Nullable: true},
+	}}
+	in := "ok,n,at\n1,2,2024-01-02\nfalse,2.5,\nTRUE,1e3, \nyes,true,2024\n"
+	rep, err := Enforce(strings.NewReader(in), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range rep.Violations {
+		got = append(got, v.Column+"="+v.Value)
+	}
+	if want := []string{"ok=yes", "n=true", "at=2024"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("violations %q, want %q", got, want)
+	}
+}
+
+func TestEnforceHeader(t *testing.T) {
+	c := Contract{
+		Columns:       []ContractColumn{{Name: "a"}, {Name: "b"}},
+		Strict:        true,
+		MaxViolations: 2,
+	}
+	rep, err := Enforce(strings.NewReader("b,c\n,2\n"), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Violations) != 2 || rep.Omitted != 1 {
+		t.Fatalf("report %+v", rep)
+	}
+	if v := rep.Violations[0]; v.Column != "a" || !errors.Is(v, ErrMissingColumn) {
+		t.Errorf("violation %v", v)
+	}
+
+	if _, err := Enforce(strings.NewReader("a\n"), Contract{Columns: []ContractColumn{{Name: "a", Pattern: "("}}}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestContractJSON(t *testing.T) {
+	c := Contract{Columns: []ContractColumn{{Name: "when", Type: TypeTime}}, MinRows: 1}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Columns":[{"Name":"when","Type":"time"}],"MinRows":1}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	var back Contract
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, c) {
+		t.Errorf("round trip %+v, want %+v", back, c)
+	}
+}
@@ -0,0 +1,81 @@
+package csv
+
+import "errors"
+
+// ErrControlByte is returned in a ParseError for records holding a NUL or
+// another control byte under ControlError.
+var ErrControlByte = errors.New("control byte in field")
+
+// A ControlPolicy controls how the decoder handles control bytes in
+// fields: NUL and the other ASCII control characters except tab, carriage
+// return and newline, and DEL. They are a sign of binary content.
+type ControlPolicy int
+
+const (
+	// ControlPassThrough returns fields as read, without checking.
+	ControlPassThrough ControlPolicy = iota
+	// ControlError makes Decode fail with ErrControlByte. The Offset of
+	// the ParseError is that of the first control byte.
+	ControlError
+	// ControlStrip removes control bytes from fields.
+	ControlStrip
+)
+
+// isControl reports whether c is a control byte.
+func isControl(c byte) bool {
+	return c < ' ' && c != '\t' && c != '\r' && c != '\n' || c == 0x7f
+}
+
+// indexControl returns the index of the first control byte of b, or -1.
+func indexControl(b []byte) int {
+	for i, c := range b {
+		if isControl(c) {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkControl applies ControlBytes to the current record, whose raw
+// bytes start at buf[start].
+func (d *Decoder) checkControl(start int) error {
+	if d.ControlBytes == ControlPassThrough || indexControl(d.lineBuffer.Bytes()) < 0 {
+		return nil
+	}
+
+	if d.ControlBytes == ControlError {
+		field := 0
+		for field+1 < len(d.fieldIndexes) && indexControl(d.field(field)) < 0 {
+			field++
+		}
+		d.err = ErrControlByte
+		perr := &ParseError{
+			Record: d.records,
+			Line:   d.recordLine,
+			Field:  field,
+			Offset: d.offset + int64(start) - d.spilled,
+			Err:    d.err,
+		}
+		if i := indexControl(d.raw); i >= 0 {
+			perr.Offset = d.offset + int64(start+i)
+			perr.Snippet = snippet(d.buf, start+i)
+		}
+		return perr
+	}
+
+	// strip the control bytes in place
+	line := d.lineBuffer.Bytes()
+	n := 0
+	for i := range d.fieldIndexes {
+		field := d.field(i)
+		d.fieldIndexes[i] = n
+		for _, c := range field {
+			if !isControl(c) {
+				line[n] = c
+				n++
+			}
+		}
+	}
+	d.lineBuffer.Truncate(n)
+	return nil
+}
@@ -0,0 +1,53 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestControlBytes(t *testing.T) {
+	in := "id,data\n1,\"ok\tfine\"\n2,\"a\x00b\nc\"\n3,x\x1by\x7f,z\n"
+	tests := []struct {
+		policy ControlPolicy
+		want   [][]string
+	}{
+		{ControlPassThrough, [][]string{{"id", "data"}, {"1", "ok\tfine"}, {"2", "a\x00b\nc"}, {"3", "x\x1by\x7f", "z"}}},
+		{ControlStrip, [][]string{{"id", "data"}, {"1", "ok\tfine"}, {"2", "ab\nc"}, {"3", "xy", "z"}}},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.ControlBytes = tt.policy
+		dec.FieldsPerRecord = -1
+		var got [][]string
+		for {
+			fields, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, fields)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("policy %d: records %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestControlError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("id,data\n1,\"a\"\"b\x00\"\n"))
+	dec.ControlBytes = ControlError
+	dec.Decode()
+	_, err := dec.Decode()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Err != ErrControlByte {
+		t.Fatalf("error %v, want %v", err, ErrControlByte)
+	}
+	if perr.Record != 2 || perr.Field != 1 || perr.Offset != 15 {
+		t.Errorf("error at record %d, field %d, offset %d, want 2, 1, 15", perr.Record, perr.Field, perr.Offset)
+	}
+}
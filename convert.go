@@ -0,0 +1,160 @@
+package csv
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// A Converter parses the raw bytes of a field into a value of the type it
+// was registered for.
+type Converter func([]byte) (interface{}, error)
+
+// A ConversionError describes a field that could not be converted to the
+// type of its destination.
+type ConversionError struct {
+	Field int          // index of the field in the record
+	Value string       // raw value of the field
+	Type  reflect.Type // type of the destination
+	Err   error        // the actual error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("field %d: cannot convert %q to %s: %s", e.Field, e.Value, e.Type, e.Err)
+}
+
+// Unwrap returns the actual error.
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnsupportedType is returned when a destination has a type for which
+// no conversion exists.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+var errNotStructPointer = errors.New("csv: DecodeStruct argument is not a non-nil pointer to a struct")
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// RegisterConverter registers fn as the converter for destinations of type t.
+// Registered converters take precedence over the built-in conversions in
+// both DecodeValues and DecodeStruct, so they can be used to parse domain
+// types or to override how standard types are read.
+//
+// The value returned by fn must be assignable to t.
+func (d *Decoder) RegisterConverter(t reflect.Type, fn func([]byte) (interface{}, error)) {
+	if d.converters == nil {
+		d.converters = make(map[reflect.Type]Converter)
+	}
+	d.converters[t] = fn
+	d.plans = nil
+}
+
+// DecodeValues reads the next record and converts its fields into the
+// values pointed to by dst, in order. Extra fields are ignored; a record
+// with fewer fields than dst is an error.
+func (d *Decoder) DecodeValues(dst ...interface{}) error {
+	if err := d.next(); err != nil {
+		return err
+	}
+	if err := d.checkFieldCount(len(d.fieldIndexes)); err != nil {
+		return err
+	}
+	if len(d.fieldIndexes) < len(dst) {
+		return d.fieldCountError(len(dst), len(d.fieldIndexes))
+	}
+	for i, p := range dst {
+		v := reflect.ValueOf(p)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return fmt.Errorf("csv: DecodeValues argument %d is not a non-nil pointer", i)
+		}
+		if err := d.convert(v.Elem(), i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeStruct reads the next record into the struct pointed to by v.
+//
+// Exported fields are bound to columns by name when a header has been read
+// with ReadHeader, and by position otherwise. The name of a field is taken
+// from its "csv" tag, falling back to the field name; fields tagged "-" are
+// skipped. Columns without a matching field are ignored. Fields without a
+// matching column are set to their zero value, or make DecodeStruct fail
+// if MissingColumns is MissingError and the field is not tagged
+// "optional", as in `csv:"nick,optional"`.
+func (d *Decoder) DecodeStruct(v interface{}) error {
+	if _, err := structFieldsOf(v); err != nil {
+		return err
+	}
+	if err := d.next(); err != nil {
+		return err
+	}
+	if err := d.checkFieldCount(len(d.fieldIndexes)); err != nil {
+		return err
+	}
+
+	sv := reflect.ValueOf(v).Elem()
+	var missing []string
+	for _, f := range d.plan(sv.Type()).fields {
+		fv := sv.Field(f.index)
+		if f.col < 0 || f.col >= len(d.fieldIndexes) {
+			if f.required && d.MissingColumns == MissingError {
+				missing = append(missing, f.name)
+			}
+			fv.Set(reflect.Zero(fv.Type()))
+			continue
+		}
+		field := sanitize(d.field(f.col), f.schema)
+		if err := f.set(fv, field); err != nil {
+			d.column = 0
+			return d.error(&ConversionError{
+				Field: f.col,
+				Value: string(field),
+				Type:  fv.Type(),
+				Err:   err,
+			})
+		}
+	}
+
+	if len(missing) > 0 {
+		d.column = 0
+		return d.error(&MismatchError{Report: &MismatchReport{Missing: missing}})
+	}
+	return nil
+}
+
+// convert stores the i'th field of the current record into v.
+func (d *Decoder) convert(v reflect.Value, i int) error {
+	col := d.schemaColumn(i)
+	field := sanitize(d.field(i), col)
+	format := d.NumberFormat
+	if col != nil && col.NumberFormat != nil {
+		format = *col.NumberFormat
+	}
+	if err := d.convertValue(v, field, format); err != nil {
+		d.column = 0
+		return d.error(&ConversionError{
+			Field: i,
+			Value: string(field),
+			Type:  v.Type(),
+			Err:   err,
+		})
+	}
+	return nil
+}
+
+// convertValue parses field into v as the setter for its type does.
+// Numbers are read in the given format.
+func (d *Decoder) convertValue(v reflect.Value, field []byte, format NumberFormat) error {
+	return d.setterFor(v.Type(), format)(v, field)
+}
+
+// number returns field as a plain number string in the form accepted by
+// strconv.
+func number(field []byte, format NumberFormat) string {
+	return string(format.normalize(bytes.TrimSpace(field)))
+}
@@ -0,0 +1,222 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type cents int64
+
+func parseCents(b []byte) (interface{}, error) {
+	f, err := strconv.ParseFloat(strings.Replace(string(b), ",", "", -1), 64)
+	if err != nil {
+		return nil, err
+	}
+	return cents(f*100 + 0.5), nil
+}
+
+func TestDecodeValues(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("42,true,2.5,x,\"1,234.56\"\n"))
+	dec.RegisterConverter(reflect.TypeOf(cents(0)), parseCents)
+
+	var (
+		n int
+		b bool
+		f float64
+		s string
+		c cents
+	)
+	if err := dec.DecodeValues(&n, &b, &f, &s, &c); err != nil {
+		t.Fatal(err)
+	}
+	if n != 42 || !b || f != 2.5 || s != "x" || c != 123456 {
+		t.Errorf("got %v %v %v %q %v", n, b, f, s, c)
+	}
+}
+
+func TestDecodeStruct(t *testing.T) {
+	type row struct {
+		Name    string
+		Age     int       `csv:"age"`
+		Balance cents     `csv:"balance"`
+		Seen    time.Time `csv:"seen"`
+		Nick    *string   `csv:"nick"`
+		Skip    string    `csv:"-"`
+	}
+	in := "balance,age,Name,nick,seen\n\"1,000.10\",30,ann,,2017-01-02T03:04:05Z\n"
+	dec := NewDecoder(strings.NewReader(in))
+	dec.RegisterConverter(reflect.TypeOf(cents(0)), parseCents)
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	var r row
+	if err := dec.DecodeStruct(&r); err != nil {
+		t.Fatal(err)
+	}
+	seen := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	if r.Name != "ann" || r.Age != 30 || r.Balance != 100010 || !r.Seen.Equal(seen) || r.Nick != nil {
+		t.Errorf("got %+v", r)
+	}
+}
+
+func TestDecodeStructConversionError(t *testing.T) {
+	var r struct{ N int }
+	dec := NewDecoder(strings.NewReader("abc\n"))
+	err := dec.DecodeStruct(&r)
+
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("error %v, want *ParseError", err)
+	}
+	cerr, ok := perr.Err.(*ConversionError)
+	if !ok || cerr.Field != 0 || cerr.Value != "abc" {
+		t.Errorf("error %#v, want ConversionError for field 0", perr.Err)
+	}
+}
+
+func TestRegisteredConverterError(t *testing.T) {
+	var c cents
+	dec := NewDecoder(strings.NewReader("x\n"))
+	errBad := errors.New("bad")
+	dec.RegisterConverter(reflect.TypeOf(cents(0)), func([]byte) (interface{}, error) { return nil, errBad })
+	err := dec.DecodeValues(&c)
+	if perr, ok := err.(*ParseError); !ok || perr.Err.(*ConversionError).Err != errBad {
+		t.Errorf("error %v, want %v", err, errBad)
+	}
+}
+
+func TestNumberFormat(t *testing.T) {
+	in := "price;qty;ref\n1.234,56;1.000;2,000.5\n"
+	dec := NewDecoder(strings.NewReader(in))
+	dec.scan.Delimiter = ';'
+	dec.NumberFormat = EuropeanNumbers
+	dec.Schema = &Schema{Columns: []Column{
+		{Name: "ref", NumberFormat: &EnglishNumbers},
+	}}
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	var r struct {
+		Price float64 `csv:"price"`
+		Qty   int     `csv:"qty"`
+		Ref   float64 `csv:"ref"`
+	}
+	if err := dec.DecodeStruct(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Price != 1234.56 || r.Qty != 1000 || r.Ref != 2000.5 {
+		t.Errorf("got %+v", r)
+	}
+}
+
+func TestSanitizers(t *testing.T) {
+	in := "price,rate,n\n\"$ 1,200.50\", 12.5 % , 7 \n"
+	dec := NewDecoder(strings.NewReader(in))
+	dec.NumberFormat = EnglishNumbers
+	dec.Schema = &Schema{Columns: []Column{
+		{Name: "price", Sanitizers: []Sanitizer{StripCurrency}},
+		{Name: "rate", Sanitizers: []Sanitizer{StripPercent}},
+		{Name: "n", Sanitizers: []Sanitizer{StripSpace}},
+	}}
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		price, rate float64
+		n           int
+	)
+	if err := dec.DecodeValues(&price, &rate, &n); err != nil {
+		t.Fatal(err)
+	}
+	if price != 1200.5 || rate != 12.5 || n != 7 {
+		t.Errorf("got %v %v %v", price, rate, n)
+	}
+}
+
+func TestStripCurrency(t *testing.T) {
+	for in, want := range map[string]string{
+		"€1.234,00": "1.234,00",
+		"-£5":       "-5",
+		" 10 ¥ ":    "10",
+		"42":        "42",
+	} {
+		if got := string(StripCurrency([]byte(in))); got != want {
+			t.Errorf("StripCurrency(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDuplicateHeaders(t *testing.T) {
+	in := "id,name,id\n1,a,2\n"
+
+	dec := NewDecoder(strings.NewReader(in))
+	dec.DuplicateHeaders = DuplicateSuffix
+	header, err := dec.ReadHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"id", "name", "id_2"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header %q, want %q", header, want)
+	}
+
+	dec = NewDecoder(strings.NewReader("a,a,a_1,a,a_1_2\n"))
+	dec.DuplicateHeaders = DuplicateSuffix
+	header, err = dec.ReadHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "a_1_3", "a_1", "a_3", "a_1_2"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header %q, want %q", header, want)
+	}
+
+	dec = NewDecoder(strings.NewReader(in))
+	dec.DuplicateHeaders = DuplicateError
+	_, err = dec.ReadHeader()
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("error %v, want *ParseError", err)
+	}
+	if merr, ok := perr.Err.(*MismatchError); !ok || !reflect.DeepEqual(merr.Report.Duplicates, []string{"id"}) {
+		t.Errorf("error %v, want duplicate id", perr.Err)
+	}
+}
+
+func TestMissingColumns(t *testing.T) {
+	type row struct {
+		ID   int    `csv:"id"`
+		Name string `csv:"name"`
+		Nick string `csv:"nick,optional"`
+	}
+	in := "id,extra\n1,x\n"
+
+	dec := NewDecoder(strings.NewReader(in))
+	dec.ReadHeader()
+	report, err := dec.Mismatch(&row{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &MismatchReport{Missing: []string{"name"}, Extra: []string{"extra"}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report %+v, want %+v", report, want)
+	}
+
+	r := row{Name: "stale"}
+	if err := dec.DecodeStruct(&r); err != nil || r.ID != 1 || r.Name != "" {
+		t.Errorf("got %+v, %v", r, err)
+	}
+
+	dec = NewDecoder(strings.NewReader(in))
+	dec.MissingColumns = MissingError
+	dec.ReadHeader()
+	err = dec.DecodeStruct(&r)
+	if perr, ok := err.(*ParseError); !ok || !strings.Contains(perr.Error(), "missing columns name") {
+		t.Errorf("error %v, want missing columns name", err)
+	}
+}
@@ -0,0 +1,124 @@
+package csv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// WriteTo implements io.WriterTo. It validates the remaining records of
+// the input and copies them to w byte for byte, without converting their
+// fields to strings. Records rejected by Filter or Sample are not copied.
+// A final record without a line terminator is given one.
+func (d *Decoder) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriterSize(w, 64<<10)
+	n, err := d.copyRecords(bw, nil)
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	return n, err
+}
+
+// copyRecords copies the raw bytes of the remaining records to w, calling
+// counted, if not nil, with the number of fields of each record copied.
+func (d *Decoder) copyRecords(w *bufio.Writer, counted func(fields int)) (int64, error) {
+	d.keepRaw = true
+	defer func() { d.keepRaw = false }()
+	var written int64
+	for d.More() {
+		if err := d.next(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return written, err
+		}
+		if err := d.checkFieldCount(len(d.fieldIndexes)); err != nil {
+			return written, err
+		}
+		n, err := w.Write(d.raw)
+		written += int64(n)
+		if err == nil && (len(d.raw) == 0 || d.raw[len(d.raw)-1] != '\n') {
+			err = w.WriteByte('\n')
+			written++
+		}
+		if err != nil {
+			return written, err
+		}
+		if counted != nil {
+			counted(len(d.fieldIndexes))
+		}
+	}
+	return written, nil
+}
+
+// ReadFrom implements io.ReaderFrom. It validates the records read from r
+// and copies them to the output byte for byte, without converting their
+// fields to strings. The input must use the encoder's delimiter; UseCRLF
+// does not apply to the copied records. If the encoder rewrites fields,
+// with SanitizeFormulas, QuoteFunc or Escape, or has a byte order mark to
+// write, columns to reorder for OpenAppend or a flush cadence set by
+// Compress, the records are decoded and written with Encode instead. It
+// returns the number of bytes read from r.
+func (e *Encoder) ReadFrom(r io.Reader) (int64, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	cr := &countingReader{r: r}
+	dec := NewDecoder(cr)
+	dec.scan.Delimiter = e.Delimiter
+	dec.FieldsPerRecord = -1
+
+	var err error
+	if e.copiesRaw() {
+		var counted func(int)
+		if e.manifest != nil {
+			counted = e.manifest.record
+		}
+		_, err = dec.copyRecords(e.w, counted)
+	} else {
+		err = e.encodeRecords(dec)
+	}
+	if err != nil {
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			e.err = err
+		}
+	}
+	return cr.n, err
+}
+
+// copiesRaw reports whether records can be copied to the output as they
+// were read.
+func (e *Encoder) copiesRaw() bool {
+	return !e.SanitizeFormulas && e.QuoteFunc == nil && !e.Escape &&
+		(!e.WriteBOM || e.started) && e.columns == nil && e.flushEvery == 0
+}
+
+// encodeRecords writes the remaining records of dec with Encode.
+func (e *Encoder) encodeRecords(dec *Decoder) error {
+	for dec.More() {
+		record, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := e.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,85 @@
+package csv
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecoderWriteTo(t *testing.T) {
+	in := "a,b\r\n\"x\ny\",\"q\"\"q\"\n\n1,2"
+	dec := NewDecoder(strings.NewReader(in))
+
+	var out bytes.Buffer
+	n, err := dec.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a,b\r\n\"x\ny\",\"q\"\"q\"\n1,2\n"
+	if out.String() != want || n != int64(len(want)) {
+		t.Errorf("out=%q (%d bytes) want %q", out.String(), n, want)
+	}
+}
+
+func TestDecoderWriteToInvalid(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b\n1,2,3\n"))
+	var out bytes.Buffer
+	_, err := dec.WriteTo(&out)
+	if perr, ok := err.(*ParseError); !ok || perr.Err != ErrFieldCount {
+		t.Errorf("error %v, want %v", err, ErrFieldCount)
+	}
+	if out.String() != "a,b\n" {
+		t.Errorf("out=%q want %q", out.String(), "a,b\n")
+	}
+}
+
+func TestEncoderReadFrom(t *testing.T) {
+	in := "a;b\n1;\"2;3\"\n"
+	var out bytes.Buffer
+	enc := NewEncoder(&out)
+	enc.Delimiter = ';'
+	enc.Encode([]string{"h1", "h2"})
+	n, err := enc.ReadFrom(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Flush()
+	if want := "h1;h2\n" + in; out.String() != want || n != int64(len(in)) {
+		t.Errorf("out=%q (%d bytes read) want %q", out.String(), n, want)
+	}
+}
+
+func TestEncoderReadFromSanitize(t *testing.T) {
+	var out bytes.Buffer
+	enc := NewEncoder(&out)
+	enc.SanitizeFormulas = true
+	if _, err := enc.ReadFrom(strings.NewReader("a,b\n=1+2,\"@x\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	enc.Flush()
+	if want := "a,b\n'=1+2,'@x\n"; out.String() != want {
+		t.Errorf("out=%q want %q", out.String(), want)
+	}
+}
+
+func TestEncoderReadFromAppend(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.csv")
+	if err := ioutil.WriteFile(name, []byte("id,name\n1,ann\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	enc, err := OpenAppend(name, []string{"name", "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := enc.ReadFrom(strings.NewReader("bob,2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != "id,name\n1,ann\n2,bob\n" {
+		t.Errorf("file %q", b)
+	}
+}
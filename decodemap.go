@@ -0,0 +1,39 @@
+package csv
+
+// DecodeMap reads the next record and returns its fields keyed by the
+// column names of the header, which is read first with ReadHeader if none
+// has been read. Columns of a short record are mapped to empty strings,
+// and fields beyond the header are ignored. A name repeated in the header
+// maps to its first column.
+//
+// If ReuseMap is true, the same map is returned by every call and is
+// overwritten by the next one.
+func (d *Decoder) DecodeMap() (map[string]string, error) {
+	if d.header == nil {
+		if _, err := d.ReadHeader(); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.next(); err != nil {
+		return nil, err
+	}
+	if err := d.checkFieldCount(len(d.fieldIndexes)); err != nil {
+		return nil, err
+	}
+
+	m := d.recordMap
+	if m == nil || !d.ReuseMap {
+		m = make(map[string]string, len(d.headerIndex))
+		if d.ReuseMap {
+			d.recordMap = m
+		}
+	}
+	for name, i := range d.headerIndex {
+		if i < len(d.fieldIndexes) {
+			m[name] = string(d.field(i))
+		} else {
+			m[name] = ""
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,43 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMap(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("id,name,id\n1,ann,x\n2,bob,y\n"))
+	m1, err := dec.DecodeMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"id": "1", "name": "ann"}; !reflect.DeepEqual(m1, want) {
+		t.Errorf("first record %q, want %q", m1, want)
+	}
+	m2, _ := dec.DecodeMap()
+	if m1["name"] != "ann" || m2["name"] != "bob" {
+		t.Errorf("maps %q, %q", m1, m2)
+	}
+	if _, err := dec.DecodeMap(); err != io.EOF {
+		t.Errorf("at end: error %v, want io.EOF", err)
+	}
+}
+
+func TestDecodeMapReuse(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b\n1,2\n3\n"))
+	dec.FieldsPerRecord = -1
+	dec.ReuseMap = true
+	m1, _ := dec.DecodeMap()
+	m2, err := dec.DecodeMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.ValueOf(m1).Pointer() != reflect.ValueOf(m2).Pointer() {
+		t.Error("map not reused")
+	}
+	if want := map[string]string{"a": "3", "b": ""}; !reflect.DeepEqual(m2, want) {
+		t.Errorf("short record %q, want %q", m2, want)
+	}
+}
@@ -0,0 +1,113 @@
+package csv
+
+import "io"
+
+// A Dialect describes the syntax of a CSV stream.
+type Dialect struct {
+	// Delimiter is the field delimiter. It is comma (',') when 0.
+	Delimiter byte
+	// Comment, if not 0, is the comment character. Lines beginning with
+	// the Comment character are ignored.
+	Comment byte
+	// CommentPrefix, if not empty, is used instead of Comment for comments
+	// starting with several bytes, such as "//" or "--".
+	CommentPrefix string
+	// If InlineComments is true, a comment may also start after the
+	// beginning of a line, outside quotes, and runs to the end of the line.
+	// The white space before it is kept unless trimmed with Trim.
+	InlineComments bool
+	// If LazyQuotes is true, a quote may appear in an unquoted field and a
+	// non-doubled quote may appear in a quoted field.
+	LazyQuotes bool
+	// If TrimLeadingSpace is true, leading white space in a field is ignored.
+	TrimLeadingSpace bool
+
+	// If SkipBOM is true, a UTF-8 byte order mark at the start of the input
+	// is ignored.
+	SkipBOM bool
+	// If SepDirective is true, a first line such as "sep=;" sets the
+	// delimiter and is not read as a record.
+	SepDirective bool
+	// If UnwrapFormulas is true, a field written as ="0123", which
+	// spreadsheets use to keep leading zeros, is read as 0123.
+	UnwrapFormulas bool
+	// If CRLineEndings is true, a lone \r outside quotes ends a record,
+	// as in files written by classic Mac OS. Otherwise it is read as part
+	// of the field.
+	CRLineEndings bool
+	// If Escapes is true, a backslash in an unquoted field escapes the
+	// next byte, for feeds that escape rather than quote: \n, \r and \t
+	// stand for a newline, a carriage return and a tab, and any other
+	// byte, such as the delimiter, a quote or a backslash, for itself.
+	Escapes bool
+	// If Whitespace is true, fields are separated by runs of spaces and
+	// tabs, as by awk, instead of Delimiter, which is set to space, and
+	// white space at the start and end of lines is ignored. Fields
+	// containing white space must be quoted.
+	Whitespace bool
+}
+
+// NewDecoderDialect returns a new decoder that reads from r using dialect.
+func NewDecoderDialect(r io.Reader, dialect Dialect) *Decoder {
+	d := NewDecoder(r)
+	d.SetDialect(dialect)
+	return d
+}
+
+// Dialect returns the dialect the decoder reads.
+func (d *Decoder) Dialect() Dialect {
+	return Dialect{
+		Delimiter:        d.scan.Delimiter,
+		Comment:          d.scan.Comment,
+		CommentPrefix:    d.dialectCommentPrefix(),
+		InlineComments:   d.scan.InlineComment != "",
+		LazyQuotes:       d.scan.LazyQuotes,
+		TrimLeadingSpace: d.scan.TrimLeadingSpace,
+		SkipBOM:          d.skipBOM,
+		SepDirective:     d.sepDirective,
+		UnwrapFormulas:   d.scan.UnwrapFormulas,
+		CRLineEndings:    d.scan.CRLineEndings,
+		Escapes:          d.scan.Escapes,
+		Whitespace:       d.scan.Whitespace,
+	}
+}
+
+// SetDialect sets the dialect the decoder reads. It should be called
+// before the first record is decoded.
+func (d *Decoder) SetDialect(dialect Dialect) {
+	if dialect.Whitespace {
+		dialect.Delimiter = ' '
+	}
+	if dialect.Delimiter == 0 {
+		dialect.Delimiter = ','
+	}
+	d.scan.Delimiter = dialect.Delimiter
+	d.scan.Comment = dialect.Comment
+	d.commentPrefix = nil
+	switch {
+	case dialect.CommentPrefix != "":
+		d.commentPrefix = []byte(dialect.CommentPrefix)
+	case dialect.Comment != 0:
+		d.commentPrefix = []byte{dialect.Comment}
+	}
+	d.scan.InlineComment = ""
+	if dialect.InlineComments {
+		d.scan.InlineComment = string(d.commentPrefix)
+	}
+	d.scan.LazyQuotes = dialect.LazyQuotes
+	d.scan.TrimLeadingSpace = dialect.TrimLeadingSpace
+	d.skipBOM = dialect.SkipBOM
+	d.sepDirective = dialect.SepDirective
+	d.scan.UnwrapFormulas = dialect.UnwrapFormulas
+	d.scan.CRLineEndings = dialect.CRLineEndings
+	d.scan.Escapes = dialect.Escapes
+	d.scan.Whitespace = dialect.Whitespace
+}
+
+// dialectCommentPrefix returns the CommentPrefix of the decoder's dialect.
+func (d *Decoder) dialectCommentPrefix() string {
+	if d.scan.Comment != 0 && string(d.commentPrefix) == string(d.scan.Comment) {
+		return ""
+	}
+	return string(d.commentPrefix)
+}
@@ -0,0 +1,333 @@
+package csv
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"strconv"
+)
+
+// A DiffOp describes how a record differs between two inputs.
+type DiffOp int
+
+const (
+	DiffAdded   DiffOp = iota // key only in b
+	DiffRemoved               // key only in a
+	DiffChanged               // key in both, with different fields
+)
+
+var diffOpNames = [...]string{"added", "removed", "changed"}
+
+func (op DiffOp) String() string {
+	if op < 0 || int(op) >= len(diffOpNames) {
+		return "DiffOp(" + strconv.Itoa(int(op)) + ")"
+	}
+	return diffOpNames[op]
+}
+
+// A DiffRecord is a difference found by Diff. A holds the record of the
+// first input and B the record of the second; either is nil when the key
+// is missing from that input.
+//
+// If Err is not nil the diff failed, and the record is the last one sent.
+type DiffRecord struct {
+	Op  DiffOp
+	Key []string
+	A   []string
+	B   []string
+	Err error
+}
+
+// DiffOptions configures Diff.
+type DiffOptions struct {
+	// Dialect describes the syntax of both inputs.
+	Dialect Dialect
+
+	// If Sorted is true, both inputs are sorted by their key columns,
+	// compared as strings, and are diffed with a merge holding one record
+	// of each in memory. Differences are then sent in key order.
+	Sorted bool
+
+	// Buckets, if greater than one, bounds the memory used to diff unsorted
+	// inputs: both are first partitioned by a hash of their keys into that
+	// many objects of Spill, or temporary files in TmpDir if Spill is nil,
+	// and only one bucket of b is held in memory at a time. Otherwise all
+	// of b is held in memory.
+	Buckets int
+	TmpDir  string
+	Spill   SpillStore
+
+	// Context, if not nil, stops the diff when it is done: the channel is
+	// closed, after a last DiffRecord with its error if the caller is
+	// still receiving, and the buckets are removed.
+	Context context.Context
+}
+
+// Diff compares the records of two inputs by the key columns keyCols and
+// sends the records that were added, removed or changed on the returned
+// channel, which is closed when the diff is done. Both inputs start with a
+// header, which is not compared. Keys should be unique within an input.
+//
+// Unless the inputs are Sorted, removed and changed records are sent in
+// the order of a, followed by the added records in the order of b, per
+// bucket.
+//
+// The caller must receive from the channel until it is closed, or cancel
+// the Context of opts.
+func Diff(a, b io.Reader, keyCols []int, opts DiffOptions) (<-chan DiffRecord, error) {
+	sa := newJoinSide(a, opts.Dialect)
+	sb := newJoinSide(b, opts.Dialect)
+	if _, err := sa.next(); err != nil {
+		return nil, err
+	}
+	if _, err := sb.next(); err != nil {
+		return nil, err
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	d := &differ{ctx: ctx, keyCols: keyCols, out: make(chan DiffRecord, 64)}
+	go func() {
+		defer close(d.out)
+		var err error
+		switch {
+		case opts.Sorted:
+			err = d.merge(sa, sb)
+		case opts.Buckets > 1:
+			store := opts.Spill
+			if store == nil {
+				store = TempDirStore(opts.TmpDir)
+			}
+			err = d.buckets(sa, sb, opts.Buckets, store)
+		default:
+			err = d.hash(sa, sb)
+		}
+		if err != nil {
+			select {
+			case d.out <- DiffRecord{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return d.out, nil
+}
+
+type differ struct {
+	ctx     context.Context
+	keyCols []int
+	out     chan DiffRecord
+}
+
+func (d *differ) key(record []string) []string {
+	key := make([]string, len(d.keyCols))
+	for i, col := range d.keyCols {
+		key[i] = fieldAt(record, col)
+	}
+	return key
+}
+
+// send sends the difference between ra and rb, if any. It returns the
+// error of the Context if it is done first.
+func (d *differ) send(ra, rb []string) error {
+	var r DiffRecord
+	switch {
+	case ra == nil:
+		r = DiffRecord{Op: DiffAdded, Key: d.key(rb), B: rb}
+	case rb == nil:
+		r = DiffRecord{Op: DiffRemoved, Key: d.key(ra), A: ra}
+	case !equalRecords(ra, rb):
+		r = DiffRecord{Op: DiffChanged, Key: d.key(ra), A: ra, B: rb}
+	default:
+		return nil
+	}
+	select {
+	case d.out <- r:
+		return nil
+	case <-d.ctx.Done():
+		return d.ctx.Err()
+	}
+}
+
+// merge diffs two inputs sorted by key.
+func (d *differ) merge(a, b *joinSide) error {
+	keys := make([]SortKey, len(d.keyCols))
+	for i, col := range d.keyCols {
+		keys[i].Column = col
+	}
+	ra, err := a.next()
+	if err != nil {
+		return err
+	}
+	rb, err := b.next()
+	if err != nil {
+		return err
+	}
+	for ra != nil || rb != nil {
+		c := 0
+		switch {
+		case ra == nil:
+			c = 1
+		case rb == nil:
+			c = -1
+		default:
+			c = compareRecords(ra, rb, keys)
+		}
+		switch {
+		case c < 0:
+			if err = d.send(ra, nil); err == nil {
+				ra, err = a.next()
+			}
+		case c > 0:
+			if err = d.send(nil, rb); err == nil {
+				rb, err = b.next()
+			}
+		default:
+			if err = d.send(ra, rb); err != nil {
+				break
+			}
+			if ra, err = a.next(); err == nil {
+				rb, err = b.next()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hash diffs two inputs by holding the records of b in memory.
+func (d *differ) hash(a, b *joinSide) error {
+	var order []string
+	index := make(map[string][]string)
+	for {
+		rb, err := b.next()
+		if err != nil {
+			return err
+		}
+		if rb == nil {
+			break
+		}
+		id := groupID(d.key(rb))
+		if _, ok := index[id]; !ok {
+			order = append(order, id)
+		}
+		index[id] = rb
+	}
+
+	for {
+		ra, err := a.next()
+		if err != nil {
+			return err
+		}
+		if ra == nil {
+			break
+		}
+		id := groupID(d.key(ra))
+		if err := d.send(ra, index[id]); err != nil {
+			return err
+		}
+		delete(index, id)
+	}
+	for _, id := range order {
+		if rb, ok := index[id]; ok {
+			if err := d.send(nil, rb); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// buckets partitions both inputs by key into n objects of store each and
+// diffs the buckets one at a time.
+func (d *differ) buckets(a, b *joinSide, n int, store SpillStore) error {
+	var names []string
+	defer func() {
+		for _, name := range names {
+			store.Remove(name)
+		}
+	}()
+	partition := func(s *joinSide) ([]string, error) {
+		files := make([]io.WriteCloser, n)
+		encs := make([]*Encoder, n)
+		for i := range files {
+			f, name, err := store.Create("csv-diff-")
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+			files[i], encs[i] = f, NewEncoder(f)
+			defer f.Close()
+		}
+		parts := names[len(names)-n:]
+		for {
+			if err := d.ctx.Err(); err != nil {
+				return nil, err
+			}
+			record, err := s.next()
+			if err != nil {
+				return nil, err
+			}
+			if record == nil {
+				break
+			}
+			h := fnv.New32a()
+			io.WriteString(h, groupID(d.key(record)))
+			if err := encs[h.Sum32()%uint32(n)].Encode(record); err != nil {
+				return nil, err
+			}
+		}
+		for _, enc := range encs {
+			if err := enc.Flush(); err != nil {
+				return nil, err
+			}
+		}
+		return parts, nil
+	}
+
+	partsA, err := partition(a)
+	if err != nil {
+		return err
+	}
+	partsB, err := partition(b)
+	if err != nil {
+		return err
+	}
+	for i := range partsA {
+		if err := d.diffFiles(store, partsA[i], partsB[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffFiles diffs two buckets written by buckets.
+func (d *differ) diffFiles(store SpillStore, a, b string) error {
+	fa, err := store.Open(a)
+	if err != nil {
+		return err
+	}
+	defer fa.Close()
+	fb, err := store.Open(b)
+	if err != nil {
+		return err
+	}
+	defer fb.Close()
+	return d.hash(newJoinSide(fa, Dialect{}), newJoinSide(fb, Dialect{}))
+}
+
+// equalRecords reports whether a and b have the same fields.
+func equalRecords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func collectDiff(t *testing.T, ch <-chan DiffRecord) []DiffRecord {
+	var out []DiffRecord
+	for r := range ch {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	a := "id,name,qty\n1,apple,3\n2,pear,5\n4,plum,1\n5,fig,2\n"
+	b := "id,name,qty\n1,apple,3\n2,pear,6\n3,kiwi,9\n5,fig,2\n6,lime,4\n"
+	want := []DiffRecord{
+		{Op: DiffChanged, Key: []string{"2"}, A: []string{"2", "pear", "5"}, B: []string{"2", "pear", "6"}},
+		{Op: DiffAdded, Key: []string{"3"}, B: []string{"3", "kiwi", "9"}},
+		{Op: DiffRemoved, Key: []string{"4"}, A: []string{"4", "plum", "1"}},
+		{Op: DiffAdded, Key: []string{"6"}, B: []string{"6", "lime", "4"}},
+	}
+
+	for _, opts := range []DiffOptions{
+		{Sorted: true},
+		{},
+		{Buckets: 3, TmpDir: t.TempDir()},
+	} {
+		ch, err := Diff(strings.NewReader(a), strings.NewReader(b), []int{0}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := collectDiff(t, ch)
+		if !opts.Sorted {
+			sort.Slice(out, func(i, j int) bool { return out[i].Key[0] < out[j].Key[0] })
+		}
+		if !reflect.DeepEqual(out, want) {
+			t.Errorf("%+v: diff\n%v\nwant\n%v", opts, out, want)
+		}
+	}
+}
+
+func TestDiffHashOrder(t *testing.T) {
+	a := "k,v\nb,1\na,1\nc,1\n"
+	b := "k,v\nz,1\nc,2\ny,1\n"
+	ch, err := Diff(strings.NewReader(a), strings.NewReader(b), []int{0}, DiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range collectDiff(t, ch) {
+		got = append(got, r.Op.String()+":"+r.Key[0])
+	}
+	want := []string{"removed:b", "removed:a", "changed:c", "added:z", "added:y"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff %q, want %q", got, want)
+	}
+}
+
+func TestDiffError(t *testing.T) {
+	ch, err := Diff(strings.NewReader("k\n1\n"), strings.NewReader("k\na\"b\n"), []int{0}, DiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last DiffRecord
+	for r := range ch {
+		last = r
+	}
+	if last.Err == nil {
+		t.Error("diff of malformed input did not fail")
+	}
+}
+
+func TestDiffCancel(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("k\n")
+	for i := 0; i < 1000; i++ {
+		b.WriteString(strconv.Itoa(i) + "\n")
+	}
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := Diff(strings.NewReader("k\n"), strings.NewReader(b.String()), []int{0},
+		DiffOptions{Buckets: 4, TmpDir: dir, Context: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+	cancel()
+	n := 1
+	var last DiffRecord
+	for r := range ch {
+		last = r
+		n++
+	}
+	if n >= 1000 {
+		t.Errorf("received all %d differences after cancel", n)
+	}
+	if last.Err != nil && !errors.Is(last.Err, context.Canceled) {
+		t.Errorf("error %v, want %v", last.Err, context.Canceled)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("%d buckets left after cancel", len(files))
+	}
+}
@@ -0,0 +1,137 @@
+package csv
+
+import (
+	"math"
+	"sort"
+)
+
+// digestCompression bounds the number of centroids of a digest to about
+// twice its value.
+const digestCompression = 100
+
+// A centroid is the mean of count values next to each other.
+type centroid struct {
+	mean, count float64
+}
+
+// A digest is a merging t-digest, a sketch of the distribution of a stream
+// of numbers. It keeps centroids summarizing the values in sorted order,
+// small at the tails and larger in the middle, so quantiles near 0 and 1
+// are the most accurate.
+type digest struct {
+	centroids []centroid
+	buf       []float64 // values not merged yet
+	n         float64   // number of values, including buf
+	min, max  float64
+}
+
+func (t *digest) add(v float64) {
+	if t.n == 0 || v < t.min {
+		t.min = v
+	}
+	if t.n == 0 || v > t.max {
+		t.max = v
+	}
+	t.n++
+	t.buf = append(t.buf, v)
+	if len(t.buf) >= 5*digestCompression {
+		t.compress()
+	}
+}
+
+// compress merges the buffered values into the centroids.
+func (t *digest) compress() {
+	if len(t.buf) == 0 {
+		return
+	}
+	all := t.centroids
+	for _, v := range t.buf {
+		all = append(all, centroid{v, 1})
+	}
+	t.buf = t.buf[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, 2*digestCompression)
+	cur := all[0]
+	var before float64 // values in the centroids before cur
+	for _, c := range all[1:] {
+		q := (before + (cur.count+c.count)/2) / t.n
+		if cur.count+c.count <= 4*t.n*q*(1-q)/digestCompression {
+			cur.mean += (c.mean - cur.mean) * c.count / (cur.count + c.count)
+			cur.count += c.count
+			continue
+		}
+		merged = append(merged, cur)
+		before += cur.count
+		cur = c
+	}
+	t.centroids = append(merged, cur)
+}
+
+// quantile returns an estimate of the q-quantile of the values,
+// interpolating between the centers of the centroids.
+func (t *digest) quantile(q float64) float64 {
+	t.compress()
+	switch {
+	case t.n == 0:
+		return math.NaN()
+	case q <= 0:
+		return t.min
+	case q >= 1:
+		return t.max
+	}
+	target := q * t.n
+	// the minimum and maximum are centers of zero width at the ends
+	prev, prevPos := t.min, 0.0
+	var pos float64
+	for _, c := range t.centroids {
+		center := pos + c.count/2
+		if target < center {
+			return interpolate(prev, c.mean, prevPos, center, target)
+		}
+		prev, prevPos = c.mean, center
+		pos += c.count
+	}
+	return interpolate(prev, t.max, prevPos, t.n, target)
+}
+
+// cdf returns an estimate of the fraction of the values at most v.
+func (t *digest) cdf(v float64) float64 {
+	t.compress()
+	switch {
+	case t.n == 0:
+		return math.NaN()
+	case v < t.min:
+		return 0
+	case v >= t.max:
+		return 1
+	}
+	prev, prevPos := t.min, 0.0
+	var pos float64
+	for _, c := range t.centroids {
+		center := pos + c.count/2
+		if v < c.mean {
+			return interpolate(prevPos, center, prev, c.mean, v) / t.n
+		}
+		prev, prevPos = c.mean, center
+		pos += c.count
+	}
+	return interpolate(prevPos, t.n, prev, t.max, v) / t.n
+}
+
+// interpolate returns the value at x on the line through (x0, y0) and
+// (x1, y1).
+func interpolate(y0, y1, x0, x1, x float64) float64 {
+	if x1 <= x0 {
+		return y1
+	}
+	return y0 + (y1-y0)*(x-x0)/(x1-x0)
+}
+
+// clone returns a copy of t.
+func (t *digest) clone() *digest {
+	c := *t
+	c.centroids = append([]centroid(nil), t.centroids...)
+	c.buf = append([]float64(nil), t.buf...)
+	return &c
+}
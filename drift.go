@@ -0,0 +1,214 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// A FieldType is the type of the values of a column, as learned or
+// checked by a DriftDetector.
+type FieldType int
+
+const (
+	TypeUnknown FieldType = iota // no value seen yet
+	TypeBool                     // true or false, as accepted by strconv.ParseBool
+	TypeInt                      // integers
+	TypeFloat                    // numbers, including integers
+	TypeTime                     // RFC 3339 timestamps or dates such as 2006-01-02
+	TypeString                   // any text
+)
+
+var fieldTypeNames = [...]string{"unknown", "bool", "int", "float", "time", "string"}
+
+func (t FieldType) String() string {
+	if t < 0 || int(t) >= len(fieldTypeNames) {
+		return "FieldType(" + strconv.Itoa(int(t)) + ")"
+	}
+	return fieldTypeNames[t]
+}
+
+// MarshalText returns the name of t, so types are written by name in
+// JSON.
+func (t FieldType) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(fieldTypeNames) {
+		return nil, fmt.Errorf("csv: invalid %v", t)
+	}
+	return []byte(fieldTypeNames[t]), nil
+}
+
+// UnmarshalText sets t to the type named text.
+func (t *FieldType) UnmarshalText(text []byte) error {
+	for i, name := range fieldTypeNames {
+		if string(text) == name {
+			*t = FieldType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("csv: unknown field type %q", text)
+}
+
+// driftTimeLayouts are the layouts of the values of TypeTime.
+var driftTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// typeOf returns the most specific type accepting field, which is a
+// number in format.
+func typeOf(field []byte, format NumberFormat) FieldType {
+	s := string(field)
+	switch {
+	case len(bytes.TrimSpace(field)) == 0:
+		return TypeUnknown
+	case isBool(s):
+		return TypeBool
+	}
+	n := number(field, format)
+	if _, err := strconv.ParseInt(n, 10, 64); err == nil {
+		return TypeInt
+	}
+	if _, err := strconv.ParseFloat(n, 64); err == nil {
+		return TypeFloat
+	}
+	for _, layout := range driftTimeLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return TypeTime
+		}
+	}
+	return TypeString
+}
+
+func isBool(s string) bool {
+	_, err := strconv.ParseBool(s)
+	// "0" and "1" are integers first
+	return err == nil && s != "0" && s != "1"
+}
+
+// widen returns the most specific type accepting the values of both t
+// and u.
+func widen(t, u FieldType) FieldType {
+	switch {
+	case t == u || u == TypeUnknown:
+		return t
+	case t == TypeUnknown:
+		return u
+	case t == TypeInt && u == TypeFloat, t == TypeFloat && u == TypeInt:
+		return TypeFloat
+	}
+	return TypeString
+}
+
+// A DriftIssue is a field whose value does not have the type of its
+// column.
+type DriftIssue struct {
+	Record int64 // 1-based number of the record, as returned by RecordNumber
+	Line   int   // line the record starts on
+	Field  int   // index of the field
+	Column string
+	Value  string
+	Want   FieldType // type of the column
+	Got    FieldType // type of the value
+}
+
+func (i DriftIssue) String() string {
+	col := strconv.Itoa(i.Field)
+	if i.Column != "" {
+		col = i.Column
+	}
+	return fmt.Sprintf("record %d, line %d: column %s: %q is %s, want %s", i.Record, i.Line, col, i.Value, i.Got, i.Want)
+}
+
+// A DriftReport lists the fields found by a DriftDetector not to have the
+// type of their column.
+type DriftReport struct {
+	// Types are the types of the columns, as given by the schema or
+	// learned.
+	Types  []FieldType
+	Issues []DriftIssue
+	// Omitted is the number of issues beyond MaxIssues.
+	Omitted int
+}
+
+// OK reports whether no drift was found.
+func (r *DriftReport) OK() bool {
+	return len(r.Issues) == 0 && r.Omitted == 0
+}
+
+// defaultDriftLearn is the default number of records a DriftDetector
+// learns column types from.
+const defaultDriftLearn = 100
+
+// A DriftDetector watches the records read by a Decoder, set as its Drift,
+// for values that suddenly do not parse as the type of their column. The
+// type of a column is taken from the Type of its schema column or learned
+// from the first records, as the most specific type accepting all their
+// values. The records after them are checked; empty fields are always
+// accepted. Drift is reported, not treated as an error.
+type DriftDetector struct {
+	// Learn is the number of records after the header the column types
+	// are learned from. It is 100 when 0.
+	Learn int
+	// MaxIssues, if positive, is the number of issues kept.
+	MaxIssues int
+
+	report  DriftReport
+	fixed   []bool // whether the type of a column comes from the schema
+	learned int
+}
+
+// Report returns the drift found so far.
+func (dd *DriftDetector) Report() *DriftReport {
+	return &dd.report
+}
+
+// observe learns from or checks the current record of d.
+func (dd *DriftDetector) observe(d *Decoder) {
+	learn := dd.Learn
+	if learn == 0 {
+		learn = defaultDriftLearn
+	}
+	learning := dd.learned < learn
+	if learning {
+		dd.learned++
+	}
+	rep := &dd.report
+	for i := range d.fieldIndexes {
+		col := d.schemaColumn(i)
+		if i >= len(rep.Types) {
+			rep.Types = append(rep.Types, TypeUnknown)
+			dd.fixed = append(dd.fixed, false)
+			if col != nil && col.Type != TypeUnknown {
+				rep.Types[i], dd.fixed[i] = col.Type, true
+			}
+		}
+		format := d.NumberFormat
+		if col != nil && col.NumberFormat != nil {
+			format = *col.NumberFormat
+		}
+		field := sanitize(d.field(i), col)
+		got := typeOf(field, format)
+		want := rep.Types[i]
+		if learning && !dd.fixed[i] {
+			rep.Types[i] = widen(want, got)
+			continue
+		}
+		if got == TypeUnknown || widen(want, got) == want || want == TypeUnknown {
+			continue
+		}
+		if dd.MaxIssues > 0 && len(rep.Issues) >= dd.MaxIssues {
+			rep.Omitted++
+			continue
+		}
+		issue := DriftIssue{
+			Record: d.records,
+			Line:   d.recordLine,
+			Field:  i,
+			Value:  string(field),
+			Want:   want,
+			Got:    got,
+		}
+		if i < len(d.header) {
+			issue.Column = d.header[i]
+		}
+		rep.Issues = append(rep.Issues, issue)
+	}
+}
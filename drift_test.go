@@ -0,0 +1,84 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDriftDetector(t *testing.T) {
+	in := "id,price,day,note\n" +
+		"1,2.5,2024-01-02,a\n" +
+		"2,3,2024-01-03,\n" +
+		"3,,2024-01-04,c\n" +
+		"x4,4.25,2024-01-05,d\n" +
+		"5,n/a,yesterday,e\n"
+	dec := NewDecoder(strings.NewReader(in))
+	dec.Drift = &DriftDetector{Learn: 3}
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	for dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rep := dec.Drift.Report()
+	if want := []FieldType{TypeInt, TypeFloat, TypeTime, TypeString}; !reflect.DeepEqual(rep.Types, want) {
+		t.Errorf("types %v, want %v", rep.Types, want)
+	}
+	want := []DriftIssue{
+		{Record: 5, Line: 5, Field: 0, Column: "id", Value: "x4", Want: TypeInt, Got: TypeString},
+		{Record: 6, Line: 6, Field: 1, Column: "price", Value: "n/a", Want: TypeFloat, Got: TypeString},
+		{Record: 6, Line: 6, Field: 2, Column: "day", Value: "yesterday", Want: TypeTime, Got: TypeString},
+	}
+	if !reflect.DeepEqual(rep.Issues, want) {
+		t.Errorf("issues %+v, want %+v", rep.Issues, want)
+	}
+	if rep.OK() {
+		t.Error("report OK")
+	}
+	if got, want := want[0].String(), `record 5, line 5: column id: "x4" is string, want int`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDriftDetectorSchema(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("1,true\n2.5,maybe\n\"1,000\",false\n"))
+	dec.Schema = &Schema{Columns: []Column{{Type: TypeInt, NumberFormat: &EnglishNumbers}}}
+	dec.Drift = &DriftDetector{Learn: 1, MaxIssues: 1}
+	for dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rep := dec.Drift.Report()
+	if want := []FieldType{TypeInt, TypeBool}; !reflect.DeepEqual(rep.Types, want) {
+		t.Errorf("types %v, want %v", rep.Types, want)
+	}
+	// "2.5" and "maybe" drift; "1,000" is an integer in English format
+	if len(rep.Issues) != 1 || rep.Issues[0].Value != "2.5" || rep.Omitted != 1 {
+		t.Errorf("issues %+v, omitted %d", rep.Issues, rep.Omitted)
+	}
+}
+
+func TestDriftDetectorSkipColumns(t *testing.T) {
+	in := "id,blob,price\n1,x,2.5\n2,y,3\n3,z,4\n"
+	dd := &DriftDetector{Learn: 2}
+	dec := NewDecoder(strings.NewReader(in))
+	dec.SkipColumns([]int{1})
+	dec.Drift = dd
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	for dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rep := dd.Report()
+	if want := []FieldType{TypeInt, TypeFloat}; !reflect.DeepEqual(rep.Types, want) {
+		t.Errorf("types %v, want only the kept columns %v", rep.Types, want)
+	}
+}
@@ -0,0 +1,98 @@
+package csv
+
+import (
+	"io"
+	"os"
+)
+
+// EditFile streams the named file, whose first record is a header,
+// through fn and replaces it atomically with the result. fn is called
+// with each record after the header and returns the record to write in
+// its place and whether to keep it; an error stops the edit and leaves
+// the file as it was.
+//
+// Whatever fn does not change is copied byte for byte: the header, the
+// records fn returns with the same fields, and what lies between records,
+// such as a byte order mark, a "sep=" line, comments and blank lines.
+// Changed records are written with the delimiter and line terminator of
+// the record they replace. The file is decoded with opts, and records
+// may have any number of fields unless opts set FieldsPerRecord.
+func EditFile(path string, fn func(Record) (Record, bool, error), opts ...Option) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	dec := NewDecoder(f)
+	dec.FieldsPerRecord = -1
+	for _, opt := range opts {
+		opt(dec)
+	}
+	dec.keepRaw = true
+
+	return writeAtomic(path, func(w io.Writer) error {
+		enc := NewEncoder(w)
+		var pos int64 // input bytes written so far
+
+		// copyTo copies the input from pos up to end, then the current
+		// record if raw is true.
+		copyTo := func(end int64, raw bool) error {
+			if end > pos {
+				if _, err := io.Copy(enc.w, io.NewSectionReader(f, pos, end-pos)); err != nil {
+					return err
+				}
+			}
+			pos = end + int64(len(dec.raw))
+			if raw {
+				_, err := enc.w.Write(dec.raw)
+				return err
+			}
+			return nil
+		}
+
+		var header []string
+		for {
+			fields, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			start := dec.offset + int64(dec.scanp) - int64(len(dec.raw))
+			if header == nil {
+				header = fields
+				enc.Delimiter = dec.scan.Delimiter
+				if err := copyTo(start, true); err != nil {
+					return err
+				}
+				continue
+			}
+
+			orig := append([]string(nil), fields...)
+			r, keep, err := fn(NewRecord(header, fields))
+			if err != nil {
+				dec.column = 0 // report at start of record
+				return dec.error(err)
+			}
+			unchanged := keep && equalRecords(r.Fields, orig)
+			if err := copyTo(start, unchanged); err != nil {
+				return err
+			}
+			if keep && !unchanged {
+				if err := enc.EncodeLine(r.Fields, dec.LineEnding()); err != nil {
+					return err
+				}
+			}
+		}
+		if err := copyTo(fi.Size(), false); err != nil {
+			return err
+		}
+		return enc.Close()
+	})
+}
@@ -0,0 +1,76 @@
+package csv
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "people.csv")
+	in := "\ufeffsep=;\r\nid;name;city\r\n# checked\r\n1;\"ann\";paris\r\n2;bob;\"new\nyork\"\r\n\r\n3;cid;rome\r\n4;dan;oslo"
+	if err := ioutil.WriteFile(name, []byte(in), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = EditFile(name, func(r Record) (Record, bool, error) {
+		switch r.Fields[0] {
+		case "2":
+			r.Fields[2] = "nyc;us"
+		case "3":
+			return r, false, nil
+		case "4":
+			r.Fields[1] = "Dan"
+		}
+		return r, true, nil
+	}, WithDialect(Dialect{Comment: '#', SkipBOM: true, SepDirective: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadFile(name)
+	want := "\ufeffsep=;\r\nid;name;city\r\n# checked\r\n1;\"ann\";paris\r\n2;bob;\"nyc;us\"\r\n\r\n4;Dan;oslo"
+	if string(b) != want {
+		t.Errorf("edited %q, want %q", b, want)
+	}
+	if fi, _ := os.Stat(name); fi.Mode().Perm() != 0600 {
+		t.Errorf("mode %v, want 0600", fi.Mode().Perm())
+	}
+}
+
+func TestEditFileError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "edit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "people.csv")
+	in := "id,name\n1,ann\n2,bob\n"
+	if err := ioutil.WriteFile(name, []byte(in), 0644); err != nil {
+		t.Fatal(err)
+	}
+	errBad := errors.New("bad name")
+	err = EditFile(name, func(r Record) (Record, bool, error) {
+		if r.Fields[1] == "bob" {
+			return r, false, errBad
+		}
+		r.Fields[1] = strings.ToUpper(r.Fields[1])
+		return r, true, nil
+	})
+	var perr *ParseError
+	if !errors.Is(err, errBad) || !errors.As(err, &perr) || perr.Line != 3 {
+		t.Errorf("error %v, want %v on line 3", err, errBad)
+	}
+	if b, _ := ioutil.ReadFile(name); string(b) != in {
+		t.Errorf("file changed to %q", b)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("%d files left in the directory", len(files))
+	}
+}
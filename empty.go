@@ -0,0 +1,53 @@
+package csv
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrEmptyRecord is returned for a blank line when EmptyRecords is
+// ErrorEmpty.
+var ErrEmptyRecord = errors.New("empty record")
+
+// An EmptyRecordPolicy tells a decoder what to do with blank lines.
+type EmptyRecordPolicy int
+
+const (
+	SkipEmpty  EmptyRecordPolicy = iota // skip them, as encoding/csv does
+	EmitEmpty                           // return them as records without fields
+	ErrorEmpty                          // reject them with ErrEmptyRecord
+)
+
+var emptyRecordPolicyNames = [...]string{"skip", "emit", "error"}
+
+func (p EmptyRecordPolicy) String() string {
+	if p < 0 || int(p) >= len(emptyRecordPolicyNames) {
+		return "EmptyRecordPolicy(" + strconv.Itoa(int(p)) + ")"
+	}
+	return emptyRecordPolicyNames[p]
+}
+
+// stopAtEmpty reports whether peek must stop at a blank line so that it
+// is read as a record.
+func (d *Decoder) stopAtEmpty() bool {
+	return d.EmptyRecords != SkipEmpty && !d.Sections && !d.midLine && !d.inComment
+}
+
+// checkEmpty applies EmptyRecords to the current record if it is a blank
+// line, which is left without fields. It reports whether it was.
+func (d *Decoder) checkEmpty() (bool, error) {
+	if d.EmptyRecords == SkipEmpty || len(d.fieldIndexes) != 1 || d.lineBuffer.Len() != 0 {
+		return false, nil
+	}
+	for _, c := range d.raw {
+		if c != '\r' && c != '\n' {
+			return false, nil
+		}
+	}
+	d.fieldIndexes = d.fieldIndexes[:0]
+	if d.EmptyRecords == ErrorEmpty {
+		d.column = 0 // report at start of record
+		return true, d.error(ErrEmptyRecord)
+	}
+	return true, nil
+}
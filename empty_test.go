@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEmptyRecords(t *testing.T) {
+	in := "a,b\n\n1,2\r\n\r\n\n3,4\n\n"
+	tests := []struct {
+		policy EmptyRecordPolicy
+		want   [][]string
+		errs   int
+	}{
+		{SkipEmpty, [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}}, 0},
+		{EmitEmpty, [][]string{{"a", "b"}, {}, {"1", "2"}, {}, {}, {"3", "4"}, {}}, 0},
+		{ErrorEmpty, [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}}, 4},
+	}
+	for _, tt := range tests {
+		for _, size := range []int{1, 4096} {
+			dec := NewDecoder(strings.NewReader(in))
+			dec.SetBufferSize(size)
+			dec.EmptyRecords = tt.policy
+			var got [][]string
+			errs := 0
+			for {
+				fields, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if errors.Is(err, ErrEmptyRecord) {
+					errs++
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%v: %v", tt.policy, err)
+				}
+				got = append(got, fields)
+			}
+			if !reflect.DeepEqual(got, tt.want) || errs != tt.errs {
+				t.Errorf("%v, size %d: records %q and %d errors, want %q and %d", tt.policy, size, got, errs, tt.want, tt.errs)
+			}
+		}
+	}
+}
+
+func TestEmptyRecordPosition(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a\n# c\n\nb\n"))
+	dec.SetDialect(Dialect{Comment: '#'})
+	dec.EmptyRecords = ErrorEmpty
+	dec.Decode()
+	_, err := dec.Decode()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 3 || perr.Record != 2 {
+		t.Errorf("error %v, want an empty record on line 3", err)
+	}
+	if fields, err := dec.Decode(); err != nil || fields[0] != "b" {
+		t.Errorf("Decode() = %q, %v after an empty record", fields, err)
+	}
+}
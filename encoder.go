@@ -0,0 +1,265 @@
+package csv
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// An Encoder writes CSV records to an output stream.
+//
+// Records are buffered; Flush must be called to make sure all of them
+// have been written to the underlying io.Writer.
+type Encoder struct {
+	// Delimiter is the field delimiter.
+	// It is set to comma (',') by NewEncoder.
+	Delimiter byte
+	// If UseCRLF is true, records are terminated by \r\n instead of \n.
+	UseCRLF bool
+	// If WriteBOM is true, a UTF-8 byte order mark is written before the
+	// first record, so spreadsheets detect the encoding.
+	WriteBOM bool
+
+	// If SanitizeFormulas is true, fields starting with '=', '+', '-', '@',
+	// tab or carriage return are prefixed with FormulaPrefix, so
+	// spreadsheets opening the output do not evaluate them as formulas.
+	SanitizeFormulas bool
+	// FormulaPrefix is the text written before such fields. It is a single
+	// quote (') when empty.
+	FormulaPrefix string
+
+	// QuoteFunc, if not nil, reports whether the field in column col
+	// (0-based) must be quoted, for systems requiring some columns to be
+	// quoted whatever their contents. Fields are still quoted when needed
+	// to be read back, whatever QuoteFunc returns.
+	QuoteFunc func(col int, field string) bool
+
+	// If Escape is true, fields are written unquoted with backslash
+	// escapes, as read by a decoder whose dialect sets Escapes: newlines,
+	// carriage returns and tabs are written as \n, \r and \t, and the
+	// delimiter, quotes, backslashes and a leading space are preceded by
+	// a backslash. Fields QuoteFunc selects are still quoted.
+	Escape bool
+
+	out io.Writer // the underlying writer
+	w   *bufio.Writer
+	err error
+
+	// whether a record has been written
+	started bool
+
+	// manifest of the output, when enabled by EnableManifest
+	manifest *manifestCounter
+
+	// compressor set by Compress, and the records between flushes
+	zw         *gzip.Writer
+	flushEvery int
+	unflushed  int
+
+	// column order and file of an encoder returned by OpenAppend
+	columns []int
+	mapped  []string
+	closer  io.Closer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		Delimiter: ',',
+		out:       w,
+		w:         bufio.NewWriter(w),
+	}
+}
+
+// Tee makes the encoder write its output to each of w as well as to the
+// writer it was created with, so a single pass can produce, for example,
+// the output and an audit copy, or compressed and uncompressed versions.
+// It must be called before the first record is encoded. A write error on
+// any of the writers stops the encoder. Closing the writers in w, such as
+// a gzip.Writer, after the last Flush is left to the caller.
+func (e *Encoder) Tee(w ...io.Writer) {
+	e.out = io.MultiWriter(append([]io.Writer{e.out}, w...)...)
+	e.setWriter()
+}
+
+// setWriter rebuilds the writers between the buffer and out: the
+// compressor, if any, and the manifest counter.
+func (e *Encoder) setWriter() {
+	w := e.out
+	if e.manifest != nil {
+		w = io.MultiWriter(w, e.manifest)
+	}
+	if e.zw != nil {
+		e.zw.Reset(w)
+		w = e.zw
+	}
+	e.w = bufio.NewWriter(w)
+}
+
+// Encode writes a single record, quoting fields as needed.
+func (e *Encoder) Encode(record []string) error {
+	if e.UseCRLF {
+		return e.EncodeLine(record, "\r\n")
+	}
+	return e.EncodeLine(record, "\n")
+}
+
+// EncodeLine writes a single CSV record terminated by eol instead of the
+// encoder's line terminator. It lets a decode-encode pipeline keep the
+// terminator of each input record, as returned by Decoder.LineEnding, so
+// the output differs from the input only where fields changed. Only the
+// last record may have an empty eol.
+func (e *Encoder) EncodeLine(record []string, eol string) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.columns != nil {
+		record = e.mapRecord(record)
+	}
+	if !e.started {
+		e.started = true
+		if e.WriteBOM {
+			e.w.WriteString(bom)
+		}
+	}
+
+	for i, field := range record {
+		if i > 0 {
+			e.w.WriteByte(e.Delimiter)
+		}
+		if e.SanitizeFormulas && isFormula(field) {
+			field = e.formulaPrefix() + field
+		}
+		quote := len(record) == 1 && field == "" || e.QuoteFunc != nil && e.QuoteFunc(i, field)
+		if !quote && e.Escape {
+			e.writeEscaped(field)
+			continue
+		}
+		if !quote && !e.fieldNeedsQuotes(field) {
+			e.w.WriteString(field)
+			continue
+		}
+		e.w.WriteByte('"')
+		for len(field) > 0 {
+			j := strings.IndexByte(field, '"')
+			if j < 0 {
+				e.w.WriteString(field)
+				break
+			}
+			e.w.WriteString(field[:j+1])
+			e.w.WriteByte('"')
+			field = field[j+1:]
+		}
+		e.w.WriteByte('"')
+	}
+
+	if _, err := e.w.WriteString(eol); err != nil {
+		e.err = err
+		return err
+	}
+	if e.manifest != nil {
+		e.manifest.record(len(record))
+	}
+	if e.flushEvery > 0 {
+		if e.unflushed++; e.unflushed >= e.flushEvery {
+			return e.Flush()
+		}
+	}
+	return nil
+}
+
+// writeEscaped writes field with backslash escapes.
+func (e *Encoder) writeEscaped(field string) {
+	for i := 0; i < len(field); i++ {
+		switch c := field[i]; {
+		case c == '\n':
+			e.w.WriteString(`\n`)
+		case c == '\r':
+			e.w.WriteString(`\r`)
+		case c == '\t':
+			e.w.WriteString(`\t`)
+		case c == e.Delimiter || c == '"' || c == '\\' || c == ' ' && i == 0:
+			e.w.WriteByte('\\')
+			e.w.WriteByte(c)
+		default:
+			e.w.WriteByte(c)
+		}
+	}
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.unflushed = 0
+	if err := e.w.Flush(); err != nil {
+		e.err = err
+	} else if e.zw != nil {
+		e.err = e.zw.Flush()
+	}
+	return e.err
+}
+
+// Close flushes the encoder and completes its compressed stream, if any.
+// It closes the file of an encoder returned by OpenAppend, but not the
+// io.Writer of other encoders.
+func (e *Encoder) Close() error {
+	err := e.Flush()
+	if err == nil && e.zw != nil {
+		err = e.zw.Close()
+		e.err = err
+	}
+	if e.closer != nil {
+		if cerr := e.closer.Close(); err == nil {
+			err = cerr
+		}
+		e.closer = nil
+	}
+	return err
+}
+
+// Error reports any error that has occurred during a previous Encode or
+// Flush.
+func (e *Encoder) Error() error {
+	return e.err
+}
+
+// isFormula reports whether a spreadsheet could read field as a formula.
+func isFormula(field string) bool {
+	if field == "" {
+		return false
+	}
+	switch field[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return true
+	}
+	return false
+}
+
+func (e *Encoder) formulaPrefix() string {
+	if e.FormulaPrefix == "" {
+		return "'"
+	}
+	return e.FormulaPrefix
+}
+
+// fieldNeedsQuotes reports whether field must be quoted to be read back
+// unchanged. A single empty field is quoted by Encode so the record is not
+// mistaken for a blank line.
+func (e *Encoder) fieldNeedsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if field[0] == ' ' || field[0] == '\t' {
+		return true
+	}
+	for i := 0; i < len(field); i++ {
+		switch c := field[i]; c {
+		case e.Delimiter, '"', '\r', '\n':
+			return true
+		}
+	}
+	return false
+}
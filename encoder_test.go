@@ -0,0 +1,150 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var writeTests = []struct {
+	Input   [][]string
+	Output  string
+	UseCRLF bool
+	Comma   byte
+
+	SanitizeFormulas bool
+	FormulaPrefix    string
+
+	QuoteFunc func(col int, field string) bool
+}{
+	{Input: [][]string{{"abc"}}, Output: "abc\n"},
+	{Input: [][]string{{"abc"}}, Output: "abc\r\n", UseCRLF: true},
+	{Input: [][]string{{`"abc"`}}, Output: `"""abc"""` + "\n"},
+	{Input: [][]string{{`a"b`}}, Output: `"a""b"` + "\n"},
+	{Input: [][]string{{" abc"}}, Output: `" abc"` + "\n"},
+	{Input: [][]string{{"abc,def"}}, Output: `"abc,def"` + "\n"},
+	{Input: [][]string{{"abc", "def"}}, Output: "abc,def\n"},
+	{Input: [][]string{{"abc"}, {"def"}}, Output: "abc\ndef\n"},
+	{Input: [][]string{{"abc\ndef"}}, Output: "\"abc\ndef\"\n"},
+	{Input: [][]string{{""}}, Output: "\"\"\n"},
+	{Input: [][]string{{"", ""}}, Output: ",\n"},
+	{Input: [][]string{{"a", "b;c"}}, Output: "a;\"b;c\"\n", Comma: ';'},
+	{Input: [][]string{{"=1+2", "+1", "-1", "@SUM(A1)", "a=b"}}, Output: "=1+2,+1,-1,@SUM(A1),a=b\n"},
+	{Input: [][]string{{"=1+2", "+1", "-1", "@SUM(A1)", "a=b", ""}}, Output: "'=1+2,'+1,'-1,'@SUM(A1),a=b,\n", SanitizeFormulas: true},
+	{Input: [][]string{{"\t=cmd", "=HYPERLINK(\"x\")"}}, Output: "'\t=cmd,\"'=HYPERLINK(\"\"x\"\")\"\n", SanitizeFormulas: true},
+	{Input: [][]string{{"=1+2", "ok"}}, Output: "\" =1+2\",ok\n", SanitizeFormulas: true, FormulaPrefix: " "},
+	{Input: [][]string{{"1", "ann", "x,y"}, {"2", "", "z"}}, Output: "1,\"ann\",\"x,y\"\n2,\"\",z\n", QuoteFunc: quoteColumn(1)},
+	{Input: [][]string{{"1", "a\"b"}}, Output: "\"1\",\"a\"\"b\"\n", QuoteFunc: func(int, string) bool { return true }},
+	{Input: [][]string{{"a b", "x"}}, Output: "a b,x\n", QuoteFunc: func(int, string) bool { return false }},
+}
+
+// quoteColumn returns a QuoteFunc quoting the fields of column col.
+func quoteColumn(col int) func(int, string) bool {
+	return func(i int, _ string) bool { return i == col }
+}
+
+func TestEncode(t *testing.T) {
+	for n, tt := range writeTests {
+		b := &bytes.Buffer{}
+		enc := NewEncoder(b)
+		enc.UseCRLF = tt.UseCRLF
+		enc.SanitizeFormulas = tt.SanitizeFormulas
+		enc.FormulaPrefix = tt.FormulaPrefix
+		enc.QuoteFunc = tt.QuoteFunc
+		if tt.Comma != 0 {
+			enc.Delimiter = tt.Comma
+		}
+		for _, record := range tt.Input {
+			if err := enc.Encode(record); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := enc.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if out := b.String(); out != tt.Output {
+			t.Errorf("#%d: out=%q want %q", n, out, tt.Output)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	records := [][]string{{"a", `b"c`, "d,e"}, {""}, {"multi\nline", "", "x"}}
+	b := &bytes.Buffer{}
+	enc := NewEncoder(b)
+	for _, record := range records {
+		enc.Encode(record)
+	}
+	enc.Flush()
+
+	dec := NewDecoder(strings.NewReader(b.String()))
+	dec.FieldsPerRecord = -1
+	var got [][]string
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, record)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("got %q, want %q", got, records)
+	}
+}
+
+func TestEncoderTee(t *testing.T) {
+	var out, audit, compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	enc := NewEncoder(&out)
+	enc.EnableManifest()
+	enc.Tee(&audit, zw)
+	for _, record := range [][]string{{"a", "b"}, {"1", "x,y"}} {
+		if err := enc.Encode(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "a,b\n1,\"x,y\"\n"
+	if out.String() != want || audit.String() != want {
+		t.Errorf("output %q and copy %q, want %q", out.String(), audit.String(), want)
+	}
+	zr, err := gzip.NewReader(&compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := ioutil.ReadAll(zr); err != nil || string(b) != want {
+		t.Errorf("compressed copy %q, %v", b, err)
+	}
+	if m := enc.Manifest(); m.Bytes != int64(len(want)) || m.Records != 2 {
+		t.Errorf("manifest %+v", m)
+	}
+}
+
+// failWriter fails every write.
+type failWriter struct{ err error }
+
+func (w failWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+func TestEncoderTeeError(t *testing.T) {
+	errDisk := errors.New("disk full")
+	var out bytes.Buffer
+	enc := NewEncoder(&out)
+	enc.Tee(failWriter{errDisk})
+	enc.Encode([]string{"a"})
+	if err := enc.Flush(); err != errDisk {
+		t.Errorf("Flush error %v, want %v", err, errDisk)
+	}
+	if err := enc.Encode([]string{"b"}); err != errDisk {
+		t.Errorf("Encode error %v after failure, want %v", err, errDisk)
+	}
+}
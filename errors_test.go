@@ -0,0 +1,95 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func firstError(dec *Decoder) error {
+	for dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestErrorsIs(t *testing.T) {
+	tests := []struct {
+		input    string
+		sentinel error
+	}{
+		{"a,b\nc\n", ErrFieldCount},
+		{"a\"b\n", ErrBareQuote},
+		{"\"a\"b\n", ErrQuote},
+	}
+	for _, tt := range tests {
+		err := firstError(NewDecoder(strings.NewReader(tt.input)))
+		wrapped := fmt.Errorf("loading: %w", err)
+		if !errors.Is(wrapped, tt.sentinel) {
+			t.Errorf("%q: errors.Is(%v, %v) = false", tt.input, wrapped, tt.sentinel)
+		}
+		var perr *ParseError
+		if !errors.As(wrapped, &perr) || perr.Err != tt.sentinel {
+			t.Errorf("%q: errors.As(%v) did not find the ParseError", tt.input, wrapped)
+		}
+	}
+}
+
+func TestErrorsAsConversion(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("x\n"))
+	var n int
+	err := dec.DecodeValues(&n)
+
+	var cerr *ConversionError
+	if !errors.As(err, &cerr) || cerr.Value != "x" {
+		t.Fatalf("errors.As(%v) did not find the ConversionError", err)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("errors.Is(%v, strconv.ErrSyntax) = false", err)
+	}
+}
+
+func TestErrorsAsTransform(t *testing.T) {
+	boom := errors.New("boom")
+	var out bytes.Buffer
+	err := Reencode(strings.NewReader("a\n1\n"), &out, ReencodeOptions{}, Mask(map[string]Masker{
+		"a": func(string) (string, error) { return "", boom },
+	}))
+	var terr *TransformError
+	if !errors.As(err, &terr) || terr.Column != "a" {
+		t.Errorf("errors.As(%v) did not find the TransformError", err)
+	}
+	if !errors.Is(err, boom) {
+		t.Errorf("errors.Is(%v, boom) = false", err)
+	}
+}
+
+func TestFieldCountError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b,c\n1,2,3\n4,5\n6,7,8,9\n"))
+	dec.Decode()
+	dec.Decode()
+	_, err := dec.Decode()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Err != ErrFieldCount {
+		t.Fatalf("error %v, want ErrFieldCount", err)
+	}
+	if perr.Expected != 3 || perr.Actual != 2 || perr.Record != 3 || perr.Field != 2 || perr.Offset != 12 || perr.Snippet != "4,5" {
+		t.Errorf("error %+v", perr)
+	}
+	want := `record 3, line 3, column 0: wrong number of fields (got 2, want 3) (field 2, offset 12, near "4,5")`
+	if err.Error() != want {
+		t.Errorf("message %q, want %q", err.Error(), want)
+	}
+
+	dec = NewDecoder(strings.NewReader("a\n" + strings.Repeat("x,", 30) + "y\r\n"))
+	dec.Decode()
+	_, err = dec.Decode()
+	if !errors.As(err, &perr) || perr.Actual != 31 || perr.Field != 1 || len(perr.Snippet) != 2*snippetContext+1 {
+		t.Errorf("long record: error %+v", perr)
+	}
+}
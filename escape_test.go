@@ -0,0 +1,70 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEscapes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want [][]string
+	}{
+		{`a\,b,c\\d` + "\n", [][]string{{"a,b", `c\d`}}},
+		{`line\none,tab\there,cr\r` + "\n", [][]string{{"line\none", "tab\there", "cr\r"}}},
+		{`say \"hi\",\x` + "\n", [][]string{{`say "hi"`, "x"}}},
+		{"\\\n,x\ny\n", [][]string{{"\n", "x"}, {"y"}}},
+		{`"quoted \n",\ lead` + "\n", [][]string{{`quoted \n`, " lead"}}},
+	}
+	for _, tt := range tests {
+		for _, size := range []int{1, 4096} {
+			dec := NewDecoderDialect(strings.NewReader(tt.in), Dialect{Escapes: true})
+			dec.SetBufferSize(size)
+			dec.FieldsPerRecord = -1
+			var got [][]string
+			for {
+				fields, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%q: %v", tt.in, err)
+				}
+				got = append(got, fields)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q, size %d: records %q, want %q", tt.in, size, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestEncoderEscape(t *testing.T) {
+	records := [][]string{
+		{"a,b", `c\d`, "line\none", "tab\t", `"q"`, " lead"},
+		{""},
+		{"plain", ""},
+	}
+	var out bytes.Buffer
+	enc := NewEncoder(&out)
+	enc.Escape = true
+	for _, r := range records {
+		enc.Encode(r)
+	}
+	enc.Flush()
+	want := `a\,b,c\\d,line\none,tab\t,\"q\",\ lead` + "\n\"\"\nplain,\n"
+	if out.String() != want {
+		t.Errorf("output %q, want %q", out.String(), want)
+	}
+
+	dec := NewDecoderDialect(&out, Dialect{Escapes: true})
+	dec.FieldsPerRecord = -1
+	for _, r := range records {
+		if fields, err := dec.Decode(); err != nil || !reflect.DeepEqual(fields, r) {
+			t.Errorf("read back %q, %v, want %q", fields, err, r)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// A FieldMapping names the key of the data of an event holding the field
+// of a column.
+type FieldMapping struct {
+	Column string
+	Key    string // the column name when empty
+}
+
+// EventMapperOptions configures an EventMapper.
+type EventMapperOptions struct {
+	// Source and Type are the source and type attributes of every event.
+	Source string
+	Type   string
+
+	// Attributes are extension attributes added to every event. Their
+	// names are lower-case ASCII letters and digits, and must not be those
+	// of the attributes set by the mapper.
+	Attributes map[string]string
+
+	// IDColumn, if not empty, is the column whose field is the id of each
+	// event, which must not be empty. Otherwise events are numbered from 1.
+	IDColumn string
+
+	// TimeColumn, if not empty, is the column whose field is the time of
+	// each event, written in RFC 3339. It is parsed with TimeLayout, or
+	// as RFC 3339 or a date and time such as "2006-01-02 15:04:05" if it
+	// is empty, in TimeLocation, or UTC if it is nil. An empty field gives
+	// an event without time.
+	TimeColumn   string
+	TimeLayout   string
+	TimeLocation *time.Location
+
+	// Fields select the fields of the data of each event and their keys.
+	// All fields are included, keyed by the header, if it is nil.
+	Fields []FieldMapping
+}
+
+// An EventMapper converts records into CloudEvents in JSON, in structured
+// mode, ready to be sent to an event bus, for example with a
+// PublishFunc. The data of an event is a JSON object of the fields of its
+// record, as strings.
+type EventMapper struct {
+	opts   EventMapperOptions
+	keys   []string
+	cols   []int // the field of each key
+	id     int   // index of IDColumn, or -1
+	time   int   // index of TimeColumn, or -1
+	attrs  []string
+	mapped bool
+	n      int64
+	buf    bytes.Buffer
+}
+
+// eventAttribute matches the names of CloudEvents attributes.
+var eventAttribute = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// reservedEventAttributes are the attributes written by an EventMapper.
+var reservedEventAttributes = map[string]bool{
+	"specversion":     true,
+	"id":              true,
+	"source":          true,
+	"type":            true,
+	"time":            true,
+	"datacontenttype": true,
+	"data":            true,
+}
+
+// NewEventMapper returns a mapper converting records as set by opts.
+func NewEventMapper(opts EventMapperOptions) (*EventMapper, error) {
+	m := &EventMapper{opts: opts, id: -1, time: -1}
+	for name := range opts.Attributes {
+		if !eventAttribute.MatchString(name) {
+			return nil, fmt.Errorf("csv: invalid event attribute name %q", name)
+		}
+		if reservedEventAttributes[name] {
+			return nil, fmt.Errorf("csv: event attribute %q is set by the mapper", name)
+		}
+		m.attrs = append(m.attrs, name)
+	}
+	sort.Strings(m.attrs)
+	return m, nil
+}
+
+// Map returns the event of r. The columns are looked up in the header of
+// the first record mapped, so every record must have the same header.
+func (m *EventMapper) Map(r Record) ([]byte, error) {
+	if !m.mapped {
+		if err := m.resolve(r); err != nil {
+			return nil, err
+		}
+		m.mapped = true
+	}
+	m.n++
+
+	m.buf.Reset()
+	b := &m.buf
+	b.WriteString(`{"specversion":"1.0"`)
+	id := strconv.FormatInt(m.n, 10)
+	if m.id >= 0 {
+		if id = fieldAt(r.Fields, m.id); id == "" {
+			return nil, fmt.Errorf("csv: empty event id in column %q", m.opts.IDColumn)
+		}
+	}
+	writeJSONMember(b, "id", id)
+	writeJSONMember(b, "source", m.opts.Source)
+	writeJSONMember(b, "type", m.opts.Type)
+	if m.time >= 0 {
+		if field := fieldAt(r.Fields, m.time); field != "" {
+			t, err := parseTime(field, m.opts.TimeLayout, m.opts.TimeLocation)
+			if err != nil {
+				return nil, &ConversionError{Field: m.time, Value: field, Type: reflect.TypeOf(t), Err: err}
+			}
+			writeJSONMember(b, "time", t.Format(time.RFC3339Nano))
+		}
+	}
+	for _, name := range m.attrs {
+		writeJSONMember(b, name, m.opts.Attributes[name])
+	}
+	writeJSONMember(b, "datacontenttype", "application/json")
+	b.WriteString(`,"data":`)
+	writeJSONObject(b, m.keys, func(i int) string { return fieldAt(r.Fields, m.cols[i]) })
+	b.WriteByte('}')
+	return append([]byte(nil), b.Bytes()...), nil
+}
+
+// resolve finds the columns of the options in the header of r.
+func (m *EventMapper) resolve(r Record) error {
+	index := func(name string) (int, error) {
+		i := r.Index(name)
+		if i < 0 {
+			return 0, fmt.Errorf("csv: no column %q", name)
+		}
+		return i, nil
+	}
+	var err error
+	if m.opts.IDColumn != "" {
+		if m.id, err = index(m.opts.IDColumn); err != nil {
+			return err
+		}
+	}
+	if m.opts.TimeColumn != "" {
+		if m.time, err = index(m.opts.TimeColumn); err != nil {
+			return err
+		}
+	}
+	if m.opts.Fields == nil {
+		m.keys = r.Header()
+		m.cols = make([]int, len(m.keys))
+		for i := range m.cols {
+			m.cols[i] = i
+		}
+		return nil
+	}
+	for _, f := range m.opts.Fields {
+		col, err := index(f.Column)
+		if err != nil {
+			return err
+		}
+		key := f.Key
+		if key == "" {
+			key = f.Column
+		}
+		m.keys = append(m.keys, key)
+		m.cols = append(m.cols, col)
+	}
+	return nil
+}
+
+// parseTime parses field with layout, or as RFC 3339 or a date and time
+// such as "2006-01-02 15:04:05" if it is empty, in loc, or UTC if it is
+// nil.
+func parseTime(field, layout string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if layout != "" {
+		return time.ParseInLocation(layout, field, loc)
+	}
+	var err error
+	for _, layout := range driftTimeLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, field, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// writeJSONMember writes a comma and the member key: value of a JSON
+// object to b.
+func writeJSONMember(b *bytes.Buffer, key, value string) {
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(value)
+	b.WriteByte(',')
+	b.Write(k)
+	b.WriteByte(':')
+	b.Write(v)
+}
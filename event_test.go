@@ -0,0 +1,85 @@
+package csv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventMapper(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("id,at,temp,room\ne1,2024-03-01 10:00:00,21.5,a\ne2,,19,b\n"))
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewEventMapper(EventMapperOptions{
+		Source:     "/sensors",
+		Type:       "reading",
+		Attributes: map[string]string{"site": "lab", "env": "test"},
+		IDColumn:   "id",
+		TimeColumn: "at",
+		Fields:     []FieldMapping{{Column: "temp", Key: "celsius"}, {Column: "room"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`{"specversion":"1.0","id":"e1","source":"/sensors","type":"reading","time":"2024-03-01T10:00:00Z","env":"test","site":"lab","datacontenttype":"application/json","data":{"celsius":"21.5","room":"a"}}`,
+		`{"specversion":"1.0","id":"e2","source":"/sensors","type":"reading","env":"test","site":"lab","datacontenttype":"application/json","data":{"celsius":"19","room":"b"}}`,
+	}
+	for _, w := range want {
+		r, err := dec.DecodeRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := m.Map(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != w {
+			t.Errorf("got  %s\nwant %s", got, w)
+		}
+	}
+}
+
+func TestEventMapperDefaults(t *testing.T) {
+	header := []string{"a", "when"}
+	m, err := NewEventMapper(EventMapperOptions{Source: "s", Type: "t", TimeColumn: "when", TimeLayout: "02/01/2006", TimeLocation: time.FixedZone("", 3600)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.Map(NewRecord(header, []string{"x", "05/06/2024"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"specversion":"1.0","id":"1","source":"s","type":"t","time":"2024-06-05T00:00:00+01:00","datacontenttype":"application/json","data":{"a":"x","when":"05/06/2024"}}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	_, err = m.Map(NewRecord(header, []string{"x", "soon"}))
+	var cerr *ConversionError
+	if !errors.As(err, &cerr) || cerr.Field != 1 {
+		t.Errorf("bad time: error %v", err)
+	}
+
+	m, _ = NewEventMapper(EventMapperOptions{IDColumn: "missing"})
+	if _, err := m.Map(NewRecord(header, []string{"x", "y"})); err == nil {
+		t.Error("missing id column accepted")
+	}
+	m, _ = NewEventMapper(EventMapperOptions{IDColumn: "a"})
+	if _, err := m.Map(NewRecord(header, []string{"", "y"})); err == nil {
+		t.Error("empty id accepted")
+	}
+}
+
+func TestEventMapperAttributes(t *testing.T) {
+	for _, name := range []string{"id", "time", "data", "datacontenttype", "specversion", "Site", "site-id", ""} {
+		if _, err := NewEventMapper(EventMapperOptions{Attributes: map[string]string{name: "x"}}); err == nil {
+			t.Errorf("attribute %q accepted", name)
+		}
+	}
+	if _, err := NewEventMapper(EventMapperOptions{Attributes: map[string]string{"site2": "x"}}); err != nil {
+		t.Error(err)
+	}
+}
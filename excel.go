@@ -0,0 +1,77 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+)
+
+// Excel is the dialect of CSV files saved by spreadsheet applications such
+// as Microsoft Excel: the input may start with a byte order mark and a
+// "sep=" line, and text fields may be written as formulas like ="0123".
+//
+// Encoders producing files for Excel should set WriteBOM and UseCRLF.
+var Excel = Dialect{
+	Delimiter:      ',',
+	SkipBOM:        true,
+	SepDirective:   true,
+	UnwrapFormulas: true,
+}
+
+// bom is the UTF-8 encoding of the byte order mark.
+const bom = "\ufeff"
+
+// maxPreamble bounds the bytes buffered while looking for a "sep=" line.
+const maxPreamble = 64
+
+// preamble reads what precedes the first record: a byte order mark and a
+// "sep=" line, as enabled by the dialect, and a Frontmatter block.
+func (d *Decoder) preamble() {
+	d.started = true
+	d.excelPreamble()
+	if d.Frontmatter && d.err == nil {
+		d.readFrontmatter()
+	}
+}
+
+// excelPreamble skips a byte order mark and reads a "sep=" line at the
+// start of the input, as enabled by the dialect.
+func (d *Decoder) excelPreamble() {
+	if !d.skipBOM && !d.sepDirective {
+		return
+	}
+
+	var err error
+	for err == nil && len(d.buf)-d.scanp < maxPreamble && bytes.IndexByte(d.buf[d.scanp:], '\n') < 0 {
+		err = d.refill()
+	}
+	if err != nil && err != io.EOF {
+		d.err = err
+	}
+
+	if d.skipBOM && bytes.HasPrefix(d.buf[d.scanp:], []byte(bom)) {
+		d.scanp += len(bom)
+		d.lineStart = d.offset + int64(d.scanp)
+	}
+	if !d.sepDirective {
+		return
+	}
+	line := d.buf[d.scanp:]
+	if len(line) < 5 || !bytes.EqualFold(line[:4], []byte("sep=")) {
+		return
+	}
+	rest := line[5:]
+	switch {
+	case len(rest) == 0:
+	case rest[0] == '\n':
+		d.scanp++
+		d.line++
+	case rest[0] == '\r' && len(rest) > 1 && rest[1] == '\n':
+		d.scanp += 2
+		d.line++
+	default:
+		return
+	}
+	d.scan.Delimiter = line[4]
+	d.scanp += 5
+	d.lineStart = d.offset + int64(d.scanp)
+}
@@ -0,0 +1,100 @@
+package csv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var excelTests = []struct {
+	Name   string
+	Input  string
+	Output [][]string
+}{
+	{
+		Name:   "BOM",
+		Input:  "\ufeffa,b\n1,2\n",
+		Output: [][]string{{"a", "b"}, {"1", "2"}},
+	},
+	{
+		Name:   "SepDirective",
+		Input:  "sep=;\r\na;b\r\n1,5;2\r\n",
+		Output: [][]string{{"a", "b"}, {"1,5", "2"}},
+	},
+	{
+		Name:   "BOMAndSepDirective",
+		Input:  "\ufeffSEP=|\na|b\n",
+		Output: [][]string{{"a", "b"}},
+	},
+	{
+		Name:   "SepDirectiveOnly",
+		Input:  "sep=;",
+		Output: nil,
+	},
+	{
+		Name:   "NotADirective",
+		Input:  "sep=;x\n",
+		Output: [][]string{{"sep=;x"}},
+	},
+	{
+		Name:   "Formulas",
+		Input:  "=\"0123\",=\"a,\"\"b\"\"\",=1+2,=\n",
+		Output: [][]string{{"0123", `a,"b"`, "=1+2", "="}},
+	},
+}
+
+func TestExcelDecode(t *testing.T) {
+	for _, tt := range excelTests {
+		dec := NewDecoderDialect(strings.NewReader(tt.Input), Excel)
+		dec.FieldsPerRecord = -1
+		var out [][]string
+		for dec.More() {
+			record, err := dec.Decode()
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.Name, err)
+				break
+			}
+			out = append(out, record)
+		}
+		if !reflect.DeepEqual(out, tt.Output) {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, tt.Output)
+		}
+	}
+}
+
+func TestExcelSepDirectiveLine(t *testing.T) {
+	dec := NewDecoderDialect(strings.NewReader("sep=;\na;b\n1\n"), Excel)
+	var err error
+	for dec.More() {
+		if _, err = dec.Decode(); err != nil {
+			break
+		}
+	}
+	if perr, ok := err.(*ParseError); !ok || perr.Line != 3 || perr.Record != 2 {
+		t.Errorf("error %v, want field count error at record 2, line 3", err)
+	}
+}
+
+func TestEncoderWriteBOM(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.WriteBOM = true
+	enc.UseCRLF = true
+	enc.Encode([]string{"a", "b"})
+	enc.Encode([]string{"1", "2"})
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "\ufeffa,b\r\n1,2\r\n"; got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+
+	records, err := ReadAll(&buf, WithDialect(Excel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"a", "b"}, {"1", "2"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("read back %q, want %q", records, want)
+	}
+}
@@ -0,0 +1,106 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+)
+
+// These are the errors returned in a ParseError for streams failing the
+// Expectations set with Expect. The ParseError wraps them with the column
+// concerned, and errors.Is finds them.
+var (
+	ErrUnexpectedHeader = errors.New("unexpected header")
+	ErrEmptyField       = errors.New("empty field")
+)
+
+// Expectations describe the shape of a stream, checked by a Decoder as it
+// reads it so that schema drift is found at the first record showing it.
+type Expectations struct {
+	// Columns, if positive, is the number of fields of every record,
+	// including the header. It is checked before PadShortRows and
+	// TruncateLongRows reshape records.
+	Columns int
+	// Header, if not nil, is the header the stream must start with.
+	Header []string
+	// NonEmpty lists the columns whose fields must not be empty in the
+	// records after the header.
+	NonEmpty []int
+}
+
+// Expect sets the expectations every record must meet. A record failing
+// them stops the decoder with a ParseError. If e.Header is not nil, Expect
+// reads the header with ReadHeader, unless it was read already, and
+// returns an error naming the first column that differs.
+func (d *Decoder) Expect(e Expectations) error {
+	d.expect = &e
+	if e.Header == nil {
+		return nil
+	}
+	header := d.header
+	if header == nil {
+		var err error
+		if header, err = d.ReadHeader(); err != nil {
+			return err
+		}
+	}
+	if err := headerDiff(header, e.Header); err != nil {
+		d.column = 0 // report at start of record
+		d.err = ErrUnexpectedHeader
+		perr := d.error(err).(*ParseError)
+		perr.Field = len(header)
+		for i := range header {
+			if i >= len(e.Header) || header[i] != e.Header[i] {
+				perr.Field = i
+				break
+			}
+		}
+		return perr
+	}
+	return nil
+}
+
+// headerDiff describes the first difference between header and want.
+func headerDiff(header, want []string) error {
+	for i := 0; i < len(header) || i < len(want); i++ {
+		switch {
+		case i >= len(want):
+			return fmt.Errorf("%w: extra column %d %q", ErrUnexpectedHeader, i, header[i])
+		case i >= len(header):
+			return fmt.Errorf("%w: missing column %d %q", ErrUnexpectedHeader, i, want[i])
+		case header[i] != want[i]:
+			return fmt.Errorf("%w: column %d is %q, want %q", ErrUnexpectedHeader, i, header[i], want[i])
+		}
+	}
+	return nil
+}
+
+// checkExpectations checks the current record against the expectations
+// set with Expect.
+func (d *Decoder) checkExpectations() error {
+	e := d.expect
+	if e == nil {
+		return nil
+	}
+	if n := len(d.fieldIndexes); e.Columns > 0 && n != e.Columns {
+		d.err = ErrFieldCount
+		return d.fieldCountError(e.Columns, n)
+	}
+	if d.inHeader {
+		return nil
+	}
+	for _, col := range e.NonEmpty {
+		if col >= 0 && col < len(d.fieldIndexes) && len(d.field(col)) > 0 {
+			continue
+		}
+		where := fmt.Sprint(col)
+		if col >= 0 && col < len(d.header) {
+			where += fmt.Sprintf(" (%s)", d.header[col])
+		}
+		d.column = 0 // report at start of record
+		d.err = ErrEmptyField
+		perr := d.error(fmt.Errorf("%w in column %s", ErrEmptyField, where)).(*ParseError)
+		perr.Field = col
+		return perr
+	}
+	return nil
+}
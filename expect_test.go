@@ -0,0 +1,51 @@
+package csv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExpect(t *testing.T) {
+	tests := []struct {
+		in     string
+		e      Expectations
+		record int64 // record failing, or 0
+		field  int
+		err    error
+		msg    string
+	}{
+		{"id,name\n1,ann\n2,bob\n", Expectations{Columns: 2, Header: []string{"id", "name"}, NonEmpty: []int{0, 1}}, 0, 0, nil, ""},
+		{"id,name\n1,ann\n2,bob,x\n", Expectations{Columns: 2}, 3, 2, ErrFieldCount, "record 3, line 3, column 0: wrong number of fields (got 3, want 2)"},
+		{"id,name\n1,ann\n,bob\n", Expectations{Header: []string{"id", "name"}, NonEmpty: []int{0}}, 3, 0, ErrEmptyField, "empty field in column 0 (id)"},
+		{"id,name\n1\n", Expectations{NonEmpty: []int{1}}, 2, 1, ErrEmptyField, "empty field in column 1"},
+		{"id,Name\n1,ann\n", Expectations{Header: []string{"id", "name"}}, 1, 1, ErrUnexpectedHeader, `unexpected header: column 1 is "Name", want "name"`},
+		{"id\n1\n", Expectations{Header: []string{"id", "name"}}, 1, 1, ErrUnexpectedHeader, `unexpected header: missing column 1 "name"`},
+		{"id,name,x\n1\n", Expectations{Header: []string{"id", "name"}}, 1, 2, ErrUnexpectedHeader, `unexpected header: extra column 2 "x"`},
+	}
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(tt.in))
+		dec.FieldsPerRecord = -1
+		err := dec.Expect(tt.e)
+		for err == nil && dec.More() {
+			_, err = dec.Decode()
+		}
+		if tt.err == nil {
+			if err != nil {
+				t.Errorf("%q: %v", tt.in, err)
+			}
+			continue
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) || !errors.Is(err, tt.err) || perr.Record != tt.record || perr.Field != tt.field {
+			t.Errorf("%q: error %v, want %v in record %d, field %d", tt.in, err, tt.err, tt.record, tt.field)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("%q: error %q, want it to contain %q", tt.in, err, tt.msg)
+		}
+		if _, err := dec.Decode(); !errors.Is(err, tt.err) {
+			t.Errorf("%q: error %v after failing, want the decoder stopped", tt.in, err)
+		}
+	}
+}
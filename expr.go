@@ -0,0 +1,257 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CompileFilter compiles a filter expression against the column names of
+// header and returns the predicate it describes.
+//
+// An expression compares columns and literals with ==, !=, <, <=, > and >=
+// and combines comparisons with &&, || and !, using parentheses for
+// grouping:
+//
+//	amount > 100 && (country == "US" || country == 'CA')
+//
+// Column names are identifiers, or any name written in backquotes such as
+// `unit price`. Literals are numbers or quoted strings. Two values that
+// both parse as numbers are compared numerically, other values as strings.
+func CompileFilter(expr string, header []string) (func(Record) bool, error) {
+	p := &exprParser{header: header}
+	if err := p.tokenize(expr); err != nil {
+		return nil, err
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %s", p.tokens[p.pos].text)
+	}
+	return func(r Record) bool {
+		return n.test(r.Fields)
+	}, nil
+}
+
+// A boolNode is a condition of a compiled filter expression.
+type boolNode interface {
+	test(fields []string) bool
+}
+
+// A valueNode is an operand of a comparison.
+type valueNode interface {
+	value(fields []string) string
+}
+
+type (
+	columnNode  int
+	literalNode string
+	notNode     struct{ x boolNode }
+	logicNode   struct {
+		and  bool
+		x, y boolNode
+	}
+	compareNode struct {
+		op   string
+		x, y valueNode
+	}
+)
+
+func (n columnNode) value(fields []string) string  { return fieldAt(fields, int(n)) }
+func (n literalNode) value(fields []string) string { return string(n) }
+
+func (n notNode) test(fields []string) bool { return !n.x.test(fields) }
+
+func (n logicNode) test(fields []string) bool {
+	if x := n.x.test(fields); x != n.and {
+		return x
+	}
+	return n.y.test(fields)
+}
+
+func (n compareNode) test(fields []string) bool {
+	c := compareValues(n.x.value(fields), n.y.value(fields))
+	switch n.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// compareValues compares x and y as numbers if both parse as numbers, and
+// as strings otherwise.
+func compareValues(x, y string) int {
+	fx, errx := strconv.ParseFloat(strings.TrimSpace(x), 64)
+	fy, erry := strconv.ParseFloat(strings.TrimSpace(y), 64)
+	if errx != nil || erry != nil {
+		return compareFields(x, y, false)
+	}
+	switch {
+	case fx < fy:
+		return -1
+	case fx > fy:
+		return 1
+	}
+	return 0
+}
+
+// A token is a lexical token of a filter expression.
+type token struct {
+	kind byte // 'i' identifier, 's' string, 'n' number, 'o' operator
+	text string
+	pos  int
+}
+
+type exprParser struct {
+	header []string
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("csv: filter: "+format, args...)
+}
+
+func (p *exprParser) tokenize(s string) error {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'' || c == '`':
+			j := strings.IndexByte(s[i+1:], c)
+			if j < 0 {
+				return p.errorf("unterminated quote at offset %d", i)
+			}
+			kind := byte('s')
+			if c == '`' {
+				kind = 'i'
+			}
+			p.tokens = append(p.tokens, token{kind, s[i+1 : i+1+j], i})
+			i += j + 2
+		case strings.HasPrefix(s[i:], "&&") || strings.HasPrefix(s[i:], "||") ||
+			strings.HasPrefix(s[i:], "==") || strings.HasPrefix(s[i:], "!=") ||
+			strings.HasPrefix(s[i:], "<=") || strings.HasPrefix(s[i:], ">="):
+			p.tokens = append(p.tokens, token{'o', s[i : i+2], i})
+			i += 2
+		case strings.IndexByte("<>!()", c) >= 0:
+			p.tokens = append(p.tokens, token{'o', s[i : i+1], i})
+			i++
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || s[j] == 'e' || s[j] == 'E' || (s[j] >= '0' && s[j] <= '9')) {
+				j++
+			}
+			p.tokens = append(p.tokens, token{'n', s[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)) || c >= 0x80:
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] >= 0x80 || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			p.tokens = append(p.tokens, token{'i', s[i:j], i})
+			i = j
+		default:
+			return p.errorf("unexpected %q at offset %d", c, i)
+		}
+	}
+	return nil
+}
+
+// peek reports whether the next token is the operator op.
+func (p *exprParser) peek(op string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == 'o' && p.tokens[p.pos].text == op
+}
+
+func (p *exprParser) parseOr() (boolNode, error) {
+	x, err := p.parseAnd()
+	for err == nil && p.peek("||") {
+		p.pos++
+		var y boolNode
+		if y, err = p.parseAnd(); err == nil {
+			x = logicNode{and: false, x: x, y: y}
+		}
+	}
+	return x, err
+}
+
+func (p *exprParser) parseAnd() (boolNode, error) {
+	x, err := p.parseUnary()
+	for err == nil && p.peek("&&") {
+		p.pos++
+		var y boolNode
+		if y, err = p.parseUnary(); err == nil {
+			x = logicNode{and: true, x: x, y: y}
+		}
+	}
+	return x, err
+}
+
+func (p *exprParser) parseUnary() (boolNode, error) {
+	if p.peek("!") {
+		p.pos++
+		x, err := p.parseUnary()
+		return notNode{x}, err
+	}
+	if p.peek("(") {
+		p.pos++
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	}
+
+	x, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		switch op := p.tokens[p.pos].text; op {
+		case "==", "!=", "<", "<=", ">", ">=":
+			p.pos++
+			y, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareNode{op: op, x: x, y: y}, nil
+		}
+	}
+	return nil, p.errorf("expected comparison after %s", p.tokens[p.pos-1].text)
+}
+
+func (p *exprParser) parseOperand() (valueNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	switch t.kind {
+	case 's', 'n':
+		return literalNode(t.text), nil
+	case 'i':
+		for i, name := range p.header {
+			if name == t.text {
+				return columnNode(i), nil
+			}
+		}
+		return nil, p.errorf("unknown column %q at offset %d", t.text, t.pos)
+	}
+	return nil, p.errorf("unexpected %s at offset %d", t.text, t.pos)
+}
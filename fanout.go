@@ -0,0 +1,53 @@
+package csv
+
+import (
+	"io"
+	"sync"
+)
+
+// A FanOut lets several goroutines read records from a single Decoder,
+// which is not safe for concurrent use by itself. Each record is returned
+// to exactly one caller, together with its position in the stream so that
+// results can be put back in order.
+type FanOut struct {
+	mu  sync.Mutex
+	dec *Decoder
+	n   int64
+	err error
+}
+
+// An IndexedRecord is a record returned by a FanOut.
+type IndexedRecord struct {
+	Index  int64 // 0-based position of the record among those returned
+	Fields []string
+}
+
+// NewFanOut returns a FanOut reading from dec. The decoder must not be used
+// directly while the FanOut is in use.
+func NewFanOut(dec *Decoder) *FanOut {
+	return &FanOut{dec: dec}
+}
+
+// Next returns the next record of the stream. At the end of the stream it
+// returns io.EOF. Once the decoder has failed, every call returns the
+// same error.
+func (f *FanOut) Next() (IndexedRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return IndexedRecord{}, f.err
+	}
+	if !f.dec.More() {
+		f.err = io.EOF
+		return IndexedRecord{}, f.err
+	}
+	fields, err := f.dec.Decode()
+	if err != nil {
+		f.err = err
+		return IndexedRecord{}, err
+	}
+	r := IndexedRecord{Index: f.n, Fields: fields}
+	f.n++
+	return r, nil
+}
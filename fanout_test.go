@@ -0,0 +1,53 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFanOut(t *testing.T) {
+	var in strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&in, "%d,x\n", i)
+	}
+	f := NewFanOut(NewDecoder(strings.NewReader(in.String())))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[int64]string)
+	)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				r, err := f.Next()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				seen[r.Index] = r.Fields[0]
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 1000 {
+		t.Fatalf("got %d records, want 1000", len(seen))
+	}
+	for i, v := range seen {
+		if strconv.FormatInt(i, 10) != v {
+			t.Errorf("record %d has value %q", i, v)
+		}
+	}
+}
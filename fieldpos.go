@@ -0,0 +1,33 @@
+package csv
+
+// A fieldPos is the 1-based line and column where a field starts.
+type fieldPos struct {
+	line, column int
+}
+
+// position returns the position of buf[i], which is on the current line.
+func (d *Decoder) position(i int) fieldPos {
+	return fieldPos{
+		line:   d.line + 1,
+		column: int(d.offset+int64(i)-d.lineStart) + 1,
+	}
+}
+
+// FieldPos returns the line and column of the start of the field with the
+// given index in the record most recently returned, as
+// encoding/csv.Reader.FieldPos does. Numbering of lines and columns starts
+// at 1; columns are counted in bytes. The position of a quoted field is
+// that of its opening quote.
+//
+// Fields added by PadShortRows report the position of the last field read.
+// If fieldIndex is out of range, FieldPos panics.
+func (d *Decoder) FieldPos(fieldIndex int) (line, column int) {
+	if fieldIndex < 0 || fieldIndex >= len(d.fieldIndexes) {
+		panic("out of range index passed to FieldPos")
+	}
+	if fieldIndex >= len(d.fieldPos) {
+		fieldIndex = len(d.fieldPos) - 1
+	}
+	p := d.fieldPos[fieldIndex]
+	return p.line, p.column
+}
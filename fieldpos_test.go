@@ -0,0 +1,41 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFieldPos(t *testing.T) {
+	in := "\n  a,bb,\"c\nc\",d\r\n\n#x\ne,\"f\"\"\",g\n"
+	dec := NewDecoderDialect(strings.NewReader(in), Dialect{Comment: '#'})
+	dec.FieldsPerRecord = -1
+	want := [][][2]int{
+		{{2, 1}, {2, 5}, {2, 8}, {3, 4}},
+		{{6, 1}, {6, 3}, {6, 9}},
+	}
+	for n, pos := range want {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+		for i, p := range pos {
+			if line, col := dec.FieldPos(i); line != p[0] || col != p[1] {
+				t.Errorf("record %d field %d: position %d:%d, want %d:%d", n, i, line, col, p[0], p[1])
+			}
+		}
+	}
+}
+
+func TestFieldPosPreamble(t *testing.T) {
+	dec := NewDecoderDialect(strings.NewReader("\ufeffsep=;\na;b\n"), Excel)
+	dec.Decode()
+	if line, col := dec.FieldPos(1); line != 2 || col != 3 {
+		t.Errorf("position %d:%d, want 2:3", line, col)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for index out of range")
+		}
+	}()
+	dec.FieldPos(2)
+}
@@ -0,0 +1,47 @@
+package csv
+
+import "io"
+
+// Filter makes the decoder skip records for which pred returns false, so
+// rejected records are never returned by Decode, DecodeRecord,
+// DecodeValues or DecodeStruct. Records are tested with the header read by
+// ReadHeader, which is never filtered. Calling Filter again replaces the previous predicate; a nil
+// pred removes it.
+//
+// When all remaining records are rejected the decode methods return io.EOF.
+func (d *Decoder) Filter(pred func(Record) bool) {
+	d.filter = pred
+}
+
+// FilterExpr compiles expr with CompileFilter against the header read by
+// ReadHeader and filters records with it.
+func (d *Decoder) FilterExpr(expr string) error {
+	pred, err := CompileFilter(expr, d.header)
+	if err != nil {
+		return err
+	}
+	d.Filter(pred)
+	return nil
+}
+
+// accept reports whether the current record passes the filter and is
+// kept by Sample. The header is always accepted.
+func (d *Decoder) accept() bool {
+	if d.filter != nil && !d.inHeader {
+		d.record = d.fields(d.record[:0])
+		if !d.filter(Record{Fields: d.record, header: d.header, index: d.headerIndex}) {
+			return false
+		}
+	}
+	return d.sampled()
+}
+
+// skip is called by next after rejecting or sampling out a record. It returns io.EOF when
+// there are no more records to read.
+func (d *Decoder) skip() error {
+	if d.More() {
+		return nil
+	}
+	d.err = io.EOF
+	return d.err
+}
@@ -0,0 +1,120 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const filterInput = `country,amount,note
+US,150,big
+CA,150,big
+US,99.5,small
+US,1000,"a, b"
+FR,5,small
+`
+
+var filterTests = []struct {
+	Expr   string
+	Output [][]string
+	Error  string
+}{
+	{
+		Expr:   `amount > 100 && country == "US"`,
+		Output: [][]string{{"US", "150", "big"}, {"US", "1000", "a, b"}},
+	},
+	{
+		Expr:   `!(country == 'US') || amount < 100`,
+		Output: [][]string{{"CA", "150", "big"}, {"US", "99.5", "small"}, {"FR", "5", "small"}},
+	},
+	{
+		Expr:   "`note` != \"small\" && amount >= 150",
+		Output: [][]string{{"US", "150", "big"}, {"CA", "150", "big"}, {"US", "1000", "a, b"}},
+	},
+	{Expr: `price > 1`, Error: `unknown column "price"`},
+	{Expr: `amount >`, Error: `unexpected end of expression`},
+	{Expr: `amount`, Error: `expected comparison`},
+	{Expr: `(amount > 1`, Error: `missing )`},
+	{Expr: `country == "US`, Error: `unterminated quote`},
+}
+
+func TestFilterExpr(t *testing.T) {
+	for _, tt := range filterTests {
+		dec := NewDecoder(strings.NewReader(filterInput))
+		dec.ReadHeader()
+		err := dec.FilterExpr(tt.Expr)
+		if tt.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.Error) {
+				t.Errorf("%s: error %v, want error %q", tt.Expr, err, tt.Error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Expr, err)
+			continue
+		}
+
+		var out [][]string
+		for dec.More() {
+			record, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error %v", tt.Expr, err)
+			}
+			out = append(out, record)
+		}
+		if !reflect.DeepEqual(out, tt.Output) {
+			t.Errorf("%s: out=%q want %q", tt.Expr, out, tt.Output)
+		}
+	}
+}
+
+func TestFilterPredicate(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(filterInput))
+	dec.ReadHeader()
+	dec.Filter(func(r Record) bool {
+		note, _ := r.Get("note")
+		return note == "small"
+	})
+
+	var countries []string
+	for dec.More() {
+		r, err := dec.DecodeRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		country, _ := r.Get("country")
+		countries = append(countries, country)
+	}
+	if want := []string{"US", "FR"}; !reflect.DeepEqual(countries, want) {
+		t.Errorf("got %q, want %q", countries, want)
+	}
+}
+
+func TestFilterHeader(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(filterInput))
+	dec.Filter(func(r Record) bool { return r.Fields[0] == "CA" })
+	header, err := dec.ReadHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"country", "amount", "note"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header %q, want %q", header, want)
+	}
+	record, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"CA", "150", "big"}; !reflect.DeepEqual(record, want) {
+		t.Errorf("record %q, want %q", record, want)
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("error %v, want io.EOF", err)
+	}
+}
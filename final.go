@@ -0,0 +1,23 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrNoFinalNewline is returned in a ParseError for a last record not
+// terminated by a newline when RequireFinalNewline is set.
+var ErrNoFinalNewline = errors.New("missing newline at end of input")
+
+// checkFinalNewline applies RequireFinalNewline to the current record.
+func (d *Decoder) checkFinalNewline() error {
+	if !d.RequireFinalNewline || bytes.HasSuffix(d.raw, []byte("\n")) {
+		return nil
+	}
+	if d.scan.CRLineEndings && bytes.HasSuffix(d.raw, []byte("\r")) {
+		return nil
+	}
+	d.column = 0 // report at start of record
+	d.err = ErrNoFinalNewline
+	return d.error(d.err)
+}
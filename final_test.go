@@ -0,0 +1,69 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFinalRecordWithoutNewline(t *testing.T) {
+	tests := []struct {
+		in   string
+		want [][]string
+	}{
+		{"a,b", [][]string{{"a", "b"}}},
+		{"a,\"b\"", [][]string{{"a", "b"}}},
+		{"a,", [][]string{{"a", ""}}},
+		{"a,b\r", [][]string{{"a", "b"}}},
+		{"a,\"b\nc\"", [][]string{{"a", "b\nc"}}},
+		{"x,y\na,b", [][]string{{"x", "y"}, {"a", "b"}}},
+		{"\"a\"\"b\",c", [][]string{{"a\"b", "c"}}},
+		{"a,b\n#c", [][]string{{"a", "b"}}},
+	}
+	for _, tt := range tests {
+		for _, size := range []int{1, 2, 4096} {
+			dec := NewDecoderDialect(strings.NewReader(tt.in), Dialect{Comment: '#'})
+			dec.SetBufferSize(size)
+			var got [][]string
+			for {
+				record, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%q, buffer %d: %v", tt.in, size, err)
+				}
+				got = append(got, record)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q, buffer %d: got %q, want %q", tt.in, size, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestRequireFinalNewline(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b\nc,d"))
+	dec.RequireFinalNewline = true
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := dec.Decode()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Err != ErrNoFinalNewline || perr.Record != 2 || perr.Line != 2 {
+		t.Errorf("error %v, want ErrNoFinalNewline at record 2, line 2", err)
+	}
+
+	dec = NewDecoder(strings.NewReader("a,b\r\nc,d\n"))
+	dec.RequireFinalNewline = true
+	for i := 0; i < 2; i++ {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("at end: %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,188 @@
+package csv
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// defaultFollowPoll is the default interval at which a followed input is
+// checked for growth.
+const defaultFollowPoll = time.Second
+
+// FollowOptions configures Follow.
+type FollowOptions struct {
+	// Poll is the interval at which the input is checked for new data
+	// at its end. It defaults to one second.
+	Poll time.Duration
+
+	// If Reopen is true and the input is an *os.File, the file is
+	// reopened when it is rotated, that is when its name refers to
+	// another file, such as one created by logrotate, and read again from
+	// the start when it is truncated. The file is checked when all of it
+	// was read, so the records written to a rotated file before it was
+	// renamed are not lost.
+	Reopen bool
+
+	// If Header is true, a reopened file starts with a header, read as
+	// with ReadHeader and returned by Header, rather than as a record.
+	Header bool
+}
+
+// Follow makes the decoder follow its input like tail -f: at the end of
+// the input, it waits for the input to grow, such as a file being
+// appended to, rather than returning io.EOF. A record is only returned
+// once it is complete, so a line written in several parts is read whole.
+// Decoding stops with the error of Context when it is done. Follow must
+// be called before the first record is decoded.
+func (d *Decoder) Follow(opts FollowOptions) {
+	if opts.Poll <= 0 {
+		opts.Poll = defaultFollowPoll
+	}
+	f := &followReader{r: d.r, d: d, opts: opts, header: -1}
+	if opts.Reopen {
+		if f.file, _ = d.r.(*os.File); f.file != nil {
+			f.pos, _ = f.file.Seek(0, io.SeekCurrent)
+		}
+	}
+	d.follow = f
+	d.r = f
+}
+
+// A followReader reads from r, waiting for more input at its end.
+type followReader struct {
+	r    io.Reader
+	d    *Decoder
+	opts FollowOptions
+
+	file  *os.File // file reopened when rotated, or nil
+	owned bool     // whether file was opened by the reader
+	pos   int64    // offset in file
+
+	n       int64 // bytes returned
+	last    byte  // last byte returned
+	newline bool  // whether to return a newline ending the previous file
+	header  int64 // offset of the header of the reopened file, or -1
+}
+
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		if f.newline && len(p) > 0 {
+			f.newline = false
+			p[0] = '\n'
+			f.n++
+			f.last = '\n'
+			return 1, nil
+		}
+		n, err := f.r.Read(p)
+		f.pos += int64(n)
+		f.n += int64(n)
+		if n > 0 {
+			f.last = p[n-1]
+		}
+		if n > 0 || err != io.EOF {
+			if err == io.EOF {
+				err = nil
+			}
+			return n, err
+		}
+		reopened, err := f.reopen()
+		if err != nil {
+			return 0, err
+		}
+		if reopened {
+			continue
+		}
+		if err := f.wait(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// reopen switches to the file now at the name of the file read if it was
+// rotated, or reads it from the start if it was truncated, and reports
+// whether there is more to read. A rotated file written to since it was
+// read to its end is read to its end again first.
+func (f *followReader) reopen() (bool, error) {
+	if f.file == nil {
+		return false, nil
+	}
+	name := f.file.Name()
+	fi, err := os.Stat(name)
+	if err != nil {
+		// rotated, and the new file not created yet
+		return false, nil
+	}
+	cur, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case !os.SameFile(cur, fi) && cur.Size() > f.pos:
+		// written to before it was rotated
+		return true, nil
+	case !os.SameFile(cur, fi):
+		nf, err := os.Open(name)
+		if err != nil {
+			return false, nil
+		}
+		if f.owned {
+			f.file.Close()
+		}
+		f.file, f.r, f.owned = nf, nf, true
+	case fi.Size() < f.pos:
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+	default:
+		return false, nil
+	}
+	f.pos = 0
+	// end a last line left unterminated so it is not joined with the
+	// first line of the new file
+	f.newline = f.n > 0 && f.last != '\n'
+	if f.opts.Header {
+		f.header = f.n
+		if f.newline {
+			f.header++
+		}
+	}
+	f.d.debug("csv: followed file reopened", "file", name)
+	return true, nil
+}
+
+// headerAt reports whether the record at offset off of the input is the
+// header of a reopened file, to be read by ReadHeader.
+func (f *followReader) headerAt(off int64) bool {
+	if f == nil || f.header < 0 || off < f.header {
+		return false
+	}
+	f.header = -1
+	return true
+}
+
+// wait waits for the poll interval, or until the decoder's Context is
+// done.
+func (f *followReader) wait() error {
+	ctx := f.d.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	t := time.NewTimer(f.opts.Poll)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// close closes the file opened by the reader, if any.
+func (f *followReader) close() {
+	if f.owned {
+		f.file.Close()
+		f.owned = false
+	}
+}
@@ -0,0 +1,168 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.csv")
+	if err := ioutil.WriteFile(path, []byte("a,b\nc,"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dec := NewDecoder(f)
+	dec.Context = ctx
+	dec.Follow(FollowOptions{Poll: time.Millisecond})
+
+	decode := func(want []string) {
+		t.Helper()
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+	decode([]string{"a", "b"})
+
+	// the partial line is completed in two writes after the decoder
+	// reached the end of the file
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		w.WriteString("d")
+		time.Sleep(10 * time.Millisecond)
+		w.WriteString("\ne,f\n")
+	}()
+	decode([]string{"c", "d"})
+	decode([]string{"e", "f"})
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := dec.Decode(); !errors.Is(err, context.Canceled) {
+		t.Errorf("after cancel: error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestFollowReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.csv")
+	if err := ioutil.WriteFile(path, []byte("id\n1\n2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dec := NewDecoder(f)
+	dec.Context = ctx
+	dec.Follow(FollowOptions{Poll: time.Millisecond, Reopen: true, Header: true})
+	defer dec.Close()
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	decode := func(want string) {
+		t.Helper()
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0] != want {
+			t.Fatalf("got %q, want [%s]", got, want)
+		}
+	}
+	decode("1")
+
+	// rotated: the unterminated last line of the old file is still read
+	if err := os.Rename(path, filepath.Join(dir, "log.csv.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte("key\n3\n33\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	decode("2")
+	decode("3")
+	if h := dec.Header(); !reflect.DeepEqual(h, []string{"key"}) {
+		t.Errorf("header %q after rotation", h)
+	}
+	decode("33")
+
+	// truncated
+	if err := ioutil.WriteFile(path, []byte("id\n4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	decode("4")
+	if h := dec.Header(); !reflect.DeepEqual(h, []string{"id"}) {
+		t.Errorf("header %q after truncation", h)
+	}
+}
+
+func TestFollowReopenDrain(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.csv")
+	if err := ioutil.WriteFile(path, []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	dec := NewDecoder(f)
+	dec.Follow(FollowOptions{Poll: time.Millisecond, Reopen: true})
+	defer dec.Close()
+
+	p := make([]byte, 64)
+	read := func(want string) {
+		t.Helper()
+		n, err := dec.follow.Read(p)
+		if err != nil || string(p[:n]) != want {
+			t.Fatalf("read %q, %v, want %q", p[:n], err, want)
+		}
+	}
+	read("1\n")
+
+	// written to after it was read to its end, then rotated
+	old, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.WriteString("2\n"); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+	if err := os.Rename(path, filepath.Join(dir, "log.csv.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte("3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if more, err := dec.follow.reopen(); !more || err != nil {
+		t.Fatalf("reopen: %v, %v", more, err)
+	}
+	read("2\n")
+	read("3\n")
+}
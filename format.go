@@ -0,0 +1,115 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownFormat is returned for a format name that was not registered.
+var ErrUnknownFormat = errors.New("csv: unknown format")
+
+// A Format is a named way of reading and writing CSV, registered with
+// RegisterFormat so that a dialect and its quirks can be shared across
+// programs and looked up by name.
+type Format struct {
+	// Dialect is the syntax of the format.
+	Dialect Dialect
+	// Options further configure the decoders of the format, for example
+	// with a Schema or a Checksum.
+	Options []Option
+	// Reader, if not nil, wraps the input before it is decoded, for
+	// formats whose escaping the dialect cannot express. It is only used
+	// by NewDecoderFormat.
+	Reader func(io.Reader) io.Reader
+
+	// Encoder, if not nil, configures the encoders of the format. Their
+	// delimiter is otherwise that of Dialect.
+	Encoder func(*Encoder)
+	// Writer, if not nil, wraps the output of the encoders of the format,
+	// undoing what Reader does.
+	Writer func(io.Writer) io.Writer
+}
+
+var formats = struct {
+	sync.RWMutex
+	m map[string]Format
+}{m: map[string]Format{
+	"csv":   {},
+	"tsv":   {Dialect: Dialect{Delimiter: '\t'}},
+	"psv":   {Dialect: Dialect{Delimiter: '|'}},
+	"excel": {Dialect: Excel, Encoder: excelEncoder},
+}}
+
+func excelEncoder(e *Encoder) {
+	e.WriteBOM = true
+	e.UseCRLF = true
+}
+
+// RegisterFormat makes a format available by name to NewDecoderFormat and
+// NewEncoderFormat. The formats "csv", "tsv", "psv" and "excel" are
+// registered by the package. Registering a name twice replaces the
+// earlier format. It is safe to call from several goroutines.
+func RegisterFormat(name string, f Format) {
+	formats.Lock()
+	formats.m[name] = f
+	formats.Unlock()
+}
+
+// LookupFormat returns the format registered under name.
+func LookupFormat(name string) (Format, bool) {
+	formats.RLock()
+	f, ok := formats.m[name]
+	formats.RUnlock()
+	return f, ok
+}
+
+// FormatNames returns the names of the registered formats, sorted.
+func FormatNames() []string {
+	formats.RLock()
+	names := make([]string, 0, len(formats.m))
+	for name := range formats.m {
+		names = append(names, name)
+	}
+	formats.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// NewDecoderFormat returns a decoder reading r in the format registered
+// under name.
+func NewDecoderFormat(r io.Reader, name string) (*Decoder, error) {
+	f, ok := LookupFormat(name)
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	if f.Reader != nil {
+		r = f.Reader(r)
+	}
+	d := NewDecoderDialect(r, f.Dialect)
+	for _, opt := range f.Options {
+		opt(d)
+	}
+	return d, nil
+}
+
+// NewEncoderFormat returns an encoder writing to w in the format
+// registered under name.
+func NewEncoderFormat(w io.Writer, name string) (*Encoder, error) {
+	f, ok := LookupFormat(name)
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	if f.Writer != nil {
+		w = f.Writer(w)
+	}
+	e := NewEncoder(w)
+	if f.Dialect.Delimiter != 0 {
+		e.Delimiter = f.Dialect.Delimiter
+	}
+	if f.Encoder != nil {
+		f.Encoder(e)
+	}
+	return e, nil
+}
@@ -0,0 +1,58 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatRegistry(t *testing.T) {
+	RegisterFormat("test-pipe", Format{
+		Dialect: Dialect{Delimiter: '|', Comment: '#'},
+		Options: []Option{WithFieldsPerRecord(-1)},
+		Reader: func(r io.Reader) io.Reader {
+			return io.MultiReader(strings.NewReader("a|b\n"), r)
+		},
+		Encoder: func(e *Encoder) { e.UseCRLF = true },
+	})
+
+	dec, err := NewDecoderFormat(strings.NewReader("# export\n1|2|3\n"), "test-pipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records [][]string
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if want := [][]string{{"a", "b"}, {"1", "2", "3"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("records %q, want %q", records, want)
+	}
+
+	var out bytes.Buffer
+	enc, err := NewEncoderFormat(&out, "test-pipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Encode([]string{"x", "y|z"})
+	enc.Flush()
+	if got, want := out.String(), "x|\"y|z\"\r\n"; got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+
+	if _, err := NewDecoderFormat(strings.NewReader(""), "nope"); err != ErrUnknownFormat {
+		t.Errorf("error %v, want ErrUnknownFormat", err)
+	}
+	if _, ok := LookupFormat("tsv"); !ok {
+		t.Error("tsv is not registered")
+	}
+	names := FormatNames()
+	if i := len(names) - 1; names[0] != "csv" || names[i] != "tsv" {
+		t.Errorf("format names %q", names)
+	}
+}
@@ -0,0 +1,69 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+)
+
+// Metadata returns the key-value pairs of the Frontmatter block at the
+// start of the input, or nil if there is none. It reads the block if no
+// record has been decoded yet.
+//
+// The block is made of the lines starting with '#', or with the comment
+// prefix of the dialect if it has one, before the first other line. Each
+// line of the form "# key: value" sets key to value, with surrounding
+// white space removed; a later line for the same key replaces the value.
+// Other lines of the block, such as "# ---", are ignored.
+func (d *Decoder) Metadata() map[string]string {
+	if !d.started {
+		d.preamble()
+	}
+	return d.metadata
+}
+
+// readFrontmatter reads the metadata lines at the start of the input.
+func (d *Decoder) readFrontmatter() {
+	prefix := []byte{'#'}
+	if d.commentPrefix != nil {
+		prefix = d.commentPrefix
+	}
+	var err error
+	for {
+		for err == nil && bytes.IndexByte(d.buf[d.scanp:], '\n') < 0 {
+			err = d.refill()
+		}
+		if err != nil && err != io.EOF {
+			d.err = err
+			return
+		}
+		line := d.buf[d.scanp:]
+		if !bytes.HasPrefix(line, prefix) {
+			return
+		}
+		end := bytes.IndexByte(line, '\n')
+		next := end + 1
+		if end < 0 {
+			end, next = len(line), len(line)
+		}
+		d.addMetadata(line[len(prefix):end])
+		d.scanp += next
+		d.line++
+		d.lineStart = d.offset + int64(d.scanp)
+	}
+}
+
+// addMetadata adds the key and value of a line of the Frontmatter block.
+func (d *Decoder) addMetadata(line []byte) {
+	i := bytes.IndexByte(line, ':')
+	if i < 0 {
+		return
+	}
+	key := bytes.TrimSpace(line[:i])
+	if len(key) == 0 {
+		return
+	}
+	if d.metadata == nil {
+		d.metadata = make(map[string]string)
+	}
+	d.metadata[string(key)] = string(bytes.TrimSpace(line[i+1:]))
+}
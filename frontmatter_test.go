@@ -0,0 +1,58 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFrontmatter(t *testing.T) {
+	tests := []struct {
+		in       string
+		dialect  Dialect
+		metadata map[string]string
+		records  [][]string
+		line     int // line of the header
+	}{
+		{"# source: crm\n# exported: 2024-01-02T10:00:00Z\n# ---\nid,name\n1,ann\n", Dialect{},
+			map[string]string{"source": "crm", "exported": "2024-01-02T10:00:00Z"},
+			[][]string{{"id", "name"}, {"1", "ann"}}, 4},
+		{"\ufeffsep=;\r\n#a:1\r\n#a: 2 \r\nid;name\r\n", Excel,
+			map[string]string{"a": "2"},
+			[][]string{{"id", "name"}}, 4},
+		{"-- k: v\nid\n", Dialect{CommentPrefix: "--"}, map[string]string{"k": "v"}, [][]string{{"id"}}, 2},
+		{"id,name\n# not: metadata\n", Dialect{}, nil, [][]string{{"id", "name"}, {"# not: metadata"}}, 1},
+		{"# only: block", Dialect{}, map[string]string{"only": "block"}, nil, 0},
+	}
+	for _, tt := range tests {
+		for _, size := range []int{1, 4096} {
+			dec := NewDecoderDialect(strings.NewReader(tt.in), tt.dialect)
+			dec.Frontmatter = true
+			dec.FieldsPerRecord = -1
+			dec.SetBufferSize(size)
+			if got := dec.Metadata(); !reflect.DeepEqual(got, tt.metadata) {
+				t.Errorf("%q, buffer %d: metadata %q, want %q", tt.in, size, got, tt.metadata)
+			}
+			var records [][]string
+			for {
+				record, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%q, buffer %d: %v", tt.in, size, err)
+				}
+				if records == nil {
+					if line, _ := dec.FieldPos(0); line != tt.line {
+						t.Errorf("%q, buffer %d: header on line %d, want %d", tt.in, size, line, tt.line)
+					}
+				}
+				records = append(records, record)
+			}
+			if !reflect.DeepEqual(records, tt.records) {
+				t.Errorf("%q, buffer %d: records %q, want %q", tt.in, size, records, tt.records)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// HashRecord returns the digest computed by h of the fields of record
+// selected by cols, or of all its fields if cols is nil. Each field is
+// written with its length, so records splitting the same bytes into
+// different fields hash differently. h is reset first; missing fields
+// hash as empty.
+func HashRecord(record []string, cols []int, h hash.Hash) []byte {
+	h.Reset()
+	writeRecord(h, record, cols)
+	return h.Sum(nil)
+}
+
+func writeRecord(h hash.Hash, record []string, cols []int) {
+	var n [binary.MaxVarintLen64]byte
+	write := func(field string) {
+		h.Write(n[:binary.PutUvarint(n[:], uint64(len(field)))])
+		io.WriteString(h, field)
+	}
+	if cols == nil {
+		for _, field := range record {
+			write(field)
+		}
+		return
+	}
+	for _, col := range cols {
+		write(fieldAt(record, col))
+	}
+}
+
+// FingerprintOptions configures FileFingerprint.
+type FingerprintOptions struct {
+	// Dialect describes the syntax of the input.
+	Dialect Dialect
+
+	// Columns selects the columns hashed, or all if nil.
+	Columns []int
+
+	// If SkipHeader is true, the first record is not hashed.
+	SkipHeader bool
+
+	// If Unordered is true, the fingerprint is that of the multiset of the
+	// records: inputs holding the same records in any order have the same
+	// fingerprint. Otherwise the order of the records matters.
+	Unordered bool
+
+	// Hash returns the hash applied to each record, SHA-256 if nil.
+	Hash func() hash.Hash
+}
+
+// FileFingerprint reads the records of r and returns a hex-encoded
+// fingerprint of their content, which unlike a digest of the raw bytes
+// does not depend on quoting, line endings or other details of the syntax.
+//
+// An unordered fingerprint is the sum modulo 2^(8n) of the n-byte digests
+// of the records.
+func FileFingerprint(r io.Reader, opts FingerprintOptions) (string, error) {
+	newHash := opts.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	dec := NewDecoderDialect(r, opts.Dialect)
+	dec.FieldsPerRecord = -1
+	defer dec.Close()
+
+	h := newHash()
+	seq := newHash()
+	var sum []byte
+	first := true
+	for dec.More() {
+		record, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if first && opts.SkipHeader {
+			first = false
+			continue
+		}
+		first = false
+
+		digest := HashRecord(record, opts.Columns, h)
+		if !opts.Unordered {
+			seq.Write(digest)
+			continue
+		}
+		if sum == nil {
+			sum = make([]byte, len(digest))
+		}
+		addDigest(sum, digest)
+	}
+	if !opts.Unordered {
+		sum = seq.Sum(nil)
+	} else if sum == nil {
+		sum = make([]byte, h.Size())
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// addDigest adds the big-endian number d to sum, modulo its size.
+func addDigest(sum, d []byte) {
+	carry := 0
+	for i := len(sum) - 1; i >= 0; i-- {
+		v := int(sum[i]) + int(d[i]) + carry
+		sum[i], carry = byte(v), v>>8
+	}
+}
@@ -0,0 +1,59 @@
+package csv
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestHashRecord(t *testing.T) {
+	h := sha256.New()
+	a := HashRecord([]string{"ab", "c"}, nil, h)
+	b := HashRecord([]string{"a", "bc"}, nil, h)
+	if bytes.Equal(a, b) {
+		t.Error("records splitting the same bytes differently hash the same")
+	}
+	c := HashRecord([]string{"x", "ab", "y", "c"}, []int{1, 3}, h)
+	if !bytes.Equal(a, c) {
+		t.Error("selected columns hash differently from the same fields")
+	}
+	if got := HashRecord([]string{"a"}, []int{0, 5}, h); !bytes.Equal(got, HashRecord([]string{"a", ""}, nil, h)) {
+		t.Error("missing field does not hash as empty")
+	}
+}
+
+func TestFileFingerprint(t *testing.T) {
+	day1 := "id,name\n1,ann\n2,bob\n"
+	day2 := "id,name\r\n\"2\",bob\r\n\"1\",ann\r\n"
+	day3 := "id,name\n1,ann\n2,bobby\n"
+
+	fp := func(in string, opts FingerprintOptions) string {
+		s, err := FileFingerprint(strings.NewReader(in), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	unordered := FingerprintOptions{Unordered: true}
+	if fp(day1, unordered) != fp(day2, unordered) {
+		t.Error("unordered: same records differ")
+	}
+	if fp(day1, unordered) == fp(day3, unordered) {
+		t.Error("unordered: changed record not detected")
+	}
+	if fp(day1, FingerprintOptions{}) == fp(day2, FingerprintOptions{}) {
+		t.Error("ordered: reordered records not detected")
+	}
+	byID := FingerprintOptions{Columns: []int{0}, SkipHeader: true, Unordered: true}
+	if fp(day1, byID) != fp(day3, byID) {
+		t.Error("column selection ignored")
+	}
+	if s := fp(day1, FingerprintOptions{Hash: md5.New}); len(s) != 32 {
+		t.Errorf("md5 fingerprint %q", s)
+	}
+	if s := fp("", unordered); s != strings.Repeat("0", 64) {
+		t.Errorf("empty fingerprint %q", s)
+	}
+}
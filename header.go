@@ -0,0 +1,254 @@
+package csv
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A DuplicatePolicy controls how ReadHeader handles column names that
+// appear more than once in the header.
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeepFirst binds a repeated name to its first column; later
+	// columns with the same name can only be reached by position.
+	DuplicateKeepFirst DuplicatePolicy = iota
+	// DuplicateError makes ReadHeader fail on a repeated name.
+	DuplicateError
+	// DuplicateSuffix renames every repeat of a name by appending an
+	// underscore and its column index, so "id,x,id" becomes "id,x,id_2",
+	// and then an underscore and a number if that is the name of another
+	// column, so "a,a,a_1" becomes "a,a_1_2,a_1".
+	DuplicateSuffix
+)
+
+// A MissingPolicy controls how DecodeStruct handles required fields for
+// which the record has no column.
+type MissingPolicy int
+
+const (
+	// MissingZero sets fields without a column to their zero value.
+	MissingZero MissingPolicy = iota
+	// MissingError makes DecodeStruct fail when a required field has no
+	// column.
+	MissingError
+)
+
+// A MismatchReport describes how a header differs from what a struct
+// expects. All names are column names.
+type MismatchReport struct {
+	Duplicates []string // names that appear more than once in the header
+	Missing    []string // required struct fields without a column
+	Extra      []string // columns not bound to any struct field
+}
+
+// OK reports whether the report found no duplicates and no missing
+// columns. Extra columns are not considered a mismatch.
+func (r *MismatchReport) OK() bool {
+	return len(r.Duplicates) == 0 && len(r.Missing) == 0
+}
+
+// A MismatchError is returned when a header violates DuplicateHeaders or
+// a struct violates MissingColumns.
+type MismatchError struct {
+	Report *MismatchReport
+}
+
+func (e *MismatchError) Error() string {
+	var parts []string
+	if len(e.Report.Duplicates) > 0 {
+		parts = append(parts, "duplicate columns "+strings.Join(e.Report.Duplicates, ", "))
+	}
+	if len(e.Report.Missing) > 0 {
+		parts = append(parts, "missing columns "+strings.Join(e.Report.Missing, ", "))
+	}
+	return "header mismatch: " + strings.Join(parts, "; ")
+}
+
+// ReadHeader decodes the next record and uses it as the header of the
+// stream. Once a header has been read, DecodeStruct binds struct fields
+// to columns by name instead of by position.
+//
+// Column names are first rewritten by NormalizeHeader, if set. Repeated
+// column names are then handled according to DuplicateHeaders.
+func (d *Decoder) ReadHeader() ([]string, error) {
+	d.inHeader = true
+	header, err := d.Decode()
+	d.inHeader = false
+	if err != nil {
+		return nil, err
+	}
+	if d.NormalizeHeader != nil {
+		for i, name := range header {
+			header[i] = d.NormalizeHeader(name)
+		}
+	}
+
+	if dups := duplicates(header); len(dups) > 0 {
+		switch d.DuplicateHeaders {
+		case DuplicateError:
+			d.column = 0
+			d.err = &MismatchError{Report: &MismatchReport{Duplicates: dups}}
+			return nil, d.error(d.err)
+		case DuplicateSuffix:
+			taken := make(map[string]bool, len(header))
+			for _, name := range header {
+				taken[name] = true
+			}
+			seen := make(map[string]bool, len(header))
+			for i, name := range header {
+				if seen[name] {
+					suffixed := name + "_" + strconv.Itoa(i)
+					renamed := suffixed
+					for n := 2; taken[renamed]; n++ {
+						renamed = suffixed + "_" + strconv.Itoa(n)
+					}
+					header[i] = renamed
+					taken[renamed] = true
+				}
+				seen[name] = true
+			}
+		}
+	}
+
+	d.setHeader(header)
+	return header, nil
+}
+
+// Header returns the header read by ReadHeader, or nil if no header has
+// been read.
+func (d *Decoder) Header() []string {
+	return d.header
+}
+
+// Mismatch compares the header with the fields of the struct pointed to
+// by v, as bound by DecodeStruct. It must be called after ReadHeader.
+func (d *Decoder) Mismatch(v interface{}) (*MismatchReport, error) {
+	fields, err := structFieldsOf(v)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MismatchReport{Duplicates: duplicates(d.header)}
+	bound := make(map[int]bool, len(fields))
+	for _, f := range fields {
+		col := d.columnIndex(f.name)
+		if col < 0 {
+			if f.required {
+				report.Missing = append(report.Missing, f.name)
+			}
+			continue
+		}
+		bound[col] = true
+	}
+	for i, name := range d.header {
+		if !bound[i] {
+			report.Extra = append(report.Extra, name)
+		}
+	}
+	return report, nil
+}
+
+// setHeader records header as the stream header and indexes its names.
+func (d *Decoder) setHeader(header []string) {
+	d.header = header
+	d.headerID = groupID(header)
+	d.headerIndex = make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := d.headerIndex[name]; !ok {
+			d.headerIndex[name] = i
+		}
+	}
+}
+
+// columnIndex returns the index of the column called name, or -1 if the header
+// has no such column. The name is normalized like the header.
+func (d *Decoder) columnIndex(name string) int {
+	if i, ok := d.headerIndex[name]; ok {
+		return i
+	}
+	if d.NormalizeHeader != nil {
+		if i, ok := d.headerIndex[d.NormalizeHeader(name)]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// duplicates returns the names that appear more than once in header, in
+// order of their first repeat.
+func duplicates(header []string) []string {
+	var dups []string
+	seen := make(map[string]int, len(header))
+	for _, name := range header {
+		seen[name]++
+		if seen[name] == 2 {
+			dups = append(dups, name)
+		}
+	}
+	return dups
+}
+
+// A structField is an exported struct field bound to a column.
+type structField struct {
+	index    int    // index of the field in the struct
+	name     string // column name
+	required bool
+}
+
+// structFieldsOf returns the fields bound by DecodeStruct for the struct
+// pointed to by v.
+func structFieldsOf(v interface{}) ([]structField, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, errNotStructPointer
+	}
+	return cachedStructFields(rv.Elem().Type()), nil
+}
+
+// structFieldCache holds the fields of the struct types decoded so far.
+var structFieldCache sync.Map // map[reflect.Type][]structField
+
+// cachedStructFields is like structFields but computes the fields of each
+// type once.
+func cachedStructFields(t reflect.Type) []structField {
+	if fields, ok := structFieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	fields, _ := structFieldCache.LoadOrStore(t, structFields(t))
+	return fields.([]structField)
+}
+
+// structFields returns the fields of t that DecodeStruct binds to columns.
+// The column name of a field is taken from its "csv" tag, falling back to
+// the field name. The tag option "optional" marks a field whose column may
+// be missing regardless of MissingColumns, and fields tagged "-" are
+// skipped.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag := sf.Tag.Get("csv")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if j := strings.IndexByte(tag, ','); j >= 0 {
+			name, opts = tag[:j], tag[j+1:]
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, structField{
+			index:    i,
+			name:     name,
+			required: opts != "optional",
+		})
+	}
+	return fields
+}
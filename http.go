@@ -0,0 +1,119 @@
+package csv
+
+import (
+	"context"
+	"mime"
+	"net/http"
+)
+
+// An HTTPHandler is an http.Handler streaming the records of a query as a
+// CSV response. The response is sent in chunks as the query produces
+// records rather than buffered whole, and the query's context is canceled
+// when the client disconnects.
+type HTTPHandler struct {
+	// Query writes the records of the response to sink, the header of
+	// the first record, if any, first. The sink returns the context's
+	// error once the client is gone.
+	Query func(ctx context.Context, r *http.Request, sink Sink) error
+
+	// Filename, if not empty, is the name under which browsers save the
+	// response, set in its Content-Disposition header.
+	Filename string
+
+	// If WriteBOM is true, the response starts with a UTF-8 byte order
+	// mark, so spreadsheets detect the encoding.
+	WriteBOM bool
+
+	// FlushEvery is the number of records sent to the client at a time.
+	// It defaults to 100.
+	FlushEvery int
+
+	// Logger, if not nil, logs errors returned by Query.
+	Logger Logger
+}
+
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set("Content-Type", "text/csv; charset=utf-8")
+	if h.Filename != "" {
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": h.Filename}))
+	}
+
+	out := &responseWriter{w: w}
+	s := &httpSink{CSVSink: NewCSVSink(out), ctx: r.Context(), out: out, every: h.FlushEvery}
+	if s.every <= 0 {
+		s.every = 100
+	}
+	s.Encoder.WriteBOM = h.WriteBOM
+
+	err := h.Query(s.ctx, r, s)
+	if err == nil {
+		err = s.Close()
+	}
+	if err == nil || s.ctx.Err() != nil {
+		return
+	}
+	if h.Logger != nil {
+		h.Logger.Error("csv: query failed", "path", r.URL.Path, "error", err)
+	}
+	if !out.wrote {
+		header.Del("Content-Disposition")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	// The status was sent: abort the response so the client sees it is
+	// incomplete.
+	panic(http.ErrAbortHandler)
+}
+
+// An httpSink is the CSVSink of an HTTPHandler's response.
+type httpSink struct {
+	*CSVSink
+	ctx     context.Context
+	out     *responseWriter
+	every   int
+	pending int
+}
+
+func (s *httpSink) Write(r Record) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.CSVSink.Write(r); err != nil {
+		return err
+	}
+	if s.pending++; s.pending >= s.every {
+		s.pending = 0
+		if err := s.Encoder.Flush(); err != nil {
+			return err
+		}
+		s.out.flush()
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	if err := s.CSVSink.Close(); err != nil {
+		return err
+	}
+	s.out.flush()
+	return nil
+}
+
+// A responseWriter writes to an http.ResponseWriter and records whether
+// anything was written.
+type responseWriter struct {
+	w     http.ResponseWriter
+	wrote bool
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.w.Write(p)
+}
+
+func (w *responseWriter) flush() {
+	if f, ok := w.w.(http.Flusher); ok && w.wrote {
+		f.Flush()
+	}
+}
@@ -0,0 +1,81 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	h := &HTTPHandler{
+		Filename:   "report 1.csv",
+		WriteBOM:   true,
+		FlushEvery: 1,
+		Query: func(ctx context.Context, r *http.Request, sink Sink) error {
+			header := []string{"id", "name"}
+			for _, fields := range [][]string{{"1", "ann"}, {"2", "b,o"}} {
+				if err := sink.Write(NewRecord(header, fields)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/report", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type %q", got)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="report 1.csv"` {
+		t.Errorf("Content-Disposition %q", got)
+	}
+	if got, want := w.Body.String(), "\ufeffid,name\n1,ann\n2,\"b,o\"\n"; got != want {
+		t.Errorf("body %q, want %q", got, want)
+	}
+	if !w.Flushed {
+		t.Error("response not flushed")
+	}
+}
+
+func TestHTTPHandlerError(t *testing.T) {
+	h := &HTTPHandler{Query: func(ctx context.Context, r *http.Request, sink Sink) error {
+		return errors.New("db down")
+	}}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "db down") {
+		t.Errorf("status %d, body %q", w.Code, w.Body.String())
+	}
+
+	h = &HTTPHandler{FlushEvery: 1, Query: func(ctx context.Context, r *http.Request, sink Sink) error {
+		sink.Write(NewRecord(nil, []string{"a"}))
+		return errors.New("db down")
+	}}
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestHTTPHandlerCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var werr error
+	h := &HTTPHandler{Query: func(ctx context.Context, r *http.Request, sink Sink) error {
+		sink.Write(NewRecord(nil, []string{"a"}))
+		cancel()
+		werr = sink.Write(NewRecord(nil, []string{"b"}))
+		return werr
+	}}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	if werr != context.Canceled {
+		t.Errorf("Write() after disconnect = %v, want %v", werr, context.Canceled)
+	}
+}
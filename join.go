@@ -0,0 +1,187 @@
+package csv
+
+import (
+	"errors"
+	"io"
+)
+
+// A JoinType selects which records MergeJoin emits.
+type JoinType int
+
+const (
+	// InnerJoin emits only left records with a matching right record.
+	InnerJoin JoinType = iota
+	// LeftJoin also emits left records without a match, padded with
+	// empty right fields.
+	LeftJoin
+)
+
+// JoinOptions configures MergeJoin.
+type JoinOptions struct {
+	// LeftKeys and RightKeys select the join columns of each input,
+	// pairwise, so there must be as many of each. Each input must be
+	// sorted by its keys, whose Numeric and Descending flags describe its
+	// order; they must be the same for both inputs.
+	LeftKeys, RightKeys []SortKey
+
+	Type JoinType
+
+	// LeftDialect and RightDialect describe the syntax of each input.
+	LeftDialect, RightDialect Dialect
+}
+
+// MergeJoin joins the records of two inputs sorted by their key columns and
+// writes the combined records to w. Both inputs start with a header. The
+// output header and records are made of the left fields followed by the
+// right fields other than the right key columns.
+//
+// Records are joined as they are read: only the run of right records
+// sharing the current key is held in memory.
+func MergeJoin(left, right io.Reader, w io.Writer, opts JoinOptions) error {
+	if len(opts.LeftKeys) != len(opts.RightKeys) {
+		return errors.New("csv: join needs as many left keys as right keys")
+	}
+	for i, k := range opts.LeftKeys {
+		if rk := opts.RightKeys[i]; k.Numeric != rk.Numeric || k.Descending != rk.Descending {
+			return errors.New("csv: join keys must have the same order on both sides")
+		}
+	}
+	l := newJoinSide(left, opts.LeftDialect)
+	r := newJoinSide(right, opts.RightDialect)
+	enc := NewEncoder(w)
+
+	lheader, err := l.next()
+	if err != nil || lheader == nil {
+		return err
+	}
+	rheader, err := r.next()
+	if err != nil {
+		return err
+	}
+
+	isKey := make(map[int]bool, len(opts.RightKeys))
+	for _, k := range opts.RightKeys {
+		isKey[k.Column] = true
+	}
+	project := func(dst, right []string, width int) []string {
+		for i := 0; i < width; i++ {
+			if !isKey[i] {
+				dst = append(dst, fieldAt(right, i))
+			}
+		}
+		return dst
+	}
+	width := len(rheader)
+	if err := enc.Encode(project(append([]string(nil), lheader...), rheader, width)); err != nil {
+		return err
+	}
+
+	lrec, err := l.next()
+	if err != nil {
+		return err
+	}
+	group, err := r.group(opts.RightKeys)
+	if err != nil {
+		return err
+	}
+
+	out := make([]string, 0, len(lheader)+width)
+	for lrec != nil {
+		c := -1
+		if len(group) > 0 {
+			c = compareKeys(lrec, group[0], opts.LeftKeys, opts.RightKeys)
+		}
+		switch {
+		case c < 0:
+			if opts.Type == LeftJoin {
+				out = project(append(out[:0], lrec...), nil, width)
+				if err := enc.Encode(out); err != nil {
+					return err
+				}
+			}
+			if lrec, err = l.next(); err != nil {
+				return err
+			}
+		case c > 0:
+			if group, err = r.group(opts.RightKeys); err != nil {
+				return err
+			}
+		default:
+			for _, rrec := range group {
+				out = project(append(out[:0], lrec...), rrec, width)
+				if err := enc.Encode(out); err != nil {
+					return err
+				}
+			}
+			if lrec, err = l.next(); err != nil {
+				return err
+			}
+		}
+	}
+	return enc.Flush()
+}
+
+// compareKeys compares the key fields of a left and a right record.
+func compareKeys(l, r []string, lkeys, rkeys []SortKey) int {
+	for i, k := range lkeys {
+		c := compareFields(fieldAt(l, k.Column), fieldAt(r, rkeys[i].Column), k.Numeric)
+		if k.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// A joinSide reads one input of a join with one record of lookahead.
+type joinSide struct {
+	dec     *Decoder
+	peeked  []string
+	grouped [][]string
+}
+
+func newJoinSide(r io.Reader, dialect Dialect) *joinSide {
+	dec := NewDecoderDialect(r, dialect)
+	dec.FieldsPerRecord = -1
+	return &joinSide{dec: dec}
+}
+
+// next returns the next record, or nil at the end of the input.
+func (s *joinSide) next() ([]string, error) {
+	if s.peeked != nil {
+		record := s.peeked
+		s.peeked = nil
+		return record, nil
+	}
+	if !s.dec.More() {
+		return nil, nil
+	}
+	return s.dec.Decode()
+}
+
+// group returns the next run of records with equal keys, or nil at the end
+// of the input. The returned slice is reused by the following call.
+func (s *joinSide) group(keys []SortKey) ([][]string, error) {
+	s.grouped = s.grouped[:0]
+	first, err := s.next()
+	if err != nil || first == nil {
+		return nil, err
+	}
+	s.grouped = append(s.grouped, first)
+	for {
+		record, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		if record == nil {
+			return s.grouped, nil
+		}
+		if compareRecords(first, record, keys) != 0 {
+			s.peeked = record
+			return s.grouped, nil
+		}
+		s.grouped = append(s.grouped, record)
+	}
+}
@@ -0,0 +1,72 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var joinTests = []struct {
+	Name   string
+	Left   string
+	Right  string
+	Type   JoinType
+	Output string
+}{
+	{
+		Name:   "Inner",
+		Left:   "id,name\n1,ann\n2,bob\n4,dan\n",
+		Right:  "id;city\n1;rome\n3;oslo\n4;lima\n4;kiev\n",
+		Output: "id,name,city\n1,ann,rome\n4,dan,lima\n4,dan,kiev\n",
+	},
+	{
+		Name:   "Left",
+		Left:   "id,name\n1,ann\n2,bob\n4,dan\n5,eve\n",
+		Right:  "id;city\n1;rome\n3;oslo\n4;lima\n",
+		Type:   LeftJoin,
+		Output: "id,name,city\n1,ann,rome\n2,bob,\n4,dan,lima\n5,eve,\n",
+	},
+	{
+		Name:   "ManyToMany",
+		Left:   "id,n\n1,a\n1,b\n",
+		Right:  "id;m\n1;x\n1;y\n",
+		Output: "id,n,m\n1,a,x\n1,a,y\n1,b,x\n1,b,y\n",
+	},
+	{
+		Name:   "EmptyRight",
+		Left:   "id,n\n1,a\n",
+		Right:  "id;m\n",
+		Type:   LeftJoin,
+		Output: "id,n,m\n1,a,\n",
+	},
+}
+
+func TestMergeJoin(t *testing.T) {
+	for _, tt := range joinTests {
+		var out bytes.Buffer
+		err := MergeJoin(strings.NewReader(tt.Left), strings.NewReader(tt.Right), &out, JoinOptions{
+			LeftKeys:     []SortKey{{Column: 0, Numeric: true}},
+			RightKeys:    []SortKey{{Column: 0, Numeric: true}},
+			Type:         tt.Type,
+			RightDialect: Dialect{Delimiter: ';'},
+		})
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.Name, err)
+		} else if out.String() != tt.Output {
+			t.Errorf("%s: out=%q want %q", tt.Name, out.String(), tt.Output)
+		}
+	}
+}
+
+func TestMergeJoinKeys(t *testing.T) {
+	for _, opts := range []JoinOptions{
+		{LeftKeys: []SortKey{{Column: 0}, {Column: 1}}, RightKeys: []SortKey{{Column: 0}}},
+		{LeftKeys: []SortKey{{Column: 0}}},
+		{LeftKeys: []SortKey{{Column: 0, Numeric: true}}, RightKeys: []SortKey{{Column: 0}}},
+	} {
+		var out bytes.Buffer
+		if err := MergeJoin(strings.NewReader("a,b\n1,2\n"), strings.NewReader("a\n1\n"), &out, opts); err == nil {
+			t.Errorf("%+v: no error", opts)
+		}
+	}
+}
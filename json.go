@@ -0,0 +1,161 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// ExplodeJSON returns a transform parsing the fields of column as JSON
+// objects and appending a column for each of keys with its value. A key
+// may be a dotted path such as "address.city" to reach nested objects.
+//
+// Strings are written without quotes, null and missing keys as empty
+// fields, and objects and arrays as compact JSON. An empty field is read
+// as an empty object.
+func ExplodeJSON(column string, keys ...string) Transform {
+	return &explodeJSON{column: column, keys: keys}
+}
+
+type explodeJSON struct {
+	column string
+	keys   []string
+	col    int
+	width  int
+}
+
+func (t *explodeJSON) Header(header []string) ([]string, error) {
+	cols, err := columnIndexes(header, []string{t.column})
+	if err != nil {
+		return nil, err
+	}
+	t.col, t.width = cols[0], len(header)
+	return append(header[:len(header):len(header)], t.keys...), nil
+}
+
+func (t *explodeJSON) Apply(r Record) ([]string, error) {
+	field := fieldAt(r.Fields, t.col)
+	var obj map[string]interface{}
+	if strings.TrimSpace(field) != "" {
+		dec := json.NewDecoder(strings.NewReader(field))
+		dec.UseNumber()
+		if err := dec.Decode(&obj); err != nil {
+			return nil, &TransformError{Column: t.column, Value: field, Err: err}
+		}
+	}
+
+	for len(r.Fields) < t.width {
+		r.Fields = append(r.Fields, "")
+	}
+	out := r.Fields[:t.width]
+	for _, key := range t.keys {
+		v, err := jsonField(lookupJSON(obj, key))
+		if err != nil {
+			return nil, &TransformError{Column: t.column, Value: field, Err: err}
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// lookupJSON returns the value at the dotted path key of obj, or nil.
+func lookupJSON(obj map[string]interface{}, key string) interface{} {
+	if v, ok := obj[key]; ok {
+		return v
+	}
+	for {
+		i := strings.IndexByte(key, '.')
+		if i < 0 {
+			return nil
+		}
+		inner, ok := obj[key[:i]].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		obj, key = inner, key[i+1:]
+		if v, ok := obj[key]; ok {
+			return v
+		}
+	}
+}
+
+// jsonField formats a JSON value as a field.
+func jsonField(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// PackJSON returns a transform removing the columns called names and
+// appending a column called column holding a JSON object that maps each
+// name to its field, as a string, in the order of names.
+func PackJSON(column string, names ...string) Transform {
+	return &packJSON{column: column, names: names}
+}
+
+type packJSON struct {
+	column string
+	names  []string
+	cols   []int
+	keep   []int
+}
+
+func (t *packJSON) Header(header []string) ([]string, error) {
+	cols, err := columnIndexes(header, t.names)
+	if err != nil {
+		return nil, err
+	}
+	packed := make(map[int]bool, len(cols))
+	for _, col := range cols {
+		packed[col] = true
+	}
+	var out []string
+	t.cols, t.keep = cols, nil
+	for i, name := range header {
+		if !packed[i] {
+			t.keep = append(t.keep, i)
+			out = append(out, name)
+		}
+	}
+	return append(out, t.column), nil
+}
+
+func (t *packJSON) Apply(r Record) ([]string, error) {
+	var b bytes.Buffer
+	writeJSONObject(&b, t.names, func(i int) string { return fieldAt(r.Fields, t.cols[i]) })
+
+	out := make([]string, 0, len(t.keep)+1)
+	for _, col := range t.keep {
+		out = append(out, fieldAt(r.Fields, col))
+	}
+	return append(out, b.String()), nil
+}
+
+// writeJSONObject writes a JSON object to b with the given keys, in order,
+// and the string values returned by value for each index.
+func writeJSONObject(b *bytes.Buffer, keys []string, value func(i int) string) {
+	b.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, _ := json.Marshal(value(i))
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+}
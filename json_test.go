@@ -0,0 +1,71 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExplodeJSON(t *testing.T) {
+	in := `id,payload
+1,"{""name"":""Ann"",""age"":41,""address"":{""city"":""Oslo""},""tags"":[""a"",""b""],""vip"":true}"
+2,"{""name"":""Bob"",""address"":null}"
+3,
+`
+	var out bytes.Buffer
+	err := Reencode(strings.NewReader(in), &out, ReencodeOptions{},
+		ExplodeJSON("payload", "name", "age", "address.city", "tags", "vip"),
+		DropColumns("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `id,name,age,address.city,tags,vip
+1,Ann,41,Oslo,"[""a"",""b""]",true
+2,Bob,,,,
+3,,,,,
+`
+	if got := out.String(); got != want {
+		t.Errorf("output\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExplodeJSONInvalid(t *testing.T) {
+	var out bytes.Buffer
+	err := Reencode(strings.NewReader("id,j\n1,{oops\n"), &out, ReencodeOptions{}, ExplodeJSON("j", "a"))
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("error %v, want ParseError", err)
+	}
+	if terr, ok := perr.Err.(*TransformError); !ok || terr.Column != "j" {
+		t.Errorf("error %v, want TransformError for column j", err)
+	}
+}
+
+func TestPackJSON(t *testing.T) {
+	in := "id,name,city\n1,Ann,\"Oslo, NO\"\n2,\"B\"\"ob\"\n"
+	var out bytes.Buffer
+	if err := Reencode(strings.NewReader(in), &out, ReencodeOptions{}, PackJSON("attrs", "name", "city")); err != nil {
+		t.Fatal(err)
+	}
+	want := `id,attrs
+1,"{""name"":""Ann"",""city"":""Oslo, NO""}"
+2,"{""name"":""B\""ob"",""city"":""""}"
+`
+	if got := out.String(); got != want {
+		t.Errorf("output\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPackExplodeRoundTrip(t *testing.T) {
+	in := "id,name,city\n1,Ann,Oslo\n"
+	var packed, out bytes.Buffer
+	if err := Reencode(strings.NewReader(in), &packed, ReencodeOptions{}, PackJSON("j", "name", "city")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reencode(&packed, &out, ReencodeOptions{}, ExplodeJSON("j", "name", "city"), DropColumns("j")); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != in {
+		t.Errorf("round trip %q, want %q", got, in)
+	}
+}
@@ -0,0 +1,101 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+)
+
+// DecodeKeyValues reads the remaining records of a two-column key,value
+// input into a map. A repeated key takes the value of its last record.
+func (d *Decoder) DecodeKeyValues() (map[string]string, error) {
+	m := make(map[string]string)
+	for {
+		key, value, err := d.nextKeyValue()
+		if err == io.EOF {
+			return m, nil
+		}
+		if err != nil {
+			return m, err
+		}
+		m[string(key)] = string(value)
+	}
+}
+
+// DecodeKeyValueStruct reads the remaining records of a two-column
+// key,value input into the struct pointed to by v. Keys are bound to
+// fields by name as DecodeStruct binds columns, and values are converted
+// in the same way. Unknown keys are ignored. Required fields without a key
+// make DecodeKeyValueStruct fail if MissingColumns is MissingError.
+func (d *Decoder) DecodeKeyValueStruct(v interface{}) error {
+	fields, err := structFieldsOf(v)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]structField, len(fields))
+	for _, f := range fields {
+		byName[d.keyName(f.name)] = f
+	}
+
+	sv := reflect.ValueOf(v).Elem()
+	seen := make(map[int]bool, len(fields))
+	for {
+		key, value, err := d.nextKeyValue()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		f, ok := byName[d.keyName(string(key))]
+		if !ok {
+			continue
+		}
+		fv := sv.Field(f.index)
+		if err := d.convertValue(fv, value, d.NumberFormat); err != nil {
+			d.column = 0
+			return d.error(&ConversionError{
+				Field: 1,
+				Value: string(value),
+				Type:  fv.Type(),
+				Err:   err,
+			})
+		}
+		seen[f.index] = true
+	}
+
+	var missing []string
+	for _, f := range fields {
+		if !seen[f.index] && f.required && d.MissingColumns == MissingError {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return &MismatchError{Report: &MismatchReport{Missing: missing}}
+	}
+	return nil
+}
+
+// keyName returns name as normalized by NormalizeHeader.
+func (d *Decoder) keyName(name string) string {
+	if d.NormalizeHeader != nil {
+		return d.NormalizeHeader(name)
+	}
+	return name
+}
+
+// nextKeyValue reads the next record as a key and a value. A record with a
+// single field has an empty value. It returns io.EOF at the end of the
+// input.
+func (d *Decoder) nextKeyValue() (key, value []byte, err error) {
+	if err := d.next(); err != nil {
+		return nil, nil, err
+	}
+	switch len(d.fieldIndexes) {
+	case 1:
+		return d.field(0), nil, nil
+	case 2:
+		return d.field(0), d.field(1), nil
+	}
+	d.err = ErrFieldCount
+	return nil, nil, d.fieldCountError(2, len(d.fieldIndexes))
+}
@@ -0,0 +1,87 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const deviceConfig = `# exported by unit 7
+hostname,edge-07
+Port,8080
+enabled,true
+timeout,1.5s
+location,"Oslo, NO"
+hostname,edge-07b
+empty
+`
+
+func TestDecodeKeyValues(t *testing.T) {
+	dec := NewDecoderDialect(strings.NewReader(deviceConfig), Dialect{Comment: '#'})
+	m, err := dec.DecodeKeyValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"hostname": "edge-07b",
+		"Port":     "8080",
+		"enabled":  "true",
+		"timeout":  "1.5s",
+		"location": "Oslo, NO",
+		"empty":    "",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("map %q, want %q", m, want)
+	}
+}
+
+type durationValue struct{ time.Duration }
+
+func (d *durationValue) UnmarshalText(b []byte) (err error) {
+	d.Duration, err = time.ParseDuration(string(b))
+	return err
+}
+
+func TestDecodeKeyValueStruct(t *testing.T) {
+	var cfg struct {
+		Hostname string
+		Port     int
+		Enabled  bool
+		Timeout  durationValue
+		Location string
+		Missing  string `csv:"missing,optional"`
+	}
+	dec := NewDecoderDialect(strings.NewReader(deviceConfig), Dialect{Comment: '#'})
+	dec.NormalizeHeader = LowerHeader
+	dec.MissingColumns = MissingError
+	if err := dec.DecodeKeyValueStruct(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Hostname != "edge-07b" || cfg.Port != 8080 || !cfg.Enabled ||
+		cfg.Timeout.Duration != 1500*time.Millisecond || cfg.Location != "Oslo, NO" {
+		t.Errorf("decoded %+v", cfg)
+	}
+}
+
+func TestDecodeKeyValueErrors(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,1\nb,2,3\n"))
+	if _, err := dec.DecodeKeyValues(); !errors.Is(err, ErrFieldCount) {
+		t.Errorf("three fields: error %v, want ErrFieldCount", err)
+	}
+
+	var v struct{ A, B int }
+	dec = NewDecoder(strings.NewReader("A,1\n"))
+	dec.MissingColumns = MissingError
+	var merr *MismatchError
+	if err := dec.DecodeKeyValueStruct(&v); !errors.As(err, &merr) || !reflect.DeepEqual(merr.Report.Missing, []string{"B"}) {
+		t.Errorf("missing key: error %v", err)
+	}
+
+	dec = NewDecoder(strings.NewReader("A,x\n"))
+	var cerr *ConversionError
+	if err := dec.DecodeKeyValueStruct(&v); !errors.As(err, &cerr) || cerr.Value != "x" {
+		t.Errorf("bad value: error %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// largeInput returns a stream whose second record has a quoted field of
+// about size bytes, with embedded quotes and newlines.
+func largeInput(size int) (string, string) {
+	field := strings.Repeat("lorem \"ipsum\"\ndolor, ", size/21)
+	quoted := `"` + strings.Replace(field, `"`, `""`, -1) + `"`
+	return "id,text,n\n1," + quoted + ",x\r\n2,short,y\n", field
+}
+
+func TestLargeField(t *testing.T) {
+	in, field := largeInput(3 << 20)
+	for _, threshold := range []int{0, 1 << 16} {
+		for _, size := range []int{512, 4096} {
+			dec := NewDecoder(strings.NewReader(in))
+			dec.SetBufferSize(size)
+			dec.LargeFieldThreshold = threshold
+			if _, err := dec.ReadHeader(); err != nil {
+				t.Fatal(err)
+			}
+			record, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("threshold %d, buffer %d: %v", threshold, size, err)
+			}
+			if len(record) != 3 || record[0] != "1" || record[1] != field || record[2] != "x" {
+				t.Fatalf("threshold %d, buffer %d: wrong record (%d fields)", threshold, size, len(record))
+			}
+			if threshold > 0 && cap(dec.buf) > 4*threshold {
+				t.Errorf("threshold %d, buffer %d: input buffer grew to %d", threshold, size, cap(dec.buf))
+			}
+			if line, col := dec.FieldPos(2); line != 2+strings.Count(field, "\n") || col != 10 {
+				t.Errorf("threshold %d, buffer %d: FieldPos(2) = %d, %d", threshold, size, line, col)
+			}
+			record, err = dec.Decode()
+			if err != nil || strings.Join(record, ",") != "2,short,y" {
+				t.Errorf("threshold %d, buffer %d: next record %q, %v", threshold, size, record, err)
+			}
+			if got := dec.LineEndings(); got != (LineEndings{LF: 2, CRLF: 1}) {
+				t.Errorf("threshold %d, buffer %d: line endings %+v", threshold, size, got)
+			}
+		}
+	}
+}
+
+func TestLargeFieldWriteTo(t *testing.T) {
+	in, _ := largeInput(1 << 20)
+	dec := NewDecoder(strings.NewReader(in))
+	dec.LargeFieldThreshold = 4096
+	var out bytes.Buffer
+	if _, err := dec.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != in {
+		t.Error("WriteTo did not copy the large record")
+	}
+}
+
+func TestLargeFieldErrors(t *testing.T) {
+	big := strings.Repeat("x", 100000)
+	dec := NewDecoder(strings.NewReader("a,b\n" + big + "\xff" + big + "\n"))
+	dec.FieldsPerRecord = -1
+	dec.InvalidUTF8 = UTF8Error
+	dec.LargeFieldThreshold = 1024
+	dec.Decode()
+	_, err := dec.Decode()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Err != ErrInvalidUTF8 || perr.Offset != 4 {
+		t.Errorf("error %v, want ErrInvalidUTF8 at offset 4", err)
+	}
+
+	dec = NewDecoder(strings.NewReader("a,b\n" + big + "\n"))
+	dec.LargeFieldThreshold = 1024
+	dec.Decode()
+	_, err = dec.Decode()
+	if !errors.As(err, &perr) || perr.Err != ErrFieldCount || perr.Offset != 4 {
+		t.Errorf("error %v, want ErrFieldCount at offset 4", err)
+	}
+	if _, err := dec.Decode(); err == io.EOF {
+		t.Error("decoding went on after ErrFieldCount")
+	}
+}
+
+func TestLargeFieldUTF8(t *testing.T) {
+	field := strings.Repeat("é", 3000)
+	for threshold := 16; threshold <= 100; threshold++ {
+		dec := NewDecoder(strings.NewReader("a,\"" + field + "\"\n"))
+		dec.FieldsPerRecord = -1
+		dec.InvalidUTF8 = UTF8Error
+		dec.LargeFieldThreshold = threshold
+		fields, err := dec.Decode()
+		if err != nil || len(fields) != 2 || fields[1] != field {
+			t.Fatalf("threshold %d: error %v", threshold, err)
+		}
+	}
+
+	in := "a,\"" + field + "\xff\"\n"
+	dec := NewDecoder(strings.NewReader(in))
+	dec.InvalidUTF8 = UTF8Error
+	dec.LargeFieldThreshold = 17
+	_, err := dec.Decode()
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Err != ErrInvalidUTF8 || perr.Offset != int64(strings.IndexByte(in, 0xff)) {
+		t.Errorf("error %v, want ErrInvalidUTF8 at offset %d", err, strings.IndexByte(in, 0xff))
+	}
+}
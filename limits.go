@@ -0,0 +1,56 @@
+package csv
+
+import (
+	"errors"
+	"io"
+)
+
+// These errors are returned in a ParseError for records exceeding the
+// decoder's MaxRecordSize and MaxFields.
+var (
+	ErrRecordTooLarge = errors.New("record too large")
+	ErrTooManyFields  = errors.New("too many fields")
+)
+
+// WithLimits bounds the size in bytes and the number of fields of the
+// records the decoder reads, as set by MaxRecordSize and MaxFields.
+func WithLimits(recordSize, fields int) Option {
+	return func(d *Decoder) {
+		d.MaxRecordSize = recordSize
+		d.MaxFields = fields
+	}
+}
+
+// limitError returns the error for the current record exceeding a limit
+// at buf offset i, which stops the decoder.
+func (d *Decoder) limitError(err error, i int) error {
+	d.err = err
+	return &ParseError{
+		Record: d.records + 1,
+		Line:   d.recordLine,
+		Column: d.column,
+		Field:  len(d.fieldIndexes) - 1,
+		Offset: d.offset + int64(i),
+		Err:    err,
+	}
+}
+
+// ScanOnly reads r as a decoder set up with opts does and returns the
+// first error, or nil if the input is valid. Fields are unquoted but never
+// converted to strings, so it is cheaper than decoding for validating
+// input and suited to fuzzing. Memory use is bounded when opts set limits
+// with WithLimits.
+func ScanOnly(r io.Reader, opts ...Option) error {
+	d := newDecoder(r, opts)
+	for {
+		if err := d.next(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := d.checkFieldCount(len(d.fieldIndexes)); err != nil {
+			return err
+		}
+	}
+}
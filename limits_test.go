@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		in         string
+		recordSize int
+		fields     int
+		err        error
+		record     int64
+	}{
+		{"a,b,c\n1,2,3\n", 6, 3, nil, 0},
+		{"a,b,c\n1,2,3\n", 5, 0, ErrRecordTooLarge, 1},
+		{"a,b\n\"" + strings.Repeat("x", 10000) + "\"\n", 100, 0, ErrRecordTooLarge, 2},
+		{"a,b\n" + strings.Repeat("x", 10000), 100, 0, ErrRecordTooLarge, 2},
+		{"a,b,c\n1,2,3,4\n", 0, 3, ErrTooManyFields, 2},
+		{strings.Repeat(",", 10000) + "\n", 0, 100, ErrTooManyFields, 1},
+	}
+	for _, tt := range tests {
+		for _, size := range []int{1, 4096} {
+			err := ScanOnly(strings.NewReader(tt.in), WithLimits(tt.recordSize, tt.fields), WithFieldsPerRecord(-1), WithBufferSize(size))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%.20q, size %d: error %v, want %v", tt.in, size, err, tt.err)
+				continue
+			}
+			var perr *ParseError
+			if tt.err != nil && (!errors.As(err, &perr) || perr.Record != tt.record) {
+				t.Errorf("%.20q, size %d: error %v, want record %d", tt.in, size, err, tt.record)
+			}
+		}
+	}
+}
+
+func TestLimitsBoundMemory(t *testing.T) {
+	// an endless line must fail without being buffered whole
+	r := io.MultiReader(strings.NewReader("\""), neverEnding('x'))
+	dec := NewDecoder(r)
+	dec.MaxRecordSize = 1 << 20
+	if _, err := dec.Decode(); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("error %v, want %v", err, ErrRecordTooLarge)
+	}
+	if cap(dec.buf) > 4<<20 {
+		t.Errorf("buffer grew to %d bytes", cap(dec.buf))
+	}
+}
+
+type neverEnding byte
+
+func (b neverEnding) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(b)
+	}
+	return len(p), nil
+}
+
+func TestScanOnly(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		err error
+	}{
+		{"a,b\n\"1\",\"x\n\"\"y\"\n", nil},
+		{"a,b\n1\n", ErrFieldCount},
+		{"a,b\n1,\"x\"y\n", ErrQuote},
+		{"a\x00b,c\n", nil},
+		{"", nil},
+	} {
+		if err := ScanOnly(strings.NewReader(tt.in)); !errors.Is(err, tt.err) {
+			t.Errorf("ScanOnly(%q) = %v, want %v", tt.in, err, tt.err)
+		}
+	}
+}
+
+func FuzzScanOnly(f *testing.F) {
+	for _, in := range []string{"a,b\n1,2\n", "\"a\"\"b\",c\r\n", "a,\"b\n", "\x00,\"\r\n", "# x\na\n\n"} {
+		f.Add([]byte(in))
+	}
+	f.Fuzz(func(t *testing.T, input []byte) {
+		for _, dialect := range []Dialect{{}, {LazyQuotes: true, Comment: '#', TrimLeadingSpace: true}, Excel, {Whitespace: true, Escapes: true}, {CRLineEndings: true, InlineComments: true, Comment: '#'}} {
+			ScanOnly(strings.NewReader(string(input)), WithDialect(dialect), WithLimits(1<<16, 1<<10))
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+)
+
+// LineEndings counts the line terminators of a stream by kind.
+type LineEndings struct {
+	LF   int64 // records terminated by \n
+	CRLF int64 // records terminated by \r\n
+	// CR counts records terminated by a lone \r and, unless the dialect
+	// sets CRLineEndings, the lone \r read as part of unquoted fields.
+	CR int64
+}
+
+// Mixed reports whether more than one kind of line terminator was read.
+func (l LineEndings) Mixed() bool {
+	kinds := 0
+	for _, n := range []int64{l.LF, l.CRLF, l.CR} {
+		if n > 0 {
+			kinds++
+		}
+	}
+	return kinds > 1
+}
+
+// LineEndings returns the line terminators read so far, including those
+// of the header and of skipped records. Blank lines are not counted.
+func (d *Decoder) LineEndings() LineEndings {
+	return d.lineEndings
+}
+
+// LineEnding returns the line terminator of the record most recently
+// read: "\n", "\r\n", "\r" if the dialect sets CRLineEndings, or "" if
+// the record ends the input without one.
+func (d *Decoder) LineEnding() string {
+	switch {
+	case bytes.HasSuffix(d.raw, []byte("\r\n")):
+		return "\r\n"
+	case bytes.HasSuffix(d.raw, []byte("\n")):
+		return "\n"
+	case d.scan.CRLineEndings && bytes.HasSuffix(d.raw, []byte("\r")):
+		return "\r"
+	}
+	return ""
+}
+
+// countLineEnding adds the terminator of the current record to the
+// decoder's LineEndings.
+func (d *Decoder) countLineEnding() {
+	switch {
+	case bytes.HasSuffix(d.raw, []byte("\r\n")):
+		d.lineEndings.CRLF++
+	case bytes.HasSuffix(d.raw, []byte("\n")):
+		d.lineEndings.LF++
+	case bytes.HasSuffix(d.raw, []byte("\r")):
+		d.lineEndings.CR++
+	}
+}
+
+// normalizeNewlines rewrites the line breaks inside field to \n, or to
+// \r\n if crlf is true.
+func normalizeNewlines(field string, crlf bool) string {
+	if !strings.ContainsAny(field, "\r\n") {
+		return field
+	}
+	nl := "\n"
+	if crlf {
+		nl = "\r\n"
+	}
+	var b strings.Builder
+	b.Grow(len(field))
+	for i := 0; i < len(field); i++ {
+		switch c := field[i]; c {
+		case '\r':
+			if i+1 < len(field) && field[i+1] == '\n' {
+				i++
+			}
+			b.WriteString(nl)
+		case '\n':
+			b.WriteString(nl)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,153 @@
+package csv
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineEndings(t *testing.T) {
+	tests := []struct {
+		in      string
+		dialect Dialect
+		want    [][]string
+		endings LineEndings
+	}{
+		{"a,b\nc,d\n", Dialect{}, [][]string{{"a", "b"}, {"c", "d"}}, LineEndings{LF: 2}},
+		{"a,b\r\nc,d\n", Dialect{}, [][]string{{"a", "b"}, {"c", "d"}}, LineEndings{LF: 1, CRLF: 1}},
+		{"\"a\"\r\n\"b\"\r\n", Dialect{}, [][]string{{"a"}, {"b"}}, LineEndings{CRLF: 2}},
+		{"a,\r\nb,\"\"\r\n", Dialect{}, [][]string{{"a", ""}, {"b", ""}}, LineEndings{CRLF: 2}},
+		{"a\rb\n", Dialect{}, [][]string{{"a\rb"}}, LineEndings{LF: 1, CR: 1}},
+		{"a,b\rc,d\r", Dialect{CRLineEndings: true}, [][]string{{"a", "b"}, {"c", "d"}}, LineEndings{CR: 2}},
+		{"a,\rb\r\n\"c\"\rd\n", Dialect{CRLineEndings: true}, [][]string{{"a", ""}, {"b"}, {"c"}, {"d"}}, LineEndings{LF: 1, CRLF: 1, CR: 2}},
+		{"\"a\"\rb\n", Dialect{LazyQuotes: true}, [][]string{{"a\"\rb\n"}}, LineEndings{LF: 1}},
+	}
+	for _, tt := range tests {
+		for _, size := range []int{1, 4096} {
+			dec := NewDecoderDialect(strings.NewReader(tt.in), tt.dialect)
+			dec.FieldsPerRecord = -1
+			dec.SetBufferSize(size)
+			var got [][]string
+			for {
+				record, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%q, buffer %d: %v", tt.in, size, err)
+				}
+				got = append(got, record)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q, buffer %d: got %q, want %q", tt.in, size, got, tt.want)
+			}
+			if got := dec.LineEndings(); got != tt.endings {
+				t.Errorf("%q, buffer %d: line endings %+v, want %+v", tt.in, size, got, tt.endings)
+			}
+		}
+	}
+}
+
+func TestQuoteCarriageReturn(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("\"a\"\rb\n"))
+	_, err := dec.Decode()
+	if perr, ok := err.(*ParseError); !ok || perr.Err != ErrQuote {
+		t.Errorf("error %v, want ErrQuote", err)
+	}
+}
+
+func TestCRLineNumbers(t *testing.T) {
+	dec := NewDecoderDialect(strings.NewReader("a\rb\rc,\"d\"\"\"\r"), Dialect{CRLineEndings: true})
+	dec.FieldsPerRecord = -1
+	dec.RequireFinalNewline = true
+	for want := 1; dec.More(); want++ {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+		if line, _ := dec.FieldPos(0); line != want {
+			t.Errorf("record %d on line %d", want, line)
+		}
+	}
+}
+
+func TestLineEndingsMixed(t *testing.T) {
+	tests := []struct {
+		l    LineEndings
+		want bool
+	}{
+		{LineEndings{}, false},
+		{LineEndings{CRLF: 3}, false},
+		{LineEndings{LF: 1, CRLF: 3}, true},
+		{LineEndings{LF: 1, CR: 1}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.l.Mixed(); got != tt.want {
+			t.Errorf("%+v: Mixed() = %v, want %v", tt.l, got, tt.want)
+		}
+	}
+}
+
+func TestReencodeNormalizeNewlines(t *testing.T) {
+	in := "name,note\r\nann,\"one\r\ntwo\"\nbob,\"x\ry\"\r\n"
+	var out bytes.Buffer
+	var endings LineEndings
+	opts := ReencodeOptions{UseCRLF: true, NormalizeNewlines: true, LineEndings: &endings}
+	if err := Reencode(strings.NewReader(in), &out, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "name,note\r\nann,\"one\r\ntwo\"\r\nbob,\"x\r\ny\"\r\n"; got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+	if want := (LineEndings{LF: 1, CRLF: 2}); endings != want {
+		t.Errorf("line endings %+v, want %+v", endings, want)
+	}
+
+	out.Reset()
+	opts = ReencodeOptions{NormalizeNewlines: true}
+	if err := Reencode(strings.NewReader(in), &out, opts); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "name,note\nann,\"one\ntwo\"\nbob,\"x\ny\"\n"; got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		dialect Dialect
+		want    []string
+	}{
+		{"a\r\nb\nc", Dialect{}, []string{"\r\n", "\n", ""}},
+		{"\"a\nb\"\r\nc\r", Dialect{}, []string{"\r\n", ""}},
+		{"a\rb\r\nc\r", Dialect{CRLineEndings: true}, []string{"\r", "\r\n", "\r"}},
+	} {
+		dec := NewDecoderDialect(strings.NewReader(tt.in), tt.dialect)
+		var got []string
+		for {
+			if _, err := dec.Decode(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, dec.LineEnding())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: line endings %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReencodePreserveLineEndings(t *testing.T) {
+	in := "name,city\r\nann,paris\nbob,\"new\r\nyork\"\r\ncid,rome"
+	var out bytes.Buffer
+	opts := ReencodeOptions{UseCRLF: true, PreserveLineEndings: true}
+	if err := Reencode(strings.NewReader(in), &out, opts, upper{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), strings.ToUpper(in); got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,278 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// A LintKind classifies the issues found by Lint.
+type LintKind int
+
+const (
+	LintRaggedRow   LintKind = iota // record with a different number of fields than the first
+	LintBadQuote                    // quote misplaced or not doubled
+	LintLineEndings                 // records terminated by both \n and \r\n
+	LintEncoding                    // invalid UTF-8 or a byte order mark
+	LintDialect                     // the delimiter does not look right
+)
+
+var lintKindNames = [...]string{"ragged row", "bad quote", "line endings", "encoding", "dialect"}
+
+func (k LintKind) String() string {
+	if k < 0 || int(k) >= len(lintKindNames) {
+		return "LintKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return lintKindNames[k]
+}
+
+// A LintIssue is a structural problem found by Lint.
+type LintIssue struct {
+	Kind    LintKind
+	Record  int64 // 1-based number of the record, or 0 for the whole input
+	Line    int   // line the record starts on
+	Message string
+}
+
+func (i LintIssue) String() string {
+	if i.Record == 0 {
+		return i.Kind.String() + ": " + i.Message
+	}
+	return fmt.Sprintf("record %d, line %d: %s: %s", i.Record, i.Line, i.Kind, i.Message)
+}
+
+// A LintReport is the result of Lint.
+type LintReport struct {
+	Records   int64 // records read, including broken ones
+	MinFields int
+	MaxFields int
+
+	// LF and CRLF count the records terminated by \n and \r\n.
+	LF, CRLF int64
+
+	Issues []LintIssue
+	// Omitted is the number of issues beyond LintOptions.MaxIssues.
+	Omitted int
+}
+
+// OK reports whether no issue was found.
+func (r *LintReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// LintOptions configures Lint.
+type LintOptions struct {
+	// Dialect describes the expected syntax of the input.
+	Dialect Dialect
+
+	// MaxIssues is the number of issues reported in full. It defaults
+	// to 100.
+	MaxIssues int
+}
+
+// lintDelimiters are the delimiters suggested when every record has a
+// single field.
+const lintDelimiters = ",;\t|"
+
+// Lint reads r as a pre-flight check and reports its structural issues,
+// such as ragged rows, bad quotes, mixed line endings and invalid UTF-8,
+// without materializing its records. A record with a bad quote is skipped
+// to the end of its line and checking continues, and a quoted field left
+// open at the end of the input is reported as ErrQuote. The returned error
+// is only that of reading r.
+func Lint(r io.Reader, opts LintOptions) (*LintReport, error) {
+	max := opts.MaxIssues
+	if max <= 0 {
+		max = 100
+	}
+	dec := NewDecoderDialect(r, opts.Dialect)
+	dec.FieldsPerRecord = -1
+	l := &linter{
+		dec:    dec,
+		report: &LintReport{},
+		max:    max,
+		single: make(map[byte]int),
+	}
+
+	for {
+		err := dec.next()
+		if err == io.EOF {
+			break
+		}
+		var perr *ParseError
+		if errors.As(err, &perr) && (perr.Err == ErrBareQuote || perr.Err == ErrQuote) {
+			// the error may be lines after the start of a multi-line record
+			l.issue(LintBadQuote, perr.Record, dec.recordLine, "%v at column %d", perr.Err, perr.Column)
+			l.report.Records++
+			dec.resync(perr.Offset)
+			continue
+		}
+		if err != nil {
+			return l.report, err
+		}
+		l.record()
+	}
+	l.finish()
+	return l.report, nil
+}
+
+type linter struct {
+	dec    *Decoder
+	report *LintReport
+	max    int
+
+	// field count of the first record, and the delimiter candidates found
+	// the same number of times in every record so far while all records
+	// have a single field
+	width  int
+	single map[byte]int
+}
+
+func (l *linter) issue(kind LintKind, record int64, line int, format string, args ...interface{}) {
+	if len(l.report.Issues) >= l.max {
+		l.report.Omitted++
+		return
+	}
+	l.report.Issues = append(l.report.Issues, LintIssue{
+		Kind:    kind,
+		Record:  record,
+		Line:    line,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// record checks the current record of the decoder.
+func (l *linter) record() {
+	d, rep := l.dec, l.report
+	n := len(d.fieldIndexes)
+	rep.Records++
+	if l.width == 0 {
+		// the first record checked, which may follow broken ones
+		l.width, rep.MinFields, rep.MaxFields = n, n, n
+		if bytes.HasPrefix(d.raw, []byte(bom)) {
+			l.issue(LintEncoding, d.records, d.recordLine, "byte order mark at start of input")
+		}
+		for i := 0; i < len(lintDelimiters); i++ {
+			if c := lintDelimiters[i]; c != d.scan.Delimiter {
+				l.single[c] = bytes.Count(d.raw, []byte{c})
+			}
+		}
+	}
+	if n < rep.MinFields {
+		rep.MinFields = n
+	}
+	if n > rep.MaxFields {
+		rep.MaxFields = n
+	}
+	if n != l.width {
+		l.issue(LintRaggedRow, d.records, d.recordLine, "%d fields, want %d", n, l.width)
+	}
+	for c, count := range l.single {
+		if n != 1 || count == 0 || bytes.Count(d.raw, []byte{c}) != count {
+			delete(l.single, c)
+		}
+	}
+
+	if !d.scan.LazyQuotes && !d.scan.Escapes {
+		// only a record ending the input can hold an open quote
+		if i := openQuote(d.raw); i >= 0 {
+			line := d.recordLine + bytes.Count(d.raw[:i], []byte{'\n'})
+			l.issue(LintBadQuote, d.records, d.recordLine, "%v: quote opened on line %d is not closed", ErrQuote, line)
+		}
+	}
+
+	if !utf8.Valid(d.raw) {
+		l.issue(LintEncoding, d.records, d.recordLine, "invalid UTF-8 at byte %d", invalidUTF8(d.raw))
+	}
+
+	switch {
+	case bytes.HasSuffix(d.raw, []byte("\r\n")):
+		if rep.CRLF == 0 && rep.LF > 0 {
+			l.issue(LintLineEndings, d.records, d.recordLine, "ends with \\r\\n, earlier records with \\n")
+		}
+		rep.CRLF++
+	case bytes.HasSuffix(d.raw, []byte("\n")):
+		if rep.LF == 0 && rep.CRLF > 0 {
+			l.issue(LintLineEndings, d.records, d.recordLine, "ends with \\n, earlier records with \\r\\n")
+		}
+		rep.LF++
+	}
+}
+
+// openQuote returns the index of the quote opening a quoted field that raw
+// ends in, or -1 if raw does not end in a quoted field.
+func openQuote(raw []byte) int {
+	quoted := false
+	start, end := -1, -2
+	for i, c := range raw {
+		if c != '"' {
+			continue
+		}
+		if !quoted && i != end+1 {
+			// not the second quote of a doubled one
+			start = i
+		}
+		quoted = !quoted
+		if !quoted {
+			end = i
+		}
+	}
+	if !quoted {
+		return -1
+	}
+	return start
+}
+
+// finish reports the issues of the whole input.
+func (l *linter) finish() {
+	if l.report.Records < 2 || l.report.MaxFields != 1 {
+		return
+	}
+	for i := 0; i < len(lintDelimiters); i++ {
+		if c := lintDelimiters[i]; l.single[c] > 0 {
+			l.issue(LintDialect, 0, 0, "every record has a single field and %d %q; the delimiter may be %q", l.single[c], c, c)
+		}
+	}
+}
+
+// resync recovers from the syntax error at input offset off by skipping
+// the rest of its line, which is counted as a record. It returns the bytes
+// of the record, from its start to the end of that line, cut short after
+// MaxRecordSize bytes.
+func (d *Decoder) resync(off int64) []byte {
+	d.err = nil
+	d.records++
+	var raw []byte
+	i := int(off - d.offset)
+	for {
+		if j := bytes.IndexByte(d.buf[i:], '\n'); j >= 0 {
+			raw = append(raw, d.buf[d.scanp:i+j+1]...)
+			d.scanp = i + j + 1
+			d.line++
+			d.lineStart = d.offset + int64(d.scanp)
+			return raw
+		}
+		raw = append(raw, d.buf[d.scanp:]...)
+		if d.MaxRecordSize > 0 && len(raw) > d.MaxRecordSize {
+			raw = raw[:d.MaxRecordSize] // keep memory bounded
+		}
+		d.scanp = len(d.buf)
+		err := d.refill()
+		i = 0
+		if err != nil {
+			if j := bytes.IndexByte(d.buf, '\n'); j >= 0 {
+				raw = append(raw, d.buf[:j+1]...)
+				d.scanp = j + 1
+				d.line++
+				d.lineStart = d.offset + int64(d.scanp)
+			} else {
+				raw = append(raw, d.buf...)
+				d.scanp = len(d.buf)
+			}
+			return raw
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	in := "\ufeffid,name,qty\r\n" +
+		"1,ann,3\r\n" +
+		"2,b\"ob,4\r\n" +
+		"3,cid\n" +
+		"4,\"dan\"x,5\n" +
+		"5,\xffe,6\n"
+	rep, err := Lint(strings.NewReader(in), LintOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"record 1, line 1: encoding: byte order mark at start of input",
+		"record 3, line 3: bad quote: bare \" in non-quoted-field at column 3",
+		"record 4, line 4: ragged row: 2 fields, want 3",
+		"record 4, line 4: line endings: ends with \\n, earlier records with \\r\\n",
+		"record 5, line 5: bad quote: extraneous \" in field at column 6",
+		"record 6, line 6: encoding: invalid UTF-8 at byte 2",
+	}
+	var got []string
+	for _, issue := range rep.Issues {
+		got = append(got, issue.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if rep.Records != 6 || rep.MinFields != 2 || rep.MaxFields != 3 || rep.CRLF != 2 || rep.LF != 2 {
+		t.Errorf("report %+v", rep)
+	}
+}
+
+func TestLintDialect(t *testing.T) {
+	rep, err := Lint(strings.NewReader("a;b;c\n1;2;3\n4;5;6\n"), LintOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Issues) != 1 || rep.Issues[0].Kind != LintDialect || !strings.Contains(rep.Issues[0].Message, `';'`) {
+		t.Errorf("issues %v", rep.Issues)
+	}
+
+	rep, _ = Lint(strings.NewReader("a;b;c\n1;2;3\n"), LintOptions{Dialect: Dialect{Delimiter: ';'}})
+	if !rep.OK() {
+		t.Errorf("issues %v", rep.Issues)
+	}
+}
+
+func TestLintMaxIssues(t *testing.T) {
+	rep, _ := Lint(strings.NewReader("a,b\n1\n2\n3\n"), LintOptions{MaxIssues: 2})
+	if len(rep.Issues) != 2 || rep.Omitted != 1 {
+		t.Errorf("%d issues, %d omitted", len(rep.Issues), rep.Omitted)
+	}
+}
+
+func TestLintQuote(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want []string
+	}{
+		{
+			in: "x,y\na,\"b\n\nc\"d\ne,f\n",
+			want: []string{
+				"record 2, line 2: bad quote: extraneous \" in field at column 7",
+			},
+		},
+		{
+			in: "a,\"b\"c\nd,e\n",
+			want: []string{
+				"record 1, line 1: bad quote: extraneous \" in field at column 4",
+			},
+		},
+		{
+			in: "x,y\na,\"b\"\"\nc\n",
+			want: []string{
+				"record 2, line 2: bad quote: extraneous \" in field: quote opened on line 2 is not closed",
+			},
+		},
+		{
+			in: "x,y,z\na,\"b\nc\",\"d\ne\n",
+			want: []string{
+				"record 2, line 2: bad quote: extraneous \" in field: quote opened on line 3 is not closed",
+			},
+		},
+		{
+			in:   "x,\"y\"\"\"\na,\"\"\n",
+			want: nil,
+		},
+	} {
+		rep, err := Lint(strings.NewReader(tt.in), LintOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, issue := range rep.Issues {
+			got = append(got, issue.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%q: issues\n%s\nwant\n%s", tt.in, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
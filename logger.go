@@ -0,0 +1,41 @@
+package csv
+
+import "io"
+
+// A Logger logs the events of a decoder, such as skipped records and
+// errors, as a message followed by alternating keys and values. It is
+// implemented by *slog.Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithLogger sets the Logger of the decoder.
+func WithLogger(l Logger) Option {
+	return func(d *Decoder) { d.Logger = l }
+}
+
+// debug logs msg at debug level with the position of the current record
+// and args.
+func (d *Decoder) debug(msg string, args ...interface{}) {
+	if d.Logger != nil {
+		d.Logger.Debug(msg, append([]interface{}{"record", d.records, "line", d.recordLine}, args...)...)
+	}
+}
+
+// logResult logs err unless it was logged already: errors the decoder
+// recovers from are warnings, and the error stopping it is an error.
+func (d *Decoder) logResult(err error) {
+	if err == nil || d.reported(err) {
+		return
+	}
+	switch {
+	case err == io.EOF:
+		d.Logger.Debug("csv: end of input", "records", d.records)
+	case d.err != nil:
+		d.Logger.Error("csv: decoding stopped", "record", d.records, "error", err)
+	default:
+		d.Logger.Warn("csv: record rejected", "record", d.records, "error", err)
+	}
+}
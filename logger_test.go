@@ -0,0 +1,38 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+)
+
+// testLogger records the messages logged at each level.
+type testLogger struct{ lines []string }
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.lines = append(l.lines, "DEBUG "+msg) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.lines = append(l.lines, "WARN "+msg) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.lines = append(l.lines, "ERROR "+msg) }
+
+func TestLogger(t *testing.T) {
+	sum := func(s string) string { return s + "," + CRC32Checksum([]byte(s)) + "\n" }
+	in := sum("a,b") + sum("skip,1") + sum("c") + "d,e,00000000\n" + sum("f,g,h")
+
+	l := &testLogger{}
+	dec := NewDecoder(strings.NewReader(in))
+	dec.Checksum = CRC32Checksum
+	dec.FieldsPerRecord = 2
+	dec.PadShortRows = true
+	dec.Logger = l
+	dec.Filter(func(r Record) bool { return r.Fields[0] != "skip" })
+	for i := 0; i < 6; i++ {
+		dec.Decode()
+	}
+	want := []string{
+		"DEBUG csv: record skipped",
+		"DEBUG csv: short record padded",
+		"WARN csv: record rejected",
+		"ERROR csv: decoding stopped",
+	}
+	if strings.Join(l.lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("logged\n%s\nwant\n%s", strings.Join(l.lines, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,101 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// A Manifest summarizes a stream for auditing: a digest of its raw bytes
+// and counts of its records and fields.
+type Manifest struct {
+	SHA256  string // hex-encoded SHA-256 digest of the raw bytes
+	Bytes   int64  // number of raw bytes
+	Records int64  // number of records, including any header
+	Fields  int64  // total number of fields
+
+	// MinFields and MaxFields are the smallest and largest number of
+	// fields in a record.
+	MinFields, MaxFields int
+}
+
+// A manifestCounter accumulates a Manifest. Raw bytes are fed through its
+// Write method.
+type manifestCounter struct {
+	h       hash.Hash
+	bytes   int64
+	records int64
+	fields  int64
+	min     int
+	max     int
+}
+
+func newManifestCounter() *manifestCounter {
+	return &manifestCounter{h: sha256.New()}
+}
+
+func (m *manifestCounter) Write(p []byte) (int, error) {
+	m.h.Write(p)
+	m.bytes += int64(len(p))
+	return len(p), nil
+}
+
+// record counts a record of n fields.
+func (m *manifestCounter) record(n int) {
+	if m.records == 0 || n < m.min {
+		m.min = n
+	}
+	if n > m.max {
+		m.max = n
+	}
+	m.records++
+	m.fields += int64(n)
+}
+
+func (m *manifestCounter) manifest() Manifest {
+	return Manifest{
+		SHA256:    hex.EncodeToString(m.h.Sum(nil)),
+		Bytes:     m.bytes,
+		Records:   m.records,
+		Fields:    m.fields,
+		MinFields: m.min,
+		MaxFields: m.max,
+	}
+}
+
+// EnableManifest makes the decoder compute a Manifest of its input as it
+// reads. It must be called before the first record is decoded.
+//
+// Records rejected by Filter or Sample are counted.
+func (d *Decoder) EnableManifest() {
+	d.manifest = newManifestCounter()
+	d.r = io.TeeReader(d.r, d.manifest)
+}
+
+// Manifest returns the manifest of the input read so far, or the zero
+// Manifest if EnableManifest was not called. The digest covers every byte
+// read from the input, which after the last record is the whole stream.
+func (d *Decoder) Manifest() Manifest {
+	if d.manifest == nil {
+		return Manifest{}
+	}
+	return d.manifest.manifest()
+}
+
+// EnableManifest makes the encoder compute a Manifest of its output as it
+// writes. It must be called before the first record is encoded.
+func (e *Encoder) EnableManifest() {
+	e.manifest = newManifestCounter()
+	e.setWriter()
+}
+
+// Manifest returns the manifest of the output written so far, or the zero
+// Manifest if EnableManifest was not called. The digest and byte count
+// cover flushed output only.
+func (e *Encoder) Manifest() Manifest {
+	if e.manifest == nil {
+		return Manifest{}
+	}
+	return e.manifest.manifest()
+}
@@ -0,0 +1,51 @@
+package csv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	in := "a,b,c\n1,2\n\"x\ny\",z,w,v\n"
+	sum := sha256.Sum256([]byte(in))
+	want := Manifest{
+		SHA256:    hex.EncodeToString(sum[:]),
+		Bytes:     int64(len(in)),
+		Records:   3,
+		Fields:    9,
+		MinFields: 2,
+		MaxFields: 4,
+	}
+
+	dec := NewDecoder(strings.NewReader(in))
+	dec.FieldsPerRecord = -1
+	dec.EnableManifest()
+	var records [][]string
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if got := dec.Manifest(); got != want {
+		t.Errorf("decoder manifest %+v, want %+v", got, want)
+	}
+
+	var out bytes.Buffer
+	enc := NewEncoder(&out)
+	enc.EnableManifest()
+	for _, record := range records {
+		enc.Encode(record)
+	}
+	enc.Flush()
+	if out.String() != in {
+		t.Fatalf("out=%q want %q", out.String(), in)
+	}
+	if got := enc.Manifest(); got != want {
+		t.Errorf("encoder manifest %+v, want %+v", got, want)
+	}
+}
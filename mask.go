@@ -0,0 +1,129 @@
+package csv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A Masker replaces a field with an anonymized value.
+type Masker func(field string) (string, error)
+
+// HashMask replaces fields with the hex SHA-256 of salt followed by the
+// field, so equal values stay equal without revealing them.
+func HashMask(salt string) Masker {
+	return func(field string) (string, error) {
+		sum := sha256.Sum256([]byte(salt + field))
+		return hex.EncodeToString(sum[:]), nil
+	}
+}
+
+// RedactMask replaces fields with token. Empty fields are kept empty.
+func RedactMask(token string) Masker {
+	return func(field string) (string, error) {
+		if field == "" {
+			return "", nil
+		}
+		return token, nil
+	}
+}
+
+// PartialMask replaces all but the last keep characters of fields with
+// mask, so with keep 4 "4111111111111234" becomes "************1234".
+func PartialMask(keep int, mask rune) Masker {
+	return func(field string) (string, error) {
+		n := utf8.RuneCountInString(field) - keep
+		if n <= 0 {
+			return field, nil
+		}
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			b.WriteRune(mask)
+		}
+		for ; n > 0; n-- {
+			_, size := utf8.DecodeRuneInString(field)
+			field = field[size:]
+		}
+		b.WriteString(field)
+		return b.String(), nil
+	}
+}
+
+// A DatePrecision is the part of a date kept by DateMask.
+type DatePrecision int
+
+const (
+	KeepYear DatePrecision = iota
+	KeepMonth
+	KeepDay
+)
+
+// DateMask truncates dates written with layout to the first day of their
+// year or month, or to midnight of their day, and writes them back with
+// layout. Empty fields are kept empty; other fields must be dates.
+func DateMask(layout string, keep DatePrecision) Masker {
+	return func(field string) (string, error) {
+		if field == "" {
+			return "", nil
+		}
+		t, err := time.Parse(layout, field)
+		if err != nil {
+			return "", err
+		}
+		month, day := t.Month(), t.Day()
+		switch keep {
+		case KeepYear:
+			month, day = time.January, 1
+		case KeepMonth:
+			day = 1
+		}
+		return time.Date(t.Year(), month, day, 0, 0, 0, 0, t.Location()).Format(layout), nil
+	}
+}
+
+// Mask returns a transform applying the masker of each column, by name.
+func Mask(maskers map[string]Masker) Transform {
+	return &maskTransform{maskers: maskers}
+}
+
+type maskTransform struct {
+	maskers map[string]Masker
+	names   []string
+	cols    []int
+	fns     []Masker
+}
+
+func (t *maskTransform) Header(header []string) ([]string, error) {
+	names := make([]string, 0, len(t.maskers))
+	for name := range t.maskers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cols, err := columnIndexes(header, names)
+	if err != nil {
+		return nil, err
+	}
+	t.names, t.cols = names, cols
+	t.fns = make([]Masker, len(names))
+	for i, name := range names {
+		t.fns[i] = t.maskers[name]
+	}
+	return header, nil
+}
+
+func (t *maskTransform) Apply(r Record) ([]string, error) {
+	for i, col := range t.cols {
+		if col >= len(r.Fields) {
+			continue
+		}
+		v, err := t.fns[i](r.Fields[col])
+		if err != nil {
+			return nil, &TransformError{Column: t.names[i], Value: r.Fields[col], Err: err}
+		}
+		r.Fields[col] = v
+	}
+	return r.Fields, nil
+}
@@ -0,0 +1,77 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMaskers(t *testing.T) {
+	tests := []struct {
+		m        Masker
+		in, want string
+	}{
+		{RedactMask("REDACTED"), "Ann Smith", "REDACTED"},
+		{RedactMask("REDACTED"), "", ""},
+		{PartialMask(4, '*'), "4111111111111234", "************1234"},
+		{PartialMask(4, '*'), "123", "123"},
+		{PartialMask(2, '•'), "Zoë", "•oë"},
+		{DateMask("2006-01-02", KeepYear), "1984-07-23", "1984-01-01"},
+		{DateMask("2006-01-02", KeepMonth), "1984-07-23", "1984-07-01"},
+		{DateMask("2006-01-02T15:04", KeepDay), "1984-07-23T10:30", "1984-07-23T00:00"},
+		{HashMask("s"), "a", "4cf6829aa93728e8f3c97df913fb1bfa95fe5810e2933a05943f8312a98d9cf2"},
+	}
+	for i, tt := range tests {
+		got, err := tt.m(tt.in)
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+		} else if got != tt.want {
+			t.Errorf("#%d: mask(%q) = %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMaskTransform(t *testing.T) {
+	in := "id,email,card,born\n1,a@x.org,4111111111111234,1984-07-23\n2,a@x.org,,1990-12-01\n"
+	var out bytes.Buffer
+	err := Reencode(strings.NewReader(in), &out, ReencodeOptions{}, Mask(map[string]Masker{
+		"email": HashMask("pepper"),
+		"card":  PartialMask(4, '*'),
+		"born":  DateMask("2006-01-02", KeepYear),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if lines[0] != "id,email,card,born" {
+		t.Errorf("header %q", lines[0])
+	}
+	r1 := strings.Split(lines[1], ",")
+	r2 := strings.Split(lines[2], ",")
+	if r1[1] != r2[1] || r1[1] == "a@x.org" || len(r1[1]) != 64 {
+		t.Errorf("emails hashed to %q and %q", r1[1], r2[1])
+	}
+	if r1[2] != "************1234" || r2[2] != "" {
+		t.Errorf("cards masked to %q and %q", r1[2], r2[2])
+	}
+	if r1[3] != "1984-01-01" || r2[3] != "1990-01-01" {
+		t.Errorf("dates masked to %q and %q", r1[3], r2[3])
+	}
+}
+
+func TestMaskErrors(t *testing.T) {
+	var out bytes.Buffer
+	err := Reencode(strings.NewReader("a\n1\n"), &out, ReencodeOptions{}, Mask(map[string]Masker{"b": RedactMask("x")}))
+	if _, ok := err.(*MismatchError); !ok {
+		t.Errorf("unknown column: error %v, want MismatchError", err)
+	}
+
+	err = Reencode(strings.NewReader("d\n2020-01-01\nsoon\n"), &out, ReencodeOptions{}, Mask(map[string]Masker{"d": DateMask("2006-01-02", KeepDay)}))
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("bad date: error %v, want ParseError", err)
+	}
+	if terr, ok := perr.Err.(*TransformError); !ok || terr.Column != "d" || terr.Value != "soon" || perr.Record != 3 {
+		t.Errorf("bad date: error %v", err)
+	}
+}
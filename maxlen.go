@@ -0,0 +1,110 @@
+package csv
+
+import (
+	"errors"
+	"sort"
+	"unicode/utf8"
+)
+
+// ErrFieldTooLong is returned in a ParseError for a field longer than the
+// MaxLength of its column under LengthError.
+var ErrFieldTooLong = errors.New("field too long")
+
+// A LengthPolicy controls how fields longer than the MaxLength of their
+// column are handled.
+type LengthPolicy int
+
+const (
+	// LengthError makes Decode fail with ErrFieldTooLong.
+	LengthError LengthPolicy = iota
+	// LengthTruncate cuts the field to MaxLength characters.
+	LengthTruncate
+	// LengthTruncateFlag cuts the field like LengthTruncate and reports it
+	// in Truncated.
+	LengthTruncateFlag
+)
+
+// Truncated returns the indexes of the fields of the last record that
+// were truncated under LengthTruncateFlag. The slice is only valid until
+// the next record is read.
+func (d *Decoder) Truncated() []int {
+	return d.truncated
+}
+
+// schemaIndex returns the index of the field of the j'th schema column in
+// the current record, or -1.
+func (d *Decoder) schemaIndex(j int) int {
+	if d.header == nil {
+		return j
+	}
+	return d.columnIndex(d.Schema.Columns[j].Name)
+}
+
+// checkLengths applies the MaxLength of the schema columns to the current
+// record.
+func (d *Decoder) checkLengths() error {
+	d.truncated = d.truncated[:0]
+	if d.Schema == nil || d.inHeader {
+		return nil
+	}
+
+	var cuts map[int]int // field index to its new length in bytes
+	for j := range d.Schema.Columns {
+		col := &d.Schema.Columns[j]
+		max := col.MaxLength
+		if max <= 0 {
+			continue
+		}
+		i := d.schemaIndex(j)
+		if i < 0 || i >= len(d.fieldIndexes) {
+			continue
+		}
+		field := d.field(i)
+		if len(field) <= max || utf8.RuneCount(field) <= max {
+			continue
+		}
+		if col.LengthPolicy == LengthError {
+			d.column = 0 // report at start of record
+			d.err = ErrFieldTooLong
+			perr := d.error(d.err).(*ParseError)
+			perr.Field = i
+			return perr
+		}
+		n := 0
+		for r := 0; r < max; r++ {
+			_, size := utf8.DecodeRune(field[n:])
+			n += size
+		}
+		if cuts == nil {
+			cuts = make(map[int]int)
+		}
+		cuts[i] = n
+		if col.LengthPolicy == LengthTruncateFlag {
+			d.truncated = append(d.truncated, i)
+		}
+	}
+	if cuts != nil {
+		d.cutFields(cuts)
+		sort.Ints(d.truncated)
+	}
+	return nil
+}
+
+// cutFields shortens the fields of the current record to the lengths in
+// cuts, moving the following fields down in lineBuffer.
+func (d *Decoder) cutFields(cuts map[int]int) {
+	line := d.lineBuffer.Bytes()
+	w := 0
+	for i, start := range d.fieldIndexes {
+		end := len(line)
+		if i+1 < len(d.fieldIndexes) {
+			end = d.fieldIndexes[i+1]
+		}
+		if n, ok := cuts[i]; ok {
+			end = start + n
+		}
+		d.fieldIndexes[i] = w
+		w += copy(line[w:], line[start:end])
+	}
+	d.lineBuffer.Truncate(w)
+}
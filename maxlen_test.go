@@ -0,0 +1,67 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMaxLengthTruncate(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("code,name,note\nab,Zoë Smith,long note\nabc,Al,x\n"))
+	dec.Schema = &Schema{Columns: []Column{
+		{Name: "note", MaxLength: 4, LengthPolicy: LengthTruncate},
+		{Name: "name", MaxLength: 3, LengthPolicy: LengthTruncateFlag},
+	}}
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		record    []string
+		truncated []int
+	}{
+		{[]string{"ab", "Zoë", "long"}, []int{1}},
+		{[]string{"abc", "Al", "x"}, nil},
+	}
+	for i, w := range want {
+		record, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(record, w.record) {
+			t.Errorf("#%d: record %q, want %q", i, record, w.record)
+		}
+		if got := dec.Truncated(); len(got) != len(w.truncated) || (len(got) > 0 && !reflect.DeepEqual(got, w.truncated)) {
+			t.Errorf("#%d: truncated %v, want %v", i, got, w.truncated)
+		}
+	}
+}
+
+func TestMaxLengthError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("id,name\n1,Ann\n2,Bartholomew\n"))
+	dec.Schema = &Schema{Columns: []Column{{Name: "name", MaxLength: 5}}}
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	err := firstError(dec)
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrFieldTooLong) {
+		t.Fatalf("error %v, want ErrFieldTooLong", err)
+	}
+	if perr.Record != 3 || perr.Line != 3 || perr.Field != 1 {
+		t.Errorf("error at record %d, line %d, field %d", perr.Record, perr.Line, perr.Field)
+	}
+}
+
+func TestMaxLengthByPosition(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("abcdef,ghijkl\n"))
+	dec.Schema = &Schema{Columns: []Column{{MaxLength: 2, LengthPolicy: LengthTruncate}}}
+	record, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ab", "ghijkl"}; !reflect.DeepEqual(record, want) {
+		t.Errorf("record %q, want %q", record, want)
+	}
+}
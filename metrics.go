@@ -0,0 +1,91 @@
+package csv
+
+import (
+	"errors"
+	"expvar"
+	"io"
+	"time"
+)
+
+// Metrics receives measurements from a Decoder and from Reencode. Its
+// methods are called synchronously and must be fast. Adapters for other
+// systems, such as Prometheus counters and histograms, implement it by
+// forwarding each call.
+type Metrics interface {
+	// RecordDecoded is called for every record returned by the decoder.
+	RecordDecoded()
+	// BytesRead is called with the number of bytes of every read from the
+	// input.
+	BytesRead(n int)
+	// Error is called once for every error returned by the decoder other
+	// than io.EOF, and for every error of a Reencode transform.
+	Error(err error)
+	// Stage is called with the time spent in a stage for one record: the
+	// decoder reports "decode", and Reencode names each transform by its
+	// type, such as "*csv.Replacer".
+	Stage(name string, elapsed time.Duration)
+}
+
+// observing reports whether the decoder reports to Metrics, Tracer or
+// Logger.
+func (d *Decoder) observing() bool {
+	return d.Metrics != nil || d.Tracer != nil || d.Logger != nil
+}
+
+// reported reports whether err was already reported by observe: either
+// returned again, or returned first wrapped in a ParseError.
+func (d *Decoder) reported(err error) bool {
+	return d.observedErr != nil && (err == d.observedErr || errors.Is(d.observedErr, err))
+}
+
+// observe reports the outcome of reading a record started at start.
+func (d *Decoder) observe(start time.Time, err error) {
+	if d.Tracer != nil {
+		d.traceResult(err)
+	}
+	if d.Logger != nil {
+		d.logResult(err)
+	}
+	if d.Metrics != nil {
+		d.measure(start, err)
+	}
+	if err != nil && !d.reported(err) {
+		d.observedErr = err
+	}
+}
+
+// measure reports the outcome of reading a record to Metrics.
+func (d *Decoder) measure(start time.Time, err error) {
+	switch {
+	case err == nil:
+		d.Metrics.Stage("decode", time.Since(start))
+		d.Metrics.RecordDecoded()
+	case err != io.EOF && !d.reported(err):
+		d.Metrics.Error(err)
+	}
+}
+
+// ExpvarMetrics is a Metrics publishing its counters as an expvar.Map:
+// "records", "bytes" and "errors", and for each stage "<stage>.count" and
+// "<stage>.nanos".
+type ExpvarMetrics struct {
+	m *expvar.Map
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics published under name. Like
+// expvar.Publish, it panics if name is already in use.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{m: expvar.NewMap(name)}
+}
+
+// Map returns the map holding the counters.
+func (m *ExpvarMetrics) Map() *expvar.Map { return m.m }
+
+func (m *ExpvarMetrics) RecordDecoded()  { m.m.Add("records", 1) }
+func (m *ExpvarMetrics) BytesRead(n int) { m.m.Add("bytes", int64(n)) }
+func (m *ExpvarMetrics) Error(err error) { m.m.Add("errors", 1) }
+
+func (m *ExpvarMetrics) Stage(name string, elapsed time.Duration) {
+	m.m.Add(name+".count", 1)
+	m.m.Add(name+".nanos", int64(elapsed))
+}
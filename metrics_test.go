@@ -0,0 +1,77 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countMetrics is a Metrics counting its calls.
+type countMetrics struct {
+	records, bytes, errors int
+	stages                 map[string]int
+}
+
+func (m *countMetrics) RecordDecoded()  { m.records++ }
+func (m *countMetrics) BytesRead(n int) { m.bytes += n }
+func (m *countMetrics) Error(err error) { m.errors++ }
+
+func (m *countMetrics) Stage(name string, elapsed time.Duration) {
+	if m.stages == nil {
+		m.stages = make(map[string]int)
+	}
+	m.stages[name]++
+}
+
+func TestMetrics(t *testing.T) {
+	in := "a,b\n1,2\n3,4\n5,x\"y\n"
+	m := &countMetrics{}
+	dec := NewDecoder(strings.NewReader(in))
+	dec.Metrics = m
+	for i := 0; i < 5; i++ {
+		dec.Decode()
+	}
+	if m.records != 3 || m.bytes != len(in) || m.errors != 1 || m.stages["decode"] != 3 {
+		t.Errorf("metrics %+v", m)
+	}
+}
+
+func TestReencodeMetrics(t *testing.T) {
+	in := "name,note\nann,x1\nbob,y\n"
+	m := &countMetrics{}
+	err := Reencode(strings.NewReader(in), new(bytes.Buffer), ReencodeOptions{Metrics: m},
+		Replace(regexp.MustCompile(`\d`), "#", "note"),
+		Mask(map[string]Masker{"name": func(s string) (string, error) {
+			if s == "bob" {
+				return "", errors.New("no bob")
+			}
+			return s, nil
+		}}))
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if m.records != 3 || m.errors != 1 || m.stages["*csv.Replacer"] != 2 || len(m.stages) != 3 {
+		t.Errorf("metrics %+v", m)
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	m := NewExpvarMetrics("csv-test-metrics")
+	dec := NewDecoder(strings.NewReader("a\nb\n"))
+	dec.Metrics = m
+	for dec.More() {
+		dec.Decode()
+	}
+	if got := m.Map().Get("records").String(); got != "2" {
+		t.Errorf("records %s, want 2", got)
+	}
+	if got := m.Map().Get("bytes").String(); got != "4" {
+		t.Errorf("bytes %s, want 4", got)
+	}
+	if m.Map().Get("decode.nanos") == nil {
+		t.Error("no decode stage")
+	}
+}
@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A HeaderNormalizer rewrites a column name read by ReadHeader, so that
+// names from messy sources can be matched reliably.
+type HeaderNormalizer func(name string) string
+
+// NormalizeHeaders returns a normalizer applying each of fns in order.
+func NormalizeHeaders(fns ...HeaderNormalizer) HeaderNormalizer {
+	return func(name string) string {
+		for _, fn := range fns {
+			name = fn(name)
+		}
+		return name
+	}
+}
+
+// SetNormalizeHeader sets NormalizeHeader and drops the decode plans built
+// with the previous normalizer, which assigning the field directly cannot
+// tell apart from closures of the same function literal.
+func (d *Decoder) SetNormalizeHeader(fn HeaderNormalizer) {
+	d.NormalizeHeader = fn
+	d.plans = nil
+}
+
+// TrimHeader removes a byte order mark and the white space around a name.
+func TrimHeader(name string) string {
+	return strings.TrimSpace(strings.TrimPrefix(name, bom))
+}
+
+// LowerHeader converts a name to lower case.
+func LowerHeader(name string) string {
+	return strings.ToLower(name)
+}
+
+// SnakeCaseHeader converts a name to lower case words separated by
+// underscores: "Unit Price", "unitPrice" and "unit-price" all become
+// "unit_price", and "HTTPStatus" becomes "http_status".
+func SnakeCaseHeader(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	sep := false
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			sep = b.Len() > 0
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && b.Len() > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sep = true
+			}
+		}
+		if sep {
+			b.WriteByte('_')
+			sep = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// DeburrHeader replaces Latin letters with diacritics by their base
+// letters, so "Café Größe" becomes "Cafe Grosse".
+func DeburrHeader(name string) string {
+	return deburrReplacer.Replace(name)
+}
+
+// deburrReplacer maps the letters of the Latin-1 Supplement and Latin
+// Extended-A blocks to their base letters. Other runes are kept.
+var deburrReplacer = func() *strings.Replacer {
+	const (
+		from = "ÀÁÂÃÄÅàáâãäåĀāĂăĄąÇçĆćĈĉĊċČčĎďĐđÈÉÊËèéêëĒēĔĕĖėĘęĚěĜĝĞğĠġĢģĤĥĦħÌÍÎÏìíîïĨĩĪīĬĭĮįİıĴĵĶķĹĺĻļĽľĿŀŁłÑñŃńŅņŇňÒÓÔÕÖØòóôõöøŌōŎŏŐőŔŕŖŗŘřŚśŜŝŞşŠšŢţŤťŦŧÙÚÛÜùúûüŨũŪūŬŭŮůŰűŲųŴŵÝýÿŶŷŸŹźŻżŽž"
+		to   = "AAAAAAaaaaaaAaAaAaCcCcCcCcCcDdDdEEEEeeeeEeEeEeEeEeGgGgGgGgHhHhIIIIiiiiIiIiIiIiIiJjKkLlLlLlLlLlNnNnNnNnOOOOOOooooooOoOoOoRrRrRrSsSsSsSsTtTtTtUUUUuuuuUuUuUuUuUuUuWwYyyYyYZzZzZz"
+	)
+	var pairs []string
+	base := []rune(to)
+	for i, r := range []rune(from) {
+		pairs = append(pairs, string(r), string(base[i]))
+	}
+	pairs = append(pairs, "ß", "ss", "Æ", "AE", "æ", "ae", "Œ", "OE", "œ", "oe", "Þ", "Th", "þ", "th", "Ð", "D", "ð", "d")
+	return strings.NewReplacer(pairs...)
+}()
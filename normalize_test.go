@@ -0,0 +1,54 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHeaderNormalizers(t *testing.T) {
+	tests := []struct {
+		fn       HeaderNormalizer
+		in, want string
+	}{
+		{TrimHeader, "\ufeff id ", "id"},
+		{LowerHeader, "Unit Price", "unit price"},
+		{SnakeCaseHeader, "Unit Price", "unit_price"},
+		{SnakeCaseHeader, "unitPrice", "unit_price"},
+		{SnakeCaseHeader, " unit-price (EUR) ", "unit_price_eur"},
+		{SnakeCaseHeader, "HTTPStatus2xx", "http_status2xx"},
+		{SnakeCaseHeader, "already_snake", "already_snake"},
+		{DeburrHeader, "Café Größe", "Cafe Grosse"},
+		{DeburrHeader, "Łódź 東京", "Lodz 東京"},
+		{NormalizeHeaders(TrimHeader, DeburrHeader, SnakeCaseHeader), "\ufeffNúmero de Teléfono", "numero_de_telefono"},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReadHeaderNormalized(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("\ufeffUser ID, Full Name ,user_id\n1,Ann,2\n"))
+	dec.NormalizeHeader = NormalizeHeaders(TrimHeader, SnakeCaseHeader)
+	dec.DuplicateHeaders = DuplicateSuffix
+	header, err := dec.ReadHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"user_id", "full_name", "user_id_2"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header %q, want %q", header, want)
+	}
+
+	var v struct {
+		UserID   int
+		FullName string
+	}
+	if err := dec.DecodeStruct(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.UserID != 1 || v.FullName != "Ann" {
+		t.Errorf("decoded %+v", v)
+	}
+}
@@ -0,0 +1,477 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRowGroupSize is the number of records of a Parquet row group
+// when ParquetOptions.RowGroupSize is 0.
+const DefaultRowGroupSize = 10000
+
+// ParquetOptions configures ToParquet.
+type ParquetOptions struct {
+	// Dialect describes the syntax of the input.
+	Dialect Dialect
+
+	// RowGroupSize is the number of records of each row group. The
+	// records of a row group are held in memory until it is written.
+	RowGroupSize int
+
+	// Compression is the compression of the pages: NoCompression or Gzip.
+	Compression Compression
+}
+
+// ToParquet reads records from r, whose first record is a header, and
+// writes them to w as a Parquet file.
+//
+// The columns of the file are the columns of schema, matched to the header
+// by name, or all the columns of the header if schema has none. The type
+// of a column follows Column.Type: BOOLEAN, INT64, DOUBLE, INT64 holding
+// UTC milliseconds for TypeTime, and UTF-8 strings otherwise. Every column
+// is optional and empty fields are written as nulls. Fields are sanitized
+// and numbers read in the NumberFormat of their column, as by DecodeValues.
+//
+// Records are written in row groups of opts.RowGroupSize records, so that
+// memory use is bounded by the size of one row group.
+func ToParquet(r io.Reader, w io.Writer, schema Schema, opts ParquetOptions) error {
+	codec, ok := parquetCodecs[opts.Compression]
+	if !ok {
+		return ErrUnsupportedCompression
+	}
+	size := opts.RowGroupSize
+	if size <= 0 {
+		size = DefaultRowGroupSize
+	}
+
+	dec := NewDecoderDialect(r, opts.Dialect)
+	header, err := dec.ReadHeader()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	columns := schema.Columns
+	if len(columns) == 0 {
+		columns = make([]Column, len(header))
+		for i, name := range header {
+			columns[i].Name = name
+		}
+	}
+	cols := make([]*parquetColumn, len(columns))
+	for i := range columns {
+		c := &columns[i]
+		index := -1
+		if header != nil {
+			if index = dec.columnIndex(c.Name); index < 0 {
+				return fmt.Errorf("csv: parquet: no column %q", c.Name)
+			}
+		}
+		cols[i] = newParquetColumn(c, index)
+	}
+
+	pw := &parquetWriter{w: w, codec: codec}
+	pw.write([]byte(parquetMagic))
+	var rows int
+	for dec.More() {
+		err := dec.next()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = dec.checkFieldCount(len(dec.fieldIndexes))
+		}
+		if err != nil {
+			return err
+		}
+		for _, c := range cols {
+			if err := c.add(dec); err != nil {
+				return err
+			}
+		}
+		if rows++; rows == size {
+			pw.writeRowGroup(cols, rows)
+			rows = 0
+		}
+	}
+	if rows > 0 {
+		pw.writeRowGroup(cols, rows)
+	}
+	pw.writeFooter(cols)
+	return pw.err
+}
+
+const parquetMagic = "PAR1"
+
+// Parquet physical and converted types, encodings and codecs, as numbered
+// by the Parquet format.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+var parquetCodecs = map[Compression]int32{NoCompression: 0, Gzip: 2}
+
+// A parquetColumn buffers the values of one column for the current row
+// group, encoded as they are written in a data page.
+type parquetColumn struct {
+	col   *Column
+	index int // column of the input, or -1 if there is none
+	typ   int32
+	conv  int32 // converted type, or -1 if there is none
+
+	rows   int
+	defs   []byte // definition levels, one bit per record
+	values bytes.Buffer
+	bools  int // number of values of a BOOLEAN column
+
+	chunks []parquetChunk
+}
+
+// A parquetChunk is the metadata of a column chunk written to the file.
+type parquetChunk struct {
+	offset       int64
+	values       int
+	uncompressed int64
+	compressed   int64
+}
+
+func newParquetColumn(col *Column, index int) *parquetColumn {
+	c := &parquetColumn{col: col, index: index, conv: -1}
+	switch col.Type {
+	case TypeBool:
+		c.typ = parquetBoolean
+	case TypeInt:
+		c.typ = parquetInt64
+	case TypeFloat:
+		c.typ = parquetDouble
+	case TypeTime:
+		c.typ = parquetInt64
+		c.conv = parquetTimestampMillis
+	default:
+		c.typ = parquetByteArray
+		c.conv = parquetUTF8
+	}
+	return c
+}
+
+var (
+	parquetInt64Type  = reflect.TypeOf(int64(0))
+	parquetDoubleType = reflect.TypeOf(float64(0))
+	parquetBoolType   = reflect.TypeOf(false)
+	parquetTimeType   = reflect.TypeOf(time.Time{})
+)
+
+// add appends the field of c of the current record of d.
+func (c *parquetColumn) add(d *Decoder) error {
+	var field []byte
+	if c.index >= 0 && c.index < len(d.fieldIndexes) {
+		field = sanitize(d.field(c.index), c.col)
+	}
+	if c.rows%8 == 0 {
+		c.defs = append(c.defs, 0)
+	}
+	c.rows++
+	if len(field) == 0 {
+		return nil
+	}
+	c.defs[len(c.defs)-1] |= 1 << ((c.rows - 1) % 8)
+
+	format := d.NumberFormat
+	if c.col.NumberFormat != nil {
+		format = *c.col.NumberFormat
+	}
+	var b [8]byte
+	var err error
+	var t reflect.Type
+	switch c.col.Type {
+	case TypeBool:
+		var v bool
+		if v, err = strconv.ParseBool(strings.TrimSpace(string(field))); err == nil {
+			if c.bools%8 == 0 {
+				c.values.WriteByte(0)
+			}
+			if v {
+				c.values.Bytes()[c.values.Len()-1] |= 1 << (c.bools % 8)
+			}
+			c.bools++
+		}
+		t = parquetBoolType
+	case TypeInt:
+		var v int64
+		if v, err = strconv.ParseInt(number(field, format), 10, 64); err == nil {
+			binary.LittleEndian.PutUint64(b[:], uint64(v))
+			c.values.Write(b[:])
+		}
+		t = parquetInt64Type
+	case TypeFloat:
+		var v float64
+		if v, err = strconv.ParseFloat(number(field, format), 64); err == nil {
+			binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+			c.values.Write(b[:])
+		}
+		t = parquetDoubleType
+	case TypeTime:
+		var v time.Time
+		if v, err = parseTime(string(bytes.TrimSpace(field)), "", nil); err == nil {
+			binary.LittleEndian.PutUint64(b[:], uint64(v.UnixMilli()))
+			c.values.Write(b[:])
+		}
+		t = parquetTimeType
+	default:
+		binary.LittleEndian.PutUint32(b[:], uint32(len(field)))
+		c.values.Write(b[:4])
+		c.values.Write(field)
+	}
+	if err != nil {
+		d.column = 0
+		return d.error(&ConversionError{
+			Field: c.index,
+			Value: string(field),
+			Type:  t,
+			Err:   err,
+		})
+	}
+	return nil
+}
+
+// reset empties c for the next row group.
+func (c *parquetColumn) reset() {
+	c.rows = 0
+	c.defs = c.defs[:0]
+	c.values.Reset()
+	c.bools = 0
+}
+
+// A parquetWriter writes a Parquet file, keeping the first error.
+type parquetWriter struct {
+	w      io.Writer
+	codec  int32
+	offset int64
+	groups []int // number of records of the row groups written
+	err    error
+}
+
+func (pw *parquetWriter) write(b []byte) {
+	if pw.err != nil {
+		return
+	}
+	var n int
+	n, pw.err = pw.w.Write(b)
+	pw.offset += int64(n)
+}
+
+// writeRowGroup writes the buffered values of cols as a row group of rows
+// records, with one data page per column.
+func (pw *parquetWriter) writeRowGroup(cols []*parquetColumn, rows int) {
+	for _, c := range cols {
+		// definition levels as a single bit-packed run of width 1
+		levels := binary.AppendUvarint(nil, uint64(len(c.defs))<<1|1)
+		levels = append(levels, c.defs...)
+		page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+		page = append(page, levels...)
+		page = append(page, c.values.Bytes()...)
+		data, err := pw.compress(page)
+		if err != nil && pw.err == nil {
+			pw.err = err
+		}
+
+		var h thriftWriter
+		h.i32(1, 0) // DATA_PAGE
+		h.i32(2, int32(len(page)))
+		h.i32(3, int32(len(data)))
+		h.begin(5)
+		h.i32(1, int32(rows))
+		h.i32(2, parquetPlain)
+		h.i32(3, parquetRLE)
+		h.i32(4, parquetRLE)
+		h.end()
+		h.end()
+
+		c.chunks = append(c.chunks, parquetChunk{
+			offset:       pw.offset,
+			values:       rows,
+			uncompressed: int64(len(h.b) + len(page)),
+			compressed:   int64(len(h.b) + len(data)),
+		})
+		pw.write(h.b)
+		pw.write(data)
+		c.reset()
+	}
+	pw.groups = append(pw.groups, rows)
+}
+
+// compress compresses a page with the codec of the file.
+func (pw *parquetWriter) compress(page []byte) ([]byte, error) {
+	if pw.codec == 0 {
+		return page, nil
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(page); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFooter writes the file metadata describing cols and the row
+// groups written, followed by its length and the closing magic number.
+func (pw *parquetWriter) writeFooter(cols []*parquetColumn) {
+	var m thriftWriter
+	var total int64
+	for _, rows := range pw.groups {
+		total += int64(rows)
+	}
+	m.i32(1, 1) // version
+	m.list(2, thriftStruct, len(cols)+1)
+	m.push()
+	m.binary(4, "schema")
+	m.i32(5, int32(len(cols)))
+	m.end()
+	for _, c := range cols {
+		m.push()
+		m.i32(1, c.typ)
+		m.i32(3, 1) // OPTIONAL
+		m.binary(4, c.col.Name)
+		if c.conv >= 0 {
+			m.i32(6, c.conv)
+		}
+		m.end()
+	}
+	m.i64(3, total)
+	m.list(4, thriftStruct, len(pw.groups))
+	for g, rows := range pw.groups {
+		m.push()
+		m.list(1, thriftStruct, len(cols))
+		var size int64
+		for _, c := range cols {
+			chunk := c.chunks[g]
+			size += chunk.uncompressed
+			m.push()
+			m.i64(2, chunk.offset)
+			m.begin(3)
+			m.i32(1, c.typ)
+			m.list(2, thriftI32, 2)
+			m.elemI32(parquetPlain)
+			m.elemI32(parquetRLE)
+			m.list(3, thriftBinary, 1)
+			m.elemBinary(c.col.Name)
+			m.i32(4, pw.codec)
+			m.i64(5, int64(chunk.values))
+			m.i64(6, chunk.uncompressed)
+			m.i64(7, chunk.compressed)
+			m.i64(9, chunk.offset)
+			m.end()
+			m.end()
+		}
+		m.i64(2, size)
+		m.i64(3, int64(rows))
+		m.end()
+	}
+	m.end()
+
+	pw.write(m.b)
+	pw.write(binary.LittleEndian.AppendUint32(nil, uint32(len(m.b))))
+	pw.write([]byte(parquetMagic))
+}
+
+// Types of the Thrift compact protocol.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// A thriftWriter encodes structs in the Thrift compact protocol used by
+// the Parquet metadata. Fields are written in increasing order of id.
+type thriftWriter struct {
+	b     []byte
+	last  int16   // id of the last field of the current struct
+	outer []int16 // last of the enclosing structs
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.b = append(w.b, byte(delta)<<4|typ)
+	} else {
+		w.b = append(w.b, typ)
+		w.b = binary.AppendVarint(w.b, int64(id))
+	}
+	w.last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.elemI32(v)
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.b = binary.AppendVarint(w.b, v)
+}
+
+func (w *thriftWriter) binary(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.elemBinary(s)
+}
+
+// list starts a list field of n elements of type typ, which are written
+// next with the elem methods, or between push and end for structs.
+func (w *thriftWriter) list(id int16, typ byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.b = append(w.b, byte(n)<<4|typ)
+	} else {
+		w.b = append(w.b, 0xf0|typ)
+		w.b = binary.AppendUvarint(w.b, uint64(n))
+	}
+}
+
+func (w *thriftWriter) elemI32(v int32) {
+	w.b = binary.AppendVarint(w.b, int64(v))
+}
+
+func (w *thriftWriter) elemBinary(s string) {
+	w.b = binary.AppendUvarint(w.b, uint64(len(s)))
+	w.b = append(w.b, s...)
+}
+
+// begin starts a struct field, ended by end.
+func (w *thriftWriter) begin(id int16) {
+	w.field(id, thriftStruct)
+	w.push()
+}
+
+// push starts a struct, ended by end.
+func (w *thriftWriter) push() {
+	w.outer = append(w.outer, w.last)
+	w.last = 0
+}
+
+// end ends the current struct.
+func (w *thriftWriter) end() {
+	w.b = append(w.b, 0)
+	if n := len(w.outer); n > 0 {
+		w.last = w.outer[n-1]
+		w.outer = w.outer[:n-1]
+	}
+}
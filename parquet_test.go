@@ -0,0 +1,214 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToParquet(t *testing.T) {
+	in := "id,name,score,ok,at\n" +
+		"1,ann,1.5,true,2024-01-02\n" +
+		"2,,,false,\n" +
+		"3,cy,\"2,5\",,2024-01-02T03:04:05Z\n"
+	comma := NumberFormat{Decimal: ','}
+	schema := Schema{Columns: []Column{
+		{Name: "name"},
+		{Name: "id", Type: TypeInt},
+		{Name: "score", Type: TypeFloat, NumberFormat: &comma},
+		{Name: "ok", Type: TypeBool},
+		{Name: "at", Type: TypeTime},
+	}}
+	for _, c := range []Compression{NoCompression, Gzip} {
+		var out bytes.Buffer
+		err := ToParquet(strings.NewReader(in), &out, schema, ParquetOptions{RowGroupSize: 2, Compression: c})
+		if err != nil {
+			t.Fatalf("%v: %v", c, err)
+		}
+		names, groups, rows := readParquet(t, out.Bytes())
+		if want := []string{"name", "id", "score", "ok", "at"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("%v: columns %q, want %q", c, names, want)
+		}
+		if groups != 2 {
+			t.Errorf("%v: %d row groups, want 2", c, groups)
+		}
+		day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		want := [][]interface{}{
+			{"ann", int64(1), 1.5, true, day.UnixMilli()},
+			{nil, int64(2), nil, false, nil},
+			{"cy", int64(3), 2.5, nil, day.Add(3*time.Hour + 4*time.Minute + 5*time.Second).UnixMilli()},
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("%v: rows %v, want %v", c, rows, want)
+		}
+	}
+}
+
+func TestToParquetHeader(t *testing.T) {
+	var out bytes.Buffer
+	if err := ToParquet(strings.NewReader("a;b\nx;1\n"), &out, Schema{}, ParquetOptions{Dialect: Dialect{Delimiter: ';'}}); err != nil {
+		t.Fatal(err)
+	}
+	names, _, rows := readParquet(t, out.Bytes())
+	if want := []string{"a", "b"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("columns %q, want %q", names, want)
+	}
+	if want := [][]interface{}{{"x", "1"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows %v, want %v", rows, want)
+	}
+}
+
+func TestToParquetErrors(t *testing.T) {
+	err := ToParquet(strings.NewReader("a\n1\n"), ioutil.Discard, Schema{Columns: []Column{{Name: "b"}}}, ParquetOptions{})
+	if err == nil {
+		t.Error("missing column accepted")
+	}
+	err = ToParquet(strings.NewReader("a\nx\n"), ioutil.Discard, Schema{Columns: []Column{{Name: "a", Type: TypeInt}}}, ParquetOptions{})
+	var ce *ConversionError
+	if !errors.As(err, &ce) || ce.Value != "x" {
+		t.Errorf("invalid integer: error %v, want a ConversionError", err)
+	}
+	err = ToParquet(strings.NewReader("a\n"), ioutil.Discard, Schema{}, ParquetOptions{Compression: Compression(42)})
+	if err != ErrUnsupportedCompression {
+		t.Errorf("Compression(42): error %v, want ErrUnsupportedCompression", err)
+	}
+}
+
+// readParquet decodes a file written by ToParquet, returning its column
+// names, number of row groups and records, with nil for nulls.
+func readParquet(t *testing.T, b []byte) (names []string, groups int, rows [][]interface{}) {
+	t.Helper()
+	n := len(b)
+	if n < 12 || string(b[:4]) != parquetMagic || string(b[n-4:]) != parquetMagic {
+		t.Fatal("missing magic number")
+	}
+	size := int(binary.LittleEndian.Uint32(b[n-8:]))
+	meta := (&thriftReader{b[n-8-size : n-8]}).structure()
+
+	schema := meta[2].([]interface{})
+	types := make([]int64, len(schema)-1)
+	for i, e := range schema[1:] {
+		names = append(names, e.(map[int16]interface{})[4].(string))
+		types[i] = e.(map[int16]interface{})[1].(int64)
+	}
+	for _, g := range meta[4].([]interface{}) {
+		g := g.(map[int16]interface{})
+		start := len(rows)
+		for i := int64(0); i < g[3].(int64); i++ {
+			rows = append(rows, make([]interface{}, len(names)))
+		}
+		for col, chunk := range g[1].([]interface{}) {
+			md := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			r := &thriftReader{b[md[9].(int64):]}
+			h := r.structure()
+			page := r.b[:h[3].(int64)]
+			if md[4].(int64) == 2 {
+				zr, err := gzip.NewReader(bytes.NewReader(page))
+				if err != nil {
+					t.Fatal(err)
+				}
+				page, _ = ioutil.ReadAll(zr)
+			}
+			levels := page[4 : 4+binary.LittleEndian.Uint32(page)]
+			values := page[4+len(levels):]
+			levels = levels[1:] // the header of the single bit-packed run
+			var bit int
+			for i := range rows[start:] {
+				if levels[i/8]&(1<<(i%8)) == 0 {
+					continue
+				}
+				var v interface{}
+				switch types[col] {
+				case parquetBoolean:
+					v = values[bit/8]&(1<<(bit%8)) != 0
+					bit++
+				case parquetInt64:
+					v = int64(binary.LittleEndian.Uint64(values))
+					values = values[8:]
+				case parquetDouble:
+					v = math.Float64frombits(binary.LittleEndian.Uint64(values))
+					values = values[8:]
+				case parquetByteArray:
+					l := binary.LittleEndian.Uint32(values)
+					v = string(values[4 : 4+l])
+					values = values[4+l:]
+				}
+				rows[start+i][col] = v
+			}
+		}
+		groups++
+	}
+	if meta[3].(int64) != int64(len(rows)) {
+		t.Errorf("file of %d rows, read %d", meta[3], len(rows))
+	}
+	return names, groups, rows
+}
+
+// A thriftReader decodes the Thrift compact protocol into maps of field
+// ids to values.
+type thriftReader struct {
+	b []byte
+}
+
+func (r *thriftReader) byte() byte {
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.b)
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	m := make(map[int16]interface{})
+	var last int16
+	for {
+		h := r.byte()
+		if h == 0 {
+			return m
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.varint())
+		}
+		m[id] = r.value(h & 0xf)
+		last = id
+	}
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n, k := binary.Uvarint(r.b)
+		s := string(r.b[k : k+int(n)])
+		r.b = r.b[k+int(n):]
+		return s
+	case thriftList:
+		h := r.byte()
+		n := int(h >> 4)
+		if n == 15 {
+			u, k := binary.Uvarint(r.b)
+			n, r.b = int(u), r.b[k:]
+		}
+		l := make([]interface{}, n)
+		for i := range l {
+			l[i] = r.value(h & 0xf)
+		}
+		return l
+	case thriftStruct:
+		return r.structure()
+	}
+	panic("unexpected thrift type")
+}
@@ -0,0 +1,176 @@
+package csv
+
+import (
+	"container/list"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// PartitionOptions configures Partition.
+type PartitionOptions struct {
+	// Dialect describes the syntax of the input.
+	Dialect Dialect
+
+	// MaxOpenFiles is the number of output files kept open at once; the
+	// least recently written one is closed when a new one is needed.
+	// It defaults to 64.
+	MaxOpenFiles int
+
+	// FileName returns the name, relative to the output directory, of the
+	// file holding the records with the given key. By default the key is
+	// path-escaped and given a ".csv" extension.
+	FileName func(key string) string
+}
+
+// Partition reads records from r, whose first record is a header, and
+// writes each record to a file in outDir chosen by the value of its keyCol
+// column. Every output file starts with the header. It returns the path of
+// the file written for each key.
+//
+// Output files are created, or truncated, when their key is first seen.
+func Partition(r io.Reader, keyCol, outDir string, opts PartitionOptions) (map[string]string, error) {
+	dec := NewDecoderDialect(r, opts.Dialect)
+	dec.FieldsPerRecord = -1
+	if !dec.More() {
+		return nil, nil
+	}
+	header, err := dec.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+	col := dec.columnIndex(keyCol)
+	if col < 0 {
+		return nil, errors.New("csv: partition column " + keyCol + " not in header")
+	}
+
+	p := &partitioner{
+		header:   header,
+		dir:      outDir,
+		opts:     opts,
+		paths:    make(map[string]string),
+		open:     make(map[string]*list.Element),
+		lru:      list.New(),
+		maxFiles: opts.MaxOpenFiles,
+	}
+	if p.maxFiles <= 0 {
+		p.maxFiles = 64
+	}
+	if p.opts.FileName == nil {
+		p.opts.FileName = partitionFileName
+	}
+
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			p.close()
+			return p.paths, err
+		}
+		enc, err := p.encoder(fieldAt(record, col))
+		if err != nil {
+			p.close()
+			return p.paths, err
+		}
+		if err := enc.Encode(record); err != nil {
+			p.close()
+			return p.paths, err
+		}
+	}
+	return p.paths, p.close()
+}
+
+// partitionFileName is the default PartitionOptions.FileName.
+func partitionFileName(key string) string {
+	if key == "" {
+		return "_empty.csv"
+	}
+	return url.PathEscape(key) + ".csv"
+}
+
+// A partitioner manages the output files of Partition.
+type partitioner struct {
+	header   []string
+	dir      string
+	opts     PartitionOptions
+	paths    map[string]string        // output file of each key seen
+	open     map[string]*list.Element // open outputs by key
+	lru      *list.List               // open outputs, most recently used first
+	maxFiles int
+}
+
+// A partitionFile is an open output file.
+type partitionFile struct {
+	key string
+	f   *os.File
+	enc *Encoder
+}
+
+// encoder returns the encoder writing the partition of key, opening its
+// file if needed.
+func (p *partitioner) encoder(key string) (*Encoder, error) {
+	if e, ok := p.open[key]; ok {
+		p.lru.MoveToFront(e)
+		return e.Value.(*partitionFile).enc, nil
+	}
+
+	if p.lru.Len() >= p.maxFiles {
+		if err := p.evict(p.lru.Back()); err != nil {
+			return nil, err
+		}
+	}
+
+	path, seen := p.paths[key]
+	flag := os.O_WRONLY | os.O_APPEND
+	if !seen {
+		path = filepath.Join(p.dir, p.opts.FileName(key))
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flag, 0666)
+	if err != nil {
+		return nil, err
+	}
+	pf := &partitionFile{key: key, f: f, enc: NewEncoder(f)}
+	pf.enc.Delimiter = p.delimiter()
+	if !seen {
+		p.paths[key] = path
+		if err := pf.enc.Encode(p.header); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	p.open[key] = p.lru.PushFront(pf)
+	return pf.enc, nil
+}
+
+// delimiter returns the delimiter of the output files, which is the
+// delimiter of the input.
+func (p *partitioner) delimiter() byte {
+	if p.opts.Dialect.Delimiter == 0 {
+		return ','
+	}
+	return p.opts.Dialect.Delimiter
+}
+
+// evict flushes and closes the output in e.
+func (p *partitioner) evict(e *list.Element) error {
+	pf := p.lru.Remove(e).(*partitionFile)
+	delete(p.open, pf.key)
+	err := pf.enc.Flush()
+	if cerr := pf.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// close flushes and closes all open outputs.
+func (p *partitioner) close() error {
+	var err error
+	for p.lru.Len() > 0 {
+		if eerr := p.evict(p.lru.Front()); err == nil {
+			err = eerr
+		}
+	}
+	return err
+}
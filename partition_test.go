@@ -0,0 +1,54 @@
+package csv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPartition(t *testing.T) {
+	dir, err := ioutil.TempDir("", "csv-partition-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	in := "id;country\n1;US\n2;FR\n3;US\n4;a/b\n5;FR\n6;US\n"
+	paths, err := Partition(strings.NewReader(in), "country", dir, PartitionOptions{
+		Dialect:      Dialect{Delimiter: ';'},
+		MaxOpenFiles: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"US":  "id;country\n1;US\n3;US\n6;US\n",
+		"FR":  "id;country\n2;FR\n5;FR\n",
+		"a/b": "id;country\n4;a/b\n",
+	}
+	if len(paths) != len(want) {
+		t.Errorf("got %d partitions, want %d", len(paths), len(want))
+	}
+	for key, content := range want {
+		if filepath.Dir(paths[key]) != dir {
+			t.Errorf("%s: path %q is not in %q", key, paths[key], dir)
+			continue
+		}
+		b, err := ioutil.ReadFile(paths[key])
+		if err != nil {
+			t.Errorf("%s: %v", key, err)
+		} else if string(b) != content {
+			t.Errorf("%s: content=%q want %q", key, b, content)
+		}
+	}
+}
+
+func TestPartitionUnknownColumn(t *testing.T) {
+	_, err := Partition(strings.NewReader("a,b\n1,2\n"), "c", os.TempDir(), PartitionOptions{})
+	if err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
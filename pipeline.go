@@ -0,0 +1,303 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// A Source yields the records of a stream. The records carry the header of
+// the stream, which is not a record itself.
+type Source interface {
+	// Next returns the next record, or io.EOF at the end of the stream.
+	Next() (Record, error)
+	// Close releases the resources held by the source.
+	Close() error
+}
+
+// A Sink receives the records at the end of a pipeline.
+type Sink interface {
+	// Write consumes a record. The record's fields may be retained.
+	Write(r Record) error
+	// Close flushes and releases the resources held by the sink.
+	Close() error
+}
+
+// errNoSink is returned by Pipeline.Run when no sink was set with To.
+var errNoSink = errors.New("csv: pipeline has no sink")
+
+// defaultPipelineBuffer is the default capacity of the channels between
+// pipeline stages.
+const defaultPipelineBuffer = 64
+
+// A Pipeline reads records from a Source, applies Transforms in stages
+// running on their own goroutines, and writes the results to a Sink.
+// Stages are connected by bounded channels, so a slow stage or sink holds
+// back the stages before it instead of buffering without limit. Records
+// keep their order through every stage, including parallel ones.
+//
+// A Pipeline is built with NewPipeline and its chained methods, then run
+// once with Run.
+type Pipeline struct {
+	src    Source
+	stages []pipelineStage
+	sink   Sink
+	buffer int
+	limit  RateLimit
+}
+
+type pipelineStage struct {
+	t       Transform
+	workers int
+}
+
+// NewPipeline returns a pipeline reading from src.
+func NewPipeline(src Source) *Pipeline {
+	return &Pipeline{src: src, buffer: defaultPipelineBuffer}
+}
+
+// Buffer sets the capacity of the channels between stages. It defaults
+// to 64.
+func (p *Pipeline) Buffer(n int) *Pipeline {
+	if n < 0 {
+		n = 0
+	}
+	p.buffer = n
+	return p
+}
+
+// Then adds a stage for each of transforms, in order, run by a single
+// goroutine each.
+func (p *Pipeline) Then(transforms ...Transform) *Pipeline {
+	for _, t := range transforms {
+		p.stages = append(p.stages, pipelineStage{t: t, workers: 1})
+	}
+	return p
+}
+
+// Parallel adds a stage applying t to several records at once on the
+// given number of goroutines. t must be safe for concurrent use.
+func (p *Pipeline) Parallel(t Transform, workers int) *Pipeline {
+	if workers < 1 {
+		workers = 1
+	}
+	p.stages = append(p.stages, pipelineStage{t: t, workers: workers})
+	return p
+}
+
+// To sets the sink of the pipeline.
+func (p *Pipeline) To(sink Sink) *Pipeline {
+	p.sink = sink
+	return p
+}
+
+// Run runs the pipeline until the source is exhausted, an error occurs in
+// any part of it, or ctx is done, and returns the first error. The source
+// and the sink are closed before Run returns.
+func (p *Pipeline) Run(ctx context.Context) (err error) {
+	if p.sink == nil {
+		p.src.Close()
+		return errNoSink
+	}
+	defer func() {
+		if cerr := p.src.Close(); err == nil {
+			err = cerr
+		}
+		if cerr := p.sink.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	first, err := p.src.Next()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// compute the header of every stage from the header of the stream
+	header := first.Header()
+	inputs := make([]Record, len(p.stages))
+	for i, st := range p.stages {
+		inputs[i] = NewRecord(header, nil)
+		if header, err = st.t.Header(header); err != nil {
+			return err
+		}
+	}
+	output := NewRecord(header, nil)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r := &pipelineRun{ctx: ctx, cancel: cancel, buffer: p.buffer}
+
+	src := make(chan []string, p.buffer)
+	r.goroutine(func() error {
+		defer close(src)
+		limiter := newRateLimiter(p.limit)
+		fields := first.Fields
+		for {
+			if err := limiter.wait(ctx, fieldBytes(fields)); err != nil {
+				return nil // the pipeline was stopped
+			}
+			if !r.send(src, fields) {
+				return nil
+			}
+			next, err := p.src.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fields = next.Fields
+		}
+	})
+	var in <-chan []string = src
+	for i, st := range p.stages {
+		in = r.stage(st, inputs[i], in)
+	}
+	r.goroutine(func() error {
+		for fields := range in {
+			out := output
+			out.Fields = fields
+			if err := p.sink.Write(out); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	r.wg.Wait()
+
+	if r.err == nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return r.err
+}
+
+// A pipelineRun is the state of a running pipeline: its goroutines and the
+// first error, which cancels the others.
+type pipelineRun struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	buffer int
+
+	wg   sync.WaitGroup
+	once sync.Once
+	err  error
+}
+
+// goroutine runs fn on a new goroutine, stopping the pipeline if it fails.
+func (r *pipelineRun) goroutine(fn func() error) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := fn(); err != nil {
+			r.once.Do(func() {
+				r.err = err
+				r.cancel()
+			})
+		}
+	}()
+}
+
+// send sends fields on ch, reporting false if the pipeline was stopped.
+func (r *pipelineRun) send(ch chan<- []string, fields []string) bool {
+	select {
+	case ch <- fields:
+		return true
+	case <-r.ctx.Done():
+		return false
+	}
+}
+
+// A pipelineJob is a record being transformed by a parallel stage.
+type pipelineJob struct {
+	fields []string
+	done   chan pipelineResult
+}
+
+type pipelineResult struct {
+	fields []string
+	err    error
+}
+
+// stage starts the goroutines of st reading from in and returns the
+// channel of its output. The input header of st is that of header.
+func (r *pipelineRun) stage(st pipelineStage, header Record, in <-chan []string) <-chan []string {
+	out := make(chan []string, r.buffer)
+	apply := func(fields []string) ([]string, error) {
+		rec := header
+		rec.Fields = fields
+		return st.t.Apply(rec)
+	}
+
+	if st.workers == 1 {
+		r.goroutine(func() error {
+			defer close(out)
+			for fields := range in {
+				fields, err := apply(fields)
+				if err != nil {
+					return err
+				}
+				if !r.send(out, fields) {
+					return nil
+				}
+			}
+			return nil
+		})
+		return out
+	}
+
+	// Jobs are queued in input order and handed to the workers; results
+	// are sent in queue order. The queue bounds the records in flight.
+	queue := make(chan pipelineJob, st.workers+r.buffer)
+	work := make(chan pipelineJob, st.workers)
+	r.goroutine(func() error {
+		defer close(queue)
+		defer close(work)
+		for fields := range in {
+			job := pipelineJob{fields: fields, done: make(chan pipelineResult, 1)}
+			select {
+			case queue <- job:
+			case <-r.ctx.Done():
+				return nil
+			}
+			select {
+			case work <- job:
+			case <-r.ctx.Done():
+				return nil
+			}
+		}
+		return nil
+	})
+	for i := 0; i < st.workers; i++ {
+		r.goroutine(func() error {
+			for job := range work {
+				fields, err := apply(job.fields)
+				job.done <- pipelineResult{fields, err}
+			}
+			return nil
+		})
+	}
+	r.goroutine(func() error {
+		defer close(out)
+		for job := range queue {
+			var res pipelineResult
+			select {
+			case res = <-job.done:
+			case <-r.ctx.Done():
+				return nil
+			}
+			if res.err != nil {
+				return res.err
+			}
+			if !r.send(out, res.fields) {
+				return nil
+			}
+		}
+		return nil
+	})
+	return out
+}
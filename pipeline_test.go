@@ -0,0 +1,130 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSource yields n records with header "n,sq".
+type testSource struct {
+	n, i   int
+	closed bool
+}
+
+func (s *testSource) Next() (Record, error) {
+	if s.i >= s.n {
+		return Record{}, io.EOF
+	}
+	s.i++
+	return NewRecord([]string{"n", "sq"}, []string{strconv.Itoa(s.i), ""}), nil
+}
+
+func (s *testSource) Close() error { s.closed = true; return nil }
+
+// testSink collects the records written to it.
+type testSink struct {
+	header  []string
+	records [][]string
+	fail    error
+	closed  bool
+}
+
+func (s *testSink) Write(r Record) error {
+	if s.fail != nil {
+		return s.fail
+	}
+	s.header = r.Header()
+	s.records = append(s.records, r.Fields)
+	return nil
+}
+
+func (s *testSink) Close() error { s.closed = true; return nil }
+
+// squareTransform fills column sq with the square of column n, slowly for
+// some records so parallel workers finish out of order.
+type squareTransform struct{}
+
+func (squareTransform) Header(h []string) ([]string, error) { return h, nil }
+
+func (squareTransform) Apply(r Record) ([]string, error) {
+	n, err := strconv.Atoi(r.Fields[0])
+	if err != nil {
+		return nil, err
+	}
+	if n%7 == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	r.Fields[1] = strconv.Itoa(n * n)
+	return r.Fields, nil
+}
+
+func TestPipeline(t *testing.T) {
+	src := &testSource{n: 100}
+	sink := &testSink{}
+	err := NewPipeline(src).
+		Buffer(2).
+		Parallel(squareTransform{}, 4).
+		Then(RenameColumn("sq", "square"), Replace(regexp.MustCompile(`^1$`), "one", "n")).
+		To(sink).
+		Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !src.closed || !sink.closed {
+		t.Error("source or sink not closed")
+	}
+	if !reflect.DeepEqual(sink.header, []string{"n", "square"}) {
+		t.Errorf("header %q", sink.header)
+	}
+	if len(sink.records) != 100 {
+		t.Fatalf("%d records, want 100", len(sink.records))
+	}
+	for i, r := range sink.records {
+		n := i + 1
+		want := []string{strconv.Itoa(n), strconv.Itoa(n * n)}
+		if n == 1 {
+			want[0] = "one"
+		}
+		if !reflect.DeepEqual(r, want) {
+			t.Fatalf("record %d: %q, want %q", i, r, want)
+		}
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	errSink := errors.New("sink full")
+	err := NewPipeline(&testSource{n: 1000}).Parallel(squareTransform{}, 3).
+		To(&testSink{fail: errSink}).Run(context.Background())
+	if err != errSink {
+		t.Errorf("sink error: %v", err)
+	}
+
+	bad := &testSource{n: 50}
+	err = NewPipeline(bad).Then(Mask(map[string]Masker{"n": func(s string) (string, error) {
+		if s == "30" {
+			return "", errors.New("bad")
+		}
+		return s, nil
+	}})).To(&testSink{}).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "bad") {
+		t.Errorf("transform error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = NewPipeline(&testSource{n: 1000}).To(&testSink{}).Run(ctx)
+	if err != context.Canceled {
+		t.Errorf("canceled: %v", err)
+	}
+
+	if err := NewPipeline(&testSource{}).Run(context.Background()); err != errNoSink {
+		t.Errorf("no sink: %v", err)
+	}
+}
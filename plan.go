@@ -0,0 +1,171 @@
+package csv
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A decodePlan binds the fields of a struct type to the columns of a
+// stream, with the conversion of each field resolved in advance, so that
+// DecodeStruct does no per-record reflection beyond setting fields.
+type decodePlan struct {
+	fields []planField
+}
+
+type planField struct {
+	structField
+	col    int     // index of the column, or -1 if there is none
+	schema *Column // schema column of col, if any
+	set    setter
+}
+
+// A setter stores a field into a value of the type it was built for.
+type setter func(v reflect.Value, field []byte) error
+
+// A planKey identifies what a plan depends on besides the converters and
+// the header normalizer of the decoder, which reset the plans when set
+// through RegisterConverter and SetNormalizeHeader. A NormalizeHeader
+// assigned directly is told apart by its code only, which closures of one
+// function literal share.
+type planKey struct {
+	t         reflect.Type
+	header    string  // groupID of the header, or "" without header
+	normalize uintptr // code of NormalizeHeader, which looks up names
+	schema    *Schema
+	format    NumberFormat
+}
+
+// plan returns the decode plan of the struct type t for the current
+// header, building and caching it on first use.
+func (d *Decoder) plan(t reflect.Type) *decodePlan {
+	key := planKey{t: t, schema: d.Schema, format: d.NumberFormat}
+	if d.header != nil {
+		key.header = d.headerID
+	}
+	if d.NormalizeHeader != nil {
+		key.normalize = reflect.ValueOf(d.NormalizeHeader).Pointer()
+	}
+	if p, ok := d.plans[key]; ok {
+		return p
+	}
+
+	fields := cachedStructFields(t)
+	p := &decodePlan{fields: make([]planField, len(fields))}
+	for pos, f := range fields {
+		col := pos
+		if d.header != nil {
+			col = d.columnIndex(f.name)
+		}
+		pf := planField{structField: f, col: col}
+		if col >= 0 {
+			pf.schema = d.schemaColumn(col)
+		}
+		format := d.NumberFormat
+		if pf.schema != nil && pf.schema.NumberFormat != nil {
+			format = *pf.schema.NumberFormat
+		}
+		pf.set = d.setterFor(t.Field(f.index).Type, format)
+		p.fields[pos] = pf
+	}
+
+	if d.plans == nil {
+		d.plans = make(map[planKey]*decodePlan)
+	}
+	d.plans[key] = p
+	return p
+}
+
+// setterFor returns the setter converting fields to values of type t,
+// preferring registered converters over encoding.TextUnmarshaler and the
+// built-in conversions. Numbers are read in the given format.
+func (d *Decoder) setterFor(t reflect.Type, format NumberFormat) setter {
+	if fn, ok := d.converters[t]; ok {
+		return func(v reflect.Value, field []byte) error {
+			x, err := fn(field)
+			if err != nil {
+				return err
+			}
+			xv := reflect.ValueOf(x)
+			if !xv.IsValid() {
+				v.Set(reflect.Zero(t))
+				return nil
+			}
+			if !xv.Type().AssignableTo(t) {
+				return fmt.Errorf("converter returned %s", xv.Type())
+			}
+			v.Set(xv)
+			return nil
+		}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		elem := d.setterFor(t.Elem(), format)
+		return func(v reflect.Value, field []byte) error {
+			if len(field) == 0 {
+				v.Set(reflect.Zero(t))
+				return nil
+			}
+			if v.IsNil() {
+				v.Set(reflect.New(t.Elem()))
+			}
+			return elem(v.Elem(), field)
+		}
+	}
+
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return func(v reflect.Value, field []byte) error {
+			return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(field)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value, field []byte) error {
+			v.SetString(string(field))
+			return nil
+		}
+	case reflect.Bool:
+		return func(v reflect.Value, field []byte) error {
+			b, err := strconv.ParseBool(strings.TrimSpace(string(field)))
+			if err != nil {
+				return err
+			}
+			v.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := t.Bits()
+		return func(v reflect.Value, field []byte) error {
+			n, err := strconv.ParseInt(number(field, format), 10, bits)
+			if err != nil {
+				return err
+			}
+			v.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bits := t.Bits()
+		return func(v reflect.Value, field []byte) error {
+			n, err := strconv.ParseUint(number(field, format), 10, bits)
+			if err != nil {
+				return err
+			}
+			v.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		bits := t.Bits()
+		return func(v reflect.Value, field []byte) error {
+			f, err := strconv.ParseFloat(number(field, format), bits)
+			if err != nil {
+				return err
+			}
+			v.SetFloat(f)
+			return nil
+		}
+	}
+	return func(reflect.Value, []byte) error { return ErrUnsupportedType }
+}
@@ -0,0 +1,110 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodePlanCache(t *testing.T) {
+	type row struct {
+		A string
+		B int
+	}
+	dec := NewDecoder(strings.NewReader("B,A\n1,x\n2,y\n\nA,B\nz,3\n"))
+	dec.Sections = true
+
+	var got []row
+	for _, ok := dec.NextSection(); ok; _, ok = dec.NextSection() {
+		for dec.More() {
+			var r row
+			if err := dec.DecodeStruct(&r); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, r)
+		}
+	}
+	want := []row{{"x", 1}, {"y", 2}, {"z", 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if len(dec.plans) != 2 {
+		t.Errorf("%d plans, want one per header", len(dec.plans))
+	}
+
+	// registering a converter rebuilds the plans
+	dec = NewDecoder(strings.NewReader("x,1\ny,2\n"))
+	var r row
+	dec.DecodeStruct(&r)
+	dec.RegisterConverter(reflect.TypeOf(0), func(b []byte) (interface{}, error) { return 42, nil })
+	dec.DecodeStruct(&r)
+	if r.B != 42 {
+		t.Errorf("converter registered after first record not used: %+v", r)
+	}
+}
+
+func TestDecodePlanNormalizeHeader(t *testing.T) {
+	type row struct{ Id string }
+	dec := NewDecoder(strings.NewReader("ID,x\n1,a\n2,b\n"))
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	var r row
+	if err := dec.DecodeStruct(&r); err != nil || r.Id != "" {
+		t.Fatalf("without normalizer: %+v, %v", r, err)
+	}
+	dec.NormalizeHeader = strings.ToUpper
+	if err := dec.DecodeStruct(&r); err != nil || r.Id != "2" {
+		t.Errorf("with normalizer set after first record: %+v, %v", r, err)
+	}
+}
+
+func TestDecodePlanSetNormalizeHeader(t *testing.T) {
+	type row struct{ Id string }
+	rename := func(col string) HeaderNormalizer {
+		return func(name string) string {
+			if name == "Id" {
+				return col
+			}
+			return name
+		}
+	}
+	dec := NewDecoder(strings.NewReader("x,y\n1,2\n3,4\n"))
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	var r row
+	dec.SetNormalizeHeader(rename("x"))
+	if err := dec.DecodeStruct(&r); err != nil || r.Id != "1" {
+		t.Fatalf("bound to x: %+v, %v", r, err)
+	}
+	dec.SetNormalizeHeader(rename("y"))
+	if err := dec.DecodeStruct(&r); err != nil || r.Id != "4" {
+		t.Errorf("closure of the same literal bound to y: %+v, %v", r, err)
+	}
+}
+
+func BenchmarkDecodeStruct(b *testing.B) {
+	type row struct {
+		Name  string
+		Age   int
+		Score float64
+		OK    bool
+	}
+	var in strings.Builder
+	in.WriteString("Name,Age,Score,OK\n")
+	for i := 0; i < 1000; i++ {
+		in.WriteString("ann,30,12.5,true\n")
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dec := NewDecoder(strings.NewReader(in.String()))
+		dec.ReadHeader()
+		var r row
+		for dec.More() {
+			if err := dec.DecodeStruct(&r); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package csv
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// A RecordPool recycles the memory of records returned by DecodeRecord.
+// It is safe for concurrent use and may be shared by several decoders.
+// The zero value is an empty pool ready to use.
+type RecordPool struct {
+	p sync.Pool
+}
+
+// NewRecordPool returns an empty pool.
+func NewRecordPool() *RecordPool {
+	return &RecordPool{p: sync.Pool{New: func() interface{} { return new(recordArena) }}}
+}
+
+// A recordArena holds the field slice and field bytes of a pooled record.
+type recordArena struct {
+	buf    []byte
+	fields []string
+}
+
+// UsePool makes DecodeRecord allocate records from p. Such records must be
+// released with Record.Release once they are no longer used, after which
+// neither the record nor any of its field strings may be used again. A
+// nil pool restores normal allocation.
+func (d *Decoder) UsePool(p *RecordPool) {
+	d.pool = p
+}
+
+// Release returns the memory of a record decoded with a pool to its pool.
+// It does nothing for other records. A record must not be released twice.
+func (r Record) Release() {
+	if r.arena == nil {
+		return
+	}
+	r.arena.fields = r.arena.fields[:0]
+	r.pool.p.Put(r.arena)
+}
+
+// pooledRecord returns the current record with fields copied into an
+// arena taken from the decoder's pool.
+func (d *Decoder) pooledRecord() Record {
+	a, _ := d.pool.p.Get().(*recordArena)
+	if a == nil {
+		a = new(recordArena)
+	}
+	a.buf = append(a.buf[:0], d.lineBuffer.Bytes()...)
+	a.fields = a.fields[:0]
+	for i, start := range d.fieldIndexes {
+		end := len(a.buf)
+		if i+1 < len(d.fieldIndexes) {
+			end = d.fieldIndexes[i+1]
+		}
+		var field string
+		if end > start {
+			field = unsafe.String(&a.buf[start], end-start)
+		}
+		a.fields = append(a.fields, field)
+	}
+	return Record{
+		Fields: a.fields,
+		header: d.header,
+		index:  d.headerIndex,
+		pool:   d.pool,
+		arena:  a,
+	}
+}
@@ -0,0 +1,56 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUsePool(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("h1,h2\na,\"b,c\"\n,x\n"))
+	dec.ReadHeader()
+	dec.UsePool(NewRecordPool())
+
+	want := [][]string{{"a", "b,c"}, {"", "x"}}
+	for i := 0; dec.More(); i++ {
+		r, err := dec.DecodeRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r.Fields, want[i]) {
+			t.Errorf("record %d: %q, want %q", i, r.Fields, want[i])
+		}
+		if v, _ := r.Get("h2"); v != want[i][1] {
+			t.Errorf("record %d: h2=%q, want %q", i, v, want[i][1])
+		}
+		r.Release()
+	}
+}
+
+func TestUsePoolZero(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b\nc,d\n"))
+	dec.UsePool(new(RecordPool))
+	for _, want := range [][]string{{"a", "b"}, {"c", "d"}} {
+		r, err := dec.DecodeRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r.Fields, want) {
+			t.Errorf("got %q, want %q", r.Fields, want)
+		}
+		r.Release()
+	}
+}
+
+func BenchmarkReadPooled(b *testing.B) {
+	b.ReportAllocs()
+	d := NewDecoder(&nTimes{s: benchmarkCSVData, n: b.N})
+	d.UsePool(NewRecordPool())
+	for d.More() {
+		r, err := d.DecodeRecord()
+		if err != nil {
+			b.Fatal(err)
+		}
+		r.Release()
+	}
+}
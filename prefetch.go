@@ -0,0 +1,124 @@
+package csv
+
+import (
+	"io"
+	"sync"
+)
+
+// prefetchSize is the size of each buffer filled by a prefetching reader.
+const prefetchSize = 64 << 10
+
+// Prefetch makes the decoder read its input on a background goroutine,
+// keeping up to n buffers of read-ahead so that reads from slow sources
+// overlap with parsing. It must be called before the first record is
+// decoded. Close stops the goroutine if the input is not read to the end.
+func (d *Decoder) Prefetch(n int) {
+	if n < 1 {
+		n = 1
+	}
+	d.prefetch = newPrefetchReader(d.r, n, prefetchSize)
+	d.r = d.prefetch
+}
+
+// Close releases the resources held by the decoder, such as the goroutine
+// started by Prefetch and the files reopened by Follow, and ends the span
+// of its Tracer. It does not close the underlying reader.
+func (d *Decoder) Close() error {
+	d.endSpan()
+	if d.prefetch != nil {
+		d.prefetch.close()
+	}
+	if d.follow != nil {
+		d.follow.close()
+	}
+	return nil
+}
+
+// A prefetchReader reads from an underlying reader on a goroutine into a
+// ring of buffers.
+type prefetchReader struct {
+	free chan []byte        // buffers ready to be filled
+	full chan prefetchChunk // filled buffers, in order
+	stop chan struct{}
+	once sync.Once
+	n    int // number of buffers
+
+	buf []byte // buffer being consumed, returned to free once empty
+	cur []byte // unread part of buf
+	err error
+}
+
+// A prefetchChunk is the result of one read from the underlying reader.
+type prefetchChunk struct {
+	b   []byte
+	err error
+}
+
+func newPrefetchReader(r io.Reader, n, size int) *prefetchReader {
+	p := &prefetchReader{
+		free: make(chan []byte, n),
+		full: make(chan prefetchChunk, n),
+		stop: make(chan struct{}),
+		n:    n,
+	}
+	for i := 0; i < n; i++ {
+		p.free <- make([]byte, size)
+	}
+	go p.fill(r)
+	return p
+}
+
+// fill reads from r into free buffers until r returns an error or the
+// reader is closed.
+func (p *prefetchReader) fill(r io.Reader) {
+	for {
+		var b []byte
+		select {
+		case b = <-p.free:
+		case <-p.stop:
+			return
+		}
+		n, err := r.Read(b[:cap(b)])
+		select {
+		case p.full <- prefetchChunk{b[:n], err}:
+		case <-p.stop:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Read reads the prefetched input. It fails with io.ErrClosedPipe once the
+// reader is closed, as the goroutine filling it is gone.
+func (p *prefetchReader) Read(b []byte) (int, error) {
+	select {
+	case <-p.stop:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	for len(p.cur) == 0 {
+		if p.err != nil {
+			return 0, p.err
+		}
+		if p.buf != nil {
+			p.free <- p.buf
+			p.buf = nil
+		}
+		var c prefetchChunk
+		select {
+		case c = <-p.full:
+		case <-p.stop:
+			return 0, io.ErrClosedPipe
+		}
+		p.buf, p.cur, p.err = c.b, c.b, c.err
+	}
+	n := copy(b, p.cur)
+	p.cur = p.cur[n:]
+	return n, nil
+}
+
+func (p *prefetchReader) close() {
+	p.once.Do(func() { close(p.stop) })
+}
@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestPrefetch(t *testing.T) {
+	in := strings.Repeat("a,\"b\nb\",c\n", 50000)
+	for _, n := range []int{0, 1, 4} {
+		dec := NewDecoder(iotest.HalfReader(strings.NewReader(in)))
+		dec.Prefetch(n)
+		count := 0
+		for dec.More() {
+			record, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(record, []string{"a", "b\nb", "c"}) {
+				t.Fatalf("n=%d: record %d is %q", n, count, record)
+			}
+			count++
+		}
+		if count != 50000 {
+			t.Errorf("n=%d: got %d records, want 50000", n, count)
+		}
+		dec.Close()
+	}
+}
+
+func TestPrefetchClose(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	dec := NewDecoder(pr)
+	dec.Prefetch(2)
+	go pw.Write([]byte("a,b\n"))
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	dec.Close()
+	dec.Close()
+
+	// reading after Close fails instead of waiting for the stopped
+	// goroutine
+	done := make(chan error, 1)
+	go func() {
+		_, err := dec.Decode()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, io.ErrClosedPipe) {
+			t.Errorf("Decode after Close: error %v, want %v", err, io.ErrClosedPipe)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Decode after Close blocked")
+	}
+}
@@ -0,0 +1,334 @@
+package csv
+
+import (
+	"container/heap"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+)
+
+// Sizes of the sketches of a Profiler. The count-min sketch of a column
+// takes profileDepth×profileWidth counters, its distinct count estimate
+// 1<<profileRegisterBits bytes.
+const (
+	profileWidth        = 2048
+	profileDepth        = 4
+	profileRegisterBits = 12
+)
+
+// A ValueCount is a value and the number of times it was seen.
+type ValueCount struct {
+	Value string
+	Count int64
+}
+
+// A ColumnProfile describes the values of a column.
+type ColumnProfile struct {
+	Name  string // from the header, if any
+	Count int64  // number of non-empty fields
+	Empty int64  // number of empty fields
+
+	// Distinct estimates the number of distinct non-empty values, within
+	// a few percent.
+	Distinct int64
+
+	// Top are the most frequent values, most frequent first. Their counts
+	// are estimates that may exceed the true counts, and on inputs with
+	// many values of similar frequency a value may be missing.
+	Top []ValueCount
+
+	// Numeric, if not nil, describes the distribution of the values of a
+	// column whose non-empty fields are all numbers.
+	Numeric *NumericProfile
+}
+
+// A NumericProfile describes the distribution of the values of a numeric
+// column with a sketch, from which quantiles and histograms are
+// estimated. Estimates are most accurate for quantiles near 0 and 1.
+type NumericProfile struct {
+	Min, Max, Mean float64
+
+	digest *digest
+}
+
+// Quantile returns an estimate of the q-quantile of the values, such as
+// the median for 0.5 and the 99th percentile for 0.99.
+func (p *NumericProfile) Quantile(q float64) float64 {
+	return p.digest.quantile(q)
+}
+
+// A HistogramBin is a range of values and an estimate of the number of
+// values in it.
+type HistogramBin struct {
+	Low, High float64 // the bin holds values > Low and <= High
+	Count     int64
+}
+
+// Histogram returns an estimated histogram of the values in n bins of
+// equal width between Min and Max. The first bin also holds Min.
+func (p *NumericProfile) Histogram(n int) []HistogramBin {
+	if n <= 0 {
+		return nil
+	}
+	bins := make([]HistogramBin, n)
+	width := (p.Max - p.Min) / float64(n)
+	var prev int64
+	for i := range bins {
+		bins[i].Low = p.Min + float64(i)*width
+		bins[i].High = p.Min + float64(i+1)*width
+		if i == n-1 {
+			bins[i].High = p.Max
+		}
+		cum := int64(math.Round(p.digest.cdf(bins[i].High) * p.digest.n))
+		bins[i].Count = cum - prev
+		prev = cum
+	}
+	return bins
+}
+
+// A Profiler describes the columns of the records read by a Decoder, set
+// as its Profile, in one pass and bounded memory: the number of empty and
+// non-empty fields, an estimate of the number of distinct values, the most
+// frequent values and the distribution of numbers. The records after the
+// header are profiled.
+//
+// Only the columns kept by SkipColumns are profiled, since the fields of
+// the others are not stored, and Columns narrows them further, so a pass
+// projecting a few columns of a wide input profiles only those.
+type Profiler struct {
+	// TopN is the number of most frequent values kept for each column.
+	// None are kept when 0.
+	TopN int
+
+	// If Numeric is true, the distribution of the values of numeric
+	// columns is sketched. Numbers are read in the NumberFormat of their
+	// schema column or of the Decoder.
+	Numeric bool
+
+	// Columns, if not nil, names the columns profiled, matched with the
+	// header read by ReadHeader. The other columns cost no memory or time.
+	Columns []string
+
+	columns []*columnProfiler // nil for the columns not profiled
+}
+
+// A columnProfiler profiles one column.
+type columnProfiler struct {
+	profile   ColumnProfile
+	sketch    []uint32 // count-min sketch, profileDepth rows of profileWidth
+	registers []uint8  // HyperLogLog registers
+	top       topHeap
+	digest    *digest // nil once a field is not a number
+	sum       float64
+}
+
+// Profile returns the profiles of the columns seen so far, in the order of
+// the input.
+func (p *Profiler) Profile() []ColumnProfile {
+	out := make([]ColumnProfile, 0, len(p.columns))
+	for _, c := range p.columns {
+		if c == nil {
+			continue
+		}
+		out = append(out, c.profile)
+		i := len(out) - 1
+		out[i].Distinct = c.distinct()
+		out[i].Top = c.top.sorted()
+		if c.digest != nil && c.digest.n > 0 {
+			out[i].Numeric = &NumericProfile{
+				Min:    c.digest.min,
+				Max:    c.digest.max,
+				Mean:   c.sum / c.digest.n,
+				digest: c.digest.clone(),
+			}
+		}
+	}
+	return out
+}
+
+// observe profiles the current record of d.
+func (p *Profiler) observe(d *Decoder) {
+	for i := range d.fieldIndexes {
+		if i >= len(p.columns) {
+			p.columns = append(p.columns, p.newColumn(d, i))
+		}
+		c := p.columns[i]
+		if c == nil {
+			continue
+		}
+		field := d.field(i)
+		c.add(field, p.TopN)
+		if c.digest != nil && len(field) > 0 {
+			format := d.NumberFormat
+			if col := d.schemaColumn(i); col != nil && col.NumberFormat != nil {
+				format = *col.NumberFormat
+			}
+			v, err := strconv.ParseFloat(number(field, format), 64)
+			if err != nil || math.IsNaN(v) {
+				c.digest = nil
+				continue
+			}
+			c.digest.add(v)
+			c.sum += v
+		}
+	}
+}
+
+// newColumn returns the profiler of the i'th column of d, or nil if the
+// column is not profiled.
+func (p *Profiler) newColumn(d *Decoder, i int) *columnProfiler {
+	name := ""
+	if i < len(d.header) {
+		name = d.header[i]
+	}
+	if p.Columns != nil {
+		selected := false
+		for _, col := range p.Columns {
+			selected = selected || col == name
+		}
+		if !selected {
+			return nil
+		}
+	}
+	c := &columnProfiler{registers: make([]uint8, 1<<profileRegisterBits)}
+	c.profile.Name = name
+	if p.TopN > 0 {
+		c.sketch = make([]uint32, profileDepth*profileWidth)
+		c.top.index = make(map[string]int)
+	}
+	if p.Numeric {
+		c.digest = &digest{}
+	}
+	return c
+}
+
+func (c *columnProfiler) add(field []byte, topN int) {
+	if len(field) == 0 {
+		c.profile.Empty++
+		return
+	}
+	c.profile.Count++
+	h := profileHash(field)
+
+	// HyperLogLog: the leading bits pick a register, which keeps the
+	// longest run of leading zeros seen in the rest.
+	r := h >> (64 - profileRegisterBits)
+	if z := uint8(bits.LeadingZeros64(h<<profileRegisterBits|1<<(profileRegisterBits-1)) + 1); z > c.registers[r] {
+		c.registers[r] = z
+	}
+
+	if topN == 0 {
+		return
+	}
+	count := c.count(h)
+	if i, ok := c.top.index[string(field)]; ok {
+		c.top.entries[i].Count = count
+		heap.Fix(&c.top, i)
+		return
+	}
+	switch {
+	case len(c.top.entries) < topN:
+		heap.Push(&c.top, ValueCount{Value: string(field), Count: count})
+	case count > c.top.entries[0].Count:
+		delete(c.top.index, c.top.entries[0].Value)
+		c.top.entries[0] = ValueCount{Value: string(field), Count: count}
+		c.top.index[c.top.entries[0].Value] = 0
+		heap.Fix(&c.top, 0)
+	}
+}
+
+// count adds one to the count of the value hashed to h in the count-min
+// sketch and returns its new estimate.
+func (c *columnProfiler) count(h uint64) int64 {
+	h1, h2 := uint32(h), uint32(h>>32)|1
+	min := uint32(math.MaxUint32)
+	for row := 0; row < profileDepth; row++ {
+		cell := &c.sketch[row*profileWidth+int((h1+uint32(row)*h2)%profileWidth)]
+		if *cell < math.MaxUint32 {
+			*cell++
+		}
+		if *cell < min {
+			min = *cell
+		}
+	}
+	return int64(min)
+}
+
+// distinct returns the HyperLogLog estimate of the number of distinct
+// values, corrected by linear counting for small numbers.
+func (c *columnProfiler) distinct() int64 {
+	m := float64(len(c.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range c.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+	return int64(e + 0.5)
+}
+
+// A topHeap is a min-heap of the most frequent values of a column, with
+// the index of each value in it.
+type topHeap struct {
+	entries []ValueCount
+	index   map[string]int
+}
+
+func (h *topHeap) Len() int { return len(h.entries) }
+
+func (h *topHeap) Less(i, j int) bool {
+	a, b := h.entries[i], h.entries[j]
+	return a.Count < b.Count || a.Count == b.Count && a.Value > b.Value
+}
+
+func (h *topHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.index[h.entries[i].Value] = i
+	h.index[h.entries[j].Value] = j
+}
+
+func (h *topHeap) Push(x interface{}) {
+	v := x.(ValueCount)
+	h.index[v.Value] = len(h.entries)
+	h.entries = append(h.entries, v)
+}
+
+func (h *topHeap) Pop() interface{} {
+	v := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	delete(h.index, v.Value)
+	return v
+}
+
+// sorted returns the values of h, most frequent first.
+func (h *topHeap) sorted() []ValueCount {
+	if len(h.entries) == 0 {
+		return nil
+	}
+	out := append([]ValueCount(nil), h.entries...)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Count > out[j].Count || out[i].Count == out[j].Count && out[i].Value < out[j].Value
+	})
+	return out
+}
+
+// profileHash returns a 64-bit hash of field, with its bits mixed so the
+// sketches can use any of them.
+func profileHash(field []byte) uint64 {
+	f := fnv.New64a()
+	f.Write(field)
+	h := f.Sum64()
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
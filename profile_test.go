@@ -0,0 +1,151 @@
+package csv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,color,note\n")
+	colors := []string{"red", "red", "red", "red", "blue", "blue", "blue", "green", "green", ""}
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&b, "%d,%s,n%d\n", i, colors[i%len(colors)], i%7)
+	}
+	dec := NewDecoder(strings.NewReader(b.String()))
+	dec.Profile = &Profiler{TopN: 2}
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	for dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cols := dec.Profile.Profile()
+	if len(cols) != 3 {
+		t.Fatalf("%d columns, want 3", len(cols))
+	}
+	color := cols[1]
+	if color.Name != "color" || color.Count != 4500 || color.Empty != 500 || color.Distinct != 3 {
+		t.Errorf("color profile %+v", color)
+	}
+	want := []ValueCount{{"red", 2000}, {"blue", 1500}}
+	if !reflect.DeepEqual(color.Top, want) {
+		t.Errorf("top colors %v, want %v", color.Top, want)
+	}
+	if d := cols[0].Distinct; d < 4750 || d > 5250 {
+		t.Errorf("distinct ids %d, want about 5000", d)
+	}
+	if d := cols[2].Distinct; d != 7 {
+		t.Errorf("distinct notes %d, want 7", d)
+	}
+	// ids are unique: collisions in the sketch only inflate their counts a little
+	if top := cols[0].Top; len(top) != 2 || top[0].Count > 10 {
+		t.Errorf("top ids %v", top)
+	}
+}
+
+func TestProfilerNoTop(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a\nb\na\n"))
+	dec.Profile = &Profiler{}
+	for dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cols := dec.Profile.Profile()
+	if len(cols) != 1 || cols[0].Count != 3 || cols[0].Distinct != 2 || cols[0].Top != nil {
+		t.Errorf("profile %+v", cols)
+	}
+}
+
+func TestProfilerColumns(t *testing.T) {
+	in := "id,blob,price,note\n1,x,2.5,a\n2,y,3,b\n"
+	for _, tt := range []struct {
+		skip    []int
+		columns []string
+		want    []string
+	}{
+		{skip: []int{1}, want: []string{"id", "price", "note"}},
+		{columns: []string{"price", "id"}, want: []string{"id", "price"}},
+		{skip: []int{1, 3}, columns: []string{"price", "blob"}, want: []string{"price"}},
+	} {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.SkipColumns(tt.skip)
+		dec.Profile = &Profiler{TopN: 1, Columns: tt.columns}
+		if _, err := dec.ReadHeader(); err != nil {
+			t.Fatal(err)
+		}
+		for dec.More() {
+			if _, err := dec.Decode(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		var names []string
+		for _, col := range dec.Profile.Profile() {
+			if col.Count != 2 {
+				t.Errorf("%+v: column %+v", tt, col)
+			}
+			names = append(names, col.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("skip %v, columns %q: profiled %q, want %q", tt.skip, tt.columns, names, tt.want)
+		}
+	}
+}
+
+func TestProfilerNumeric(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("n,word\n")
+	for i := 1; i <= 10000; i++ {
+		fmt.Fprintf(&b, "%d,w\n", i)
+	}
+	b.WriteString(",\n5,x\n")
+	dec := NewDecoder(strings.NewReader(b.String()))
+	dec.Profile = &Profiler{Numeric: true}
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	for dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cols := dec.Profile.Profile()
+	if cols[1].Numeric != nil {
+		t.Error("text column has a numeric profile")
+	}
+	num := cols[0].Numeric
+	if num == nil {
+		t.Fatal("no numeric profile")
+	}
+	if num.Min != 1 || num.Max != 10000 || num.Mean != 50005005.0/10001 {
+		t.Errorf("min %g, max %g, mean %g", num.Min, num.Max, num.Mean)
+	}
+	for _, q := range []float64{0.01, 0.25, 0.5, 0.9, 0.99} {
+		want := q * 10000
+		if got := num.Quantile(q); got < want-0.01*10000 || got > want+0.01*10000 {
+			t.Errorf("quantile %g = %g, want about %g", q, got, want)
+		}
+	}
+	if got := num.Quantile(0); got != 1 {
+		t.Errorf("quantile 0 = %g", got)
+	}
+
+	bins := num.Histogram(4)
+	var total int64
+	for i, bin := range bins {
+		total += bin.Count
+		if bin.Count < 2400 || bin.Count > 2600 {
+			t.Errorf("bin %d %+v, want about 2500 values", i, bin)
+		}
+	}
+	if total != 10001 || bins[3].High != 10000 {
+		t.Errorf("histogram %+v", bins)
+	}
+}
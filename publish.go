@@ -0,0 +1,217 @@
+package csv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PublishFunc publishes a message with the given key and value, for
+// example to a Kafka topic or a message queue. The key is nil for records
+// without one. It must not retain key or value after returning.
+type PublishFunc func(ctx context.Context, key, value []byte) error
+
+// A PublishFormat is the encoding of the messages of a PublishSink.
+type PublishFormat int
+
+const (
+	PublishCSV  PublishFormat = iota // the record as a CSV line, without terminator
+	PublishJSON                      // a JSON object keyed by the header, or an array
+)
+
+var publishFormatNames = [...]string{"csv", "json"}
+
+func (f PublishFormat) String() string {
+	if f < 0 || int(f) >= len(publishFormatNames) {
+		return "PublishFormat(" + strconv.Itoa(int(f)) + ")"
+	}
+	return publishFormatNames[f]
+}
+
+// PublishSinkOptions configures a PublishSink.
+type PublishSinkOptions struct {
+	// Format is the encoding of the messages.
+	Format PublishFormat
+
+	// KeyColumn is the name of the column whose field is the key of each
+	// message. Messages have no key if it is empty.
+	KeyColumn string
+
+	// BatchSize is the number of records buffered before they are
+	// published. It defaults to 100.
+	BatchSize int
+
+	// Concurrency is the number of messages of a batch published at the
+	// same time. Order is only kept per key: messages with the same key
+	// are published one after another, in the order of their records, and
+	// messages without a key are spread over the publishers in turn. It
+	// defaults to 1.
+	Concurrency int
+
+	// Retries is the number of times a failed publish is retried, after
+	// Backoff, doubled on each retry. Backoff defaults to 100ms.
+	Retries int
+	Backoff time.Duration
+
+	// Context is passed to Publish and stops retries when it is done. It
+	// defaults to context.Background().
+	Context context.Context
+}
+
+// A PublishSink is a Sink publishing each record as a message with a
+// PublishFunc, in batches. A batch is published when it is full and when
+// the sink is closed, and the first error of a batch is returned.
+type PublishSink struct {
+	publish PublishFunc
+	opts    PublishSinkOptions
+	key     int // index of KeyColumn, or -1
+	keyed   bool
+	enc     *Encoder
+	buf     bytes.Buffer
+	batch   []publishMessage
+}
+
+type publishMessage struct {
+	key, value []byte
+}
+
+// NewPublishSink returns a sink publishing records with publish.
+func NewPublishSink(publish PublishFunc, opts PublishSinkOptions) *PublishSink {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 100 * time.Millisecond
+	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+	s := &PublishSink{publish: publish, opts: opts, key: -1}
+	s.enc = NewEncoder(&s.buf)
+	return s
+}
+
+func (s *PublishSink) Write(r Record) error {
+	if !s.keyed && s.opts.KeyColumn != "" {
+		if s.key = r.Index(s.opts.KeyColumn); s.key < 0 {
+			return fmt.Errorf("csv: no key column %q", s.opts.KeyColumn)
+		}
+	}
+	s.keyed = true
+
+	s.buf.Reset()
+	switch s.opts.Format {
+	case PublishJSON:
+		if h := r.Header(); h != nil {
+			writeJSONObject(&s.buf, h, func(i int) string { return fieldAt(r.Fields, i) })
+		} else {
+			b, err := json.Marshal(r.Fields)
+			if err != nil {
+				return err
+			}
+			s.buf.Write(b)
+		}
+	default:
+		if err := s.enc.Encode(r.Fields); err != nil {
+			return err
+		}
+		if err := s.enc.Flush(); err != nil {
+			return err
+		}
+		s.buf.Truncate(s.buf.Len() - 1) // the line terminator
+	}
+	m := publishMessage{value: append([]byte(nil), s.buf.Bytes()...)}
+	if s.key >= 0 {
+		m.key = []byte(fieldAt(r.Fields, s.key))
+	}
+	s.batch = append(s.batch, m)
+	if len(s.batch) >= s.opts.BatchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush publishes the buffered messages, split by key among Concurrency
+// goroutines, or in turn without a key column.
+func (s *PublishSink) flush() error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+	batch := s.batch
+	s.batch = nil
+	if s.opts.Concurrency == 1 {
+		return s.publishAll(batch)
+	}
+	parts := make([][]publishMessage, s.opts.Concurrency)
+	for j, m := range batch {
+		i := j % len(parts)
+		if s.key >= 0 {
+			h := fnv.New32a()
+			h.Write(m.key)
+			i = int(h.Sum32() % uint32(len(parts)))
+		}
+		parts[i] = append(parts[i], m)
+	}
+	errs := make([]error, len(parts))
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, part []publishMessage) {
+			defer wg.Done()
+			errs[i] = s.publishAll(part)
+		}(i, part)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishAll publishes messages in order, stopping at the first one that
+// fails after its retries.
+func (s *PublishSink) publishAll(messages []publishMessage) error {
+	for _, m := range messages {
+		if err := s.publishRetry(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PublishSink) publishRetry(m publishMessage) error {
+	ctx := s.opts.Context
+	backoff := s.opts.Backoff
+	for retry := 0; ; retry++ {
+		err := s.publish(ctx, m.key, m.value)
+		if err == nil || retry >= s.opts.Retries {
+			return err
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		backoff *= 2
+	}
+}
+
+// Close publishes the records still buffered.
+func (s *PublishSink) Close() error {
+	return s.flush()
+}
@@ -0,0 +1,120 @@
+package csv
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testBroker struct {
+	mu       sync.Mutex
+	messages map[string][]string // values by key
+	fails    int                 // publishes to fail before succeeding
+	calls    int
+}
+
+func (b *testBroker) publish(ctx context.Context, key, value []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls++
+	if b.fails > 0 {
+		b.fails--
+		return errors.New("broker unavailable")
+	}
+	if b.messages == nil {
+		b.messages = make(map[string][]string)
+	}
+	b.messages[string(key)] = append(b.messages[string(key)], string(value))
+	return nil
+}
+
+func TestPublishSink(t *testing.T) {
+	header := []string{"user", "event"}
+	records := [][]string{{"ann", "login"}, {"bob", "login"}, {"ann", "a,b"}, {"cid", "x"}, {"ann", "logout"}}
+	for _, tt := range []struct {
+		format PublishFormat
+		ann    []string
+	}{
+		{PublishCSV, []string{"ann,login", `ann,"a,b"`, "ann,logout"}},
+		{PublishJSON, []string{`{"user":"ann","event":"login"}`, `{"user":"ann","event":"a,b"}`, `{"user":"ann","event":"logout"}`}},
+	} {
+		b := new(testBroker)
+		s := NewPublishSink(b.publish, PublishSinkOptions{
+			Format:      tt.format,
+			KeyColumn:   "user",
+			BatchSize:   2,
+			Concurrency: 3,
+		})
+		for _, r := range records {
+			if err := s.Write(NewRecord(header, r)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.messages["ann"]; !reflect.DeepEqual(got, tt.ann) {
+			t.Errorf("%v: messages for ann %q, want %q", tt.format, got, tt.ann)
+		}
+		if len(b.messages) != 3 {
+			t.Errorf("%v: %d keys, want 3", tt.format, len(b.messages))
+		}
+	}
+}
+
+func TestPublishSinkRetry(t *testing.T) {
+	b := &testBroker{fails: 2}
+	s := NewPublishSink(b.publish, PublishSinkOptions{Retries: 2, Backoff: time.Millisecond})
+	if err := s.Write(NewRecord(nil, []string{"a"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b.calls != 3 || len(b.messages[""]) != 1 {
+		t.Errorf("%d calls, messages %q", b.calls, b.messages)
+	}
+
+	b = &testBroker{fails: 2}
+	s = NewPublishSink(b.publish, PublishSinkOptions{Retries: 1, Backoff: time.Millisecond})
+	s.Write(NewRecord(nil, []string{"a"}))
+	if err := s.Close(); err == nil {
+		t.Error("Close() succeeded after retries ran out")
+	}
+}
+
+func TestPublishSinkNoKeyColumn(t *testing.T) {
+	s := NewPublishSink(new(testBroker).publish, PublishSinkOptions{KeyColumn: "id"})
+	if err := s.Write(NewRecord([]string{"name"}, []string{"x"})); err == nil {
+		t.Error("Write() succeeded without the key column")
+	}
+}
+
+func TestPublishSinkUnkeyedConcurrency(t *testing.T) {
+	// each publish waits for the other, so they must run at the same time
+	var started sync.WaitGroup
+	started.Add(2)
+	publish := func(ctx context.Context, key, value []byte) error {
+		started.Done()
+		done := make(chan struct{})
+		go func() { started.Wait(); close(done) }()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("published one at a time")
+		}
+	}
+	s := NewPublishSink(publish, PublishSinkOptions{BatchSize: 2, Concurrency: 2})
+	for _, v := range []string{"a", "b"} {
+		if err := s.Write(NewRecord(nil, []string{v})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
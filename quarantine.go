@@ -0,0 +1,80 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// nextQuarantined reads the next record like nextRecord. If the decoder
+// has a Quarantine, records rejected with a ParseError are written to it
+// and skipped, and the field count is checked here so that records with
+// the wrong number of fields are quarantined too.
+func (d *Decoder) nextQuarantined() error {
+	if d.Quarantine == nil {
+		return d.nextRecord()
+	}
+	for {
+		err := d.nextRecord()
+		if err == nil {
+			err = d.checkFieldCount(len(d.fieldIndexes))
+		}
+		if err == nil {
+			return nil
+		}
+		if quarantined, qerr := d.quarantine(err); !quarantined {
+			return qerr
+		}
+	}
+}
+
+// quarantine writes the record rejected with err to Quarantine and clears
+// the error, reporting whether it did. Errors other than ParseErrors about
+// the record just read are returned unchanged, as is ErrTrailer, which is
+// about the stream as a whole, and an error writing to Quarantine.
+func (d *Decoder) quarantine(err error) (bool, error) {
+	var perr *ParseError
+	if !errors.As(err, &perr) || !d.inRecord || errors.Is(err, ErrTrailer) {
+		return false, err
+	}
+	raw := d.raw
+	if raw == nil {
+		// a syntax error: skip to the end of its line
+		raw = d.resync(perr.Offset)
+		d.lineBuffer.Reset()
+		d.fieldIndexes = d.fieldIndexes[:0]
+	}
+	d.err = nil
+	if d.QuarantineReason {
+		raw = d.appendReason(raw, err)
+	}
+	if _, werr := d.Quarantine.Write(raw); werr != nil {
+		d.err = werr
+		return false, werr
+	}
+	if d.Logger != nil {
+		d.Logger.Warn("csv: record quarantined", "record", perr.Record, "error", err)
+	}
+	if d.Metrics != nil {
+		d.Metrics.Error(err)
+	}
+	return true, nil
+}
+
+// appendReason returns a copy of the record raw with err appended as a
+// quoted field before its line terminator.
+func (d *Decoder) appendReason(raw []byte, err error) []byte {
+	body, eol := raw, "\n"
+	switch {
+	case bytes.HasSuffix(raw, []byte("\r\n")):
+		body, eol = raw[:len(raw)-2], "\r\n"
+	case len(raw) > 0 && (raw[len(raw)-1] == '\n' || raw[len(raw)-1] == '\r'):
+		body, eol = raw[:len(raw)-1], string(raw[len(raw)-1:])
+	}
+	out := make([]byte, 0, len(raw)+len(err.Error())+4)
+	out = append(out, body...)
+	out = append(out, d.scan.Delimiter, '"')
+	out = append(out, strings.Replace(err.Error(), `"`, `""`, -1)...)
+	out = append(out, '"')
+	return append(out, eol...)
+}
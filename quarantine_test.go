@@ -0,0 +1,100 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestQuarantine(t *testing.T) {
+	in := "id,name\n1,ann\n2,\"bo\"b,x\n3\n4,\"multi\nline\",z\r\n5,eve\n"
+	for _, size := range []int{1, 4096} {
+		var rejects bytes.Buffer
+		dec := NewDecoder(strings.NewReader(in))
+		dec.SetBufferSize(size)
+		dec.Quarantine = &rejects
+		var got []string
+		for {
+			fields, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("size %d: %v", size, err)
+			}
+			got = append(got, fields[0])
+		}
+		if want := []string{"id", "1", "5"}; strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("size %d: records %q, want %q", size, got, want)
+		}
+		if want := "2,\"bo\"b,x\n3\n4,\"multi\nline\",z\r\n"; rejects.String() != want {
+			t.Errorf("size %d: quarantined %q, want %q", size, rejects.String(), want)
+		}
+		if n := dec.RecordNumber(); n != 6 {
+			t.Errorf("size %d: RecordNumber() = %d, want 6", size, n)
+		}
+	}
+}
+
+func TestQuarantineReason(t *testing.T) {
+	var rejects bytes.Buffer
+	dec := NewDecoder(strings.NewReader("a,b\n1,2,3\r\n4,5\n"))
+	dec.Quarantine = &rejects
+	dec.QuarantineReason = true
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	if fields, err := dec.Decode(); err != nil || fields[0] != "4" {
+		t.Fatalf("Decode() = %q, %v", fields, err)
+	}
+	if !strings.HasSuffix(rejects.String(), "\"\r\n") {
+		t.Errorf("quarantined %q, want the reason before the line terminator", rejects.String())
+	}
+	r := NewDecoder(&rejects)
+	fields, err := r.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 4 || !strings.Contains(fields[3], ErrFieldCount.Error()) {
+		t.Errorf("quarantined record %q", fields)
+	}
+}
+
+func TestQuarantineWriteError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a,b\n1\n"))
+	dec.Quarantine = failWriter{errors.New("disk full")}
+	dec.Decode()
+	if _, err := dec.Decode(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Decode() error %v, want disk full", err)
+	}
+}
+
+func TestQuarantineTrailer(t *testing.T) {
+	var rejects bytes.Buffer
+	dec := NewDecoder(strings.NewReader("a,b\n1,2\nT,2\n3,4\n5,6\n"))
+	dec.Trailer = &Trailer{Tag: "T", CountField: 1}
+	dec.Quarantine = &rejects
+	var got []string
+	var err error
+	for {
+		var fields []string
+		if fields, err = dec.Decode(); err != nil {
+			break
+		}
+		got = append(got, strings.Join(fields, "|"))
+	}
+	if want := "a|b 1|2"; strings.Join(got, " ") != want {
+		t.Errorf("records %q, want %s", got, want)
+	}
+	if !errors.Is(err, ErrTrailer) {
+		t.Errorf("error %v, want %v", err, ErrTrailer)
+	}
+	if _, err := dec.Decode(); !errors.Is(err, ErrTrailer) {
+		t.Errorf("error %v after a trailer error, want %v", err, ErrTrailer)
+	}
+	if rejects.Len() != 0 {
+		t.Errorf("quarantined %q", rejects.String())
+	}
+}
@@ -0,0 +1,33 @@
+package csv
+
+import "bytes"
+
+// shapeRecord applies AllowTrailingDelimiter, PadShortRows and
+// TruncateLongRows to the current record.
+func (d *Decoder) shapeRecord() {
+	if d.AllowTrailingDelimiter && len(d.fieldIndexes) > 1 {
+		raw := bytes.TrimSuffix(d.raw, []byte{'\n'})
+		raw = bytes.TrimSuffix(raw, []byte{'\r'})
+		if len(raw) > 0 && raw[len(raw)-1] == d.scan.Delimiter {
+			last := len(d.fieldIndexes) - 1
+			d.lineBuffer.Truncate(d.fieldIndexes[last])
+			d.fieldIndexes = d.fieldIndexes[:last]
+		}
+	}
+
+	n := d.FieldsPerRecord
+	if n <= 0 {
+		return
+	}
+	switch {
+	case d.PadShortRows && len(d.fieldIndexes) < n:
+		d.debug("csv: short record padded", "fields", len(d.fieldIndexes))
+		for len(d.fieldIndexes) < n {
+			d.fieldIndexes = append(d.fieldIndexes, d.lineBuffer.Len())
+		}
+	case d.TruncateLongRows && len(d.fieldIndexes) > n:
+		d.debug("csv: long record truncated", "fields", len(d.fieldIndexes))
+		d.lineBuffer.Truncate(d.fieldIndexes[n])
+		d.fieldIndexes = d.fieldIndexes[:n]
+	}
+}
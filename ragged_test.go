@@ -0,0 +1,99 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var raggedTests = []struct {
+	Name                   string
+	Input                  string
+	FieldsPerRecord        int
+	AllowTrailingDelimiter bool
+	PadShortRows           bool
+	TruncateLongRows       bool
+	Output                 [][]string
+	Error                  error
+}{
+	{
+		Name:                   "TrailingDelimiter",
+		Input:                  "a,b,\nc,d,\r\ne,f,",
+		AllowTrailingDelimiter: true,
+		Output:                 [][]string{{"a", "b"}, {"c", "d"}, {"e", "f"}},
+	},
+	{
+		Name:                   "TrailingDelimiterQuoted",
+		Input:                  "a,\"b,\"\n",
+		AllowTrailingDelimiter: true,
+		Output:                 [][]string{{"a", "b,"}},
+	},
+	{
+		Name:                   "TrailingDelimiterOnly",
+		Input:                  ",\n",
+		AllowTrailingDelimiter: true,
+		Output:                 [][]string{{""}},
+	},
+	{
+		Name:            "NoTrailingDelimiter",
+		Input:           "a,b,\n",
+		FieldsPerRecord: -1,
+		Output:          [][]string{{"a", "b", ""}},
+	},
+	{
+		Name:         "PadShortRows",
+		Input:        "a,b,c\nd\ne,f\n",
+		PadShortRows: true,
+		Output:       [][]string{{"a", "b", "c"}, {"d", "", ""}, {"e", "f", ""}},
+	},
+	{
+		Name:             "TruncateLongRows",
+		Input:            "a,b\nc,d,e,f\n",
+		TruncateLongRows: true,
+		Output:           [][]string{{"a", "b"}, {"c", "d"}},
+	},
+	{
+		Name:             "PadAndTruncate",
+		Input:            "a,b,c\nd\ne,f,g,h\n",
+		FieldsPerRecord:  3,
+		PadShortRows:     true,
+		TruncateLongRows: true,
+		Output:           [][]string{{"a", "b", "c"}, {"d", "", ""}, {"e", "f", "g"}},
+	},
+	{
+		Name:             "TruncateOnlyShort",
+		Input:            "a,b,c\nd\n",
+		TruncateLongRows: true,
+		Output:           [][]string{{"a", "b", "c"}},
+		Error:            ErrFieldCount,
+	},
+}
+
+func TestRagged(t *testing.T) {
+	for _, tt := range raggedTests {
+		dec := NewDecoder(strings.NewReader(tt.Input))
+		dec.FieldsPerRecord = tt.FieldsPerRecord
+		dec.AllowTrailingDelimiter = tt.AllowTrailingDelimiter
+		dec.PadShortRows = tt.PadShortRows
+		dec.TruncateLongRows = tt.TruncateLongRows
+
+		var out [][]string
+		var err error
+		for dec.More() {
+			var record []string
+			if record, err = dec.Decode(); err != nil {
+				break
+			}
+			out = append(out, record)
+		}
+		if perr, ok := err.(*ParseError); ok {
+			err = perr.Err
+		}
+		if err != tt.Error {
+			t.Errorf("%s: error %v, want %v", tt.Name, err, tt.Error)
+		}
+		if !reflect.DeepEqual(out, tt.Output) {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, tt.Output)
+		}
+	}
+}
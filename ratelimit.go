@@ -0,0 +1,122 @@
+package csv
+
+import (
+	"context"
+	"time"
+)
+
+// A RateLimit bounds the rate at which records are read, to avoid
+// overwhelming downstream systems when replaying a stream. A zero field
+// sets no limit.
+type RateLimit struct {
+	Records float64 // records per second
+	Bytes   float64 // bytes per second
+}
+
+// A rateLimiter applies a RateLimit with a token bucket for each of its
+// limits. Each bucket holds up to one second of tokens, so short bursts
+// are allowed after idle periods.
+type rateLimiter struct {
+	records, bytes *tokenBucket
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	return &rateLimiter{
+		records: newTokenBucket(limit.Records),
+		bytes:   newTokenBucket(limit.Bytes),
+	}
+}
+
+// wait blocks until a record of n bytes may be passed on, or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	if err := l.records.wait(ctx, 1); err != nil {
+		return err
+	}
+	return l.bytes.wait(ctx, float64(n))
+}
+
+type tokenBucket struct {
+	rate   float64 // tokens added per second
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a bucket filled at rate, or nil if rate is not
+// positive.
+func newTokenBucket(rate float64) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{rate: rate}
+}
+
+// wait takes n tokens from the bucket, blocking until they are available
+// or ctx is done. A request larger than the bucket leaves it in debt, so
+// it delays the requests after it. A nil bucket never blocks.
+func (b *tokenBucket) wait(ctx context.Context, n float64) error {
+	if b == nil {
+		return nil
+	}
+	now := time.Now()
+	burst := b.rate
+	if burst < 1 {
+		burst = 1
+	}
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else if b.tokens += now.Sub(b.last).Seconds() * b.rate; b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		b.tokens += n // the tokens were not used
+		return ctx.Err()
+	}
+}
+
+// throttle waits for the decoder's RateLimit after a record was read.
+// The end of Context stops the decoder.
+func (d *Decoder) throttle(err error) error {
+	if err != nil || d.RateLimit == (RateLimit{}) {
+		return err
+	}
+	if d.limiter == nil {
+		d.limiter = newRateLimiter(d.RateLimit)
+	}
+	ctx := d.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := d.limiter.wait(ctx, len(d.raw)+int(d.spilled)); err != nil {
+		d.err = err
+		return err
+	}
+	return nil
+}
+
+// RateLimit limits the rate at which records leave the source, waiting
+// while the pipeline runs. The bytes of a record are the bytes of its
+// fields.
+func (p *Pipeline) RateLimit(limit RateLimit) *Pipeline {
+	p.limit = limit
+	return p
+}
+
+// fieldBytes returns the number of bytes of the fields of record.
+func fieldBytes(record []string) int {
+	n := 0
+	for _, field := range record {
+		n += len(field)
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+package csv
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoderRateLimit(t *testing.T) {
+	tests := []struct {
+		limit RateLimit
+		min   time.Duration
+	}{
+		{RateLimit{}, 0},
+		{RateLimit{Records: 1000}, 0},
+		// a burst of 20 records, then 50ms per record
+		{RateLimit{Records: 20}, 450 * time.Millisecond},
+		// 4 bytes per record: a burst of 20 records, then 50ms per record
+		{RateLimit{Bytes: 80}, 450 * time.Millisecond},
+	}
+	in := strings.Repeat("a,b\n", 30)
+	for _, tt := range tests {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.RateLimit = tt.limit
+		start := time.Now()
+		n := 0
+		for dec.More() {
+			if _, err := dec.Decode(); err != nil {
+				t.Fatal(err)
+			}
+			n++
+		}
+		if n != 30 {
+			t.Errorf("%+v: %d records, want 30", tt.limit, n)
+		}
+		if elapsed := time.Since(start); elapsed < tt.min {
+			t.Errorf("%+v: took %v, want at least %v", tt.limit, elapsed, tt.min)
+		}
+	}
+}
+
+func TestDecoderRateLimitContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	dec := NewDecoder(strings.NewReader(strings.Repeat("a\n", 10)))
+	dec.RateLimit = RateLimit{Records: 1}
+	dec.Context = ctx
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := dec.Decode(); err != context.DeadlineExceeded {
+		t.Fatalf("error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second/2 {
+		t.Errorf("waited %v after the context was done", elapsed)
+	}
+	if _, err := dec.Decode(); err != context.DeadlineExceeded {
+		t.Errorf("error %v after stop, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestPipelineRateLimit(t *testing.T) {
+	sink := &testSink{}
+	start := time.Now()
+	err := NewPipeline(&testSource{n: 12}).RateLimit(RateLimit{Records: 100}).To(sink).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.records) != 12 {
+		t.Errorf("%d records, want 12", len(sink.records))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	sink = &testSink{}
+	err = NewPipeline(&testSource{n: 100}).RateLimit(RateLimit{Records: 2}).To(sink).Run(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("took %v", elapsed)
+	}
+}
@@ -0,0 +1,23 @@
+package csv
+
+// Raw returns the exact bytes of the record most recently read, including
+// its line terminator, whether it was returned or rejected with an error
+// other than a syntax error, for which Raw returns nil. The bytes are only
+// valid until the next record is read. If LargeFieldThreshold dropped the
+// start of a long record from the input buffer, Raw returns only its end;
+// set Passthrough to keep records whole.
+func (d *Decoder) Raw() []byte {
+	return d.raw
+}
+
+// passthrough writes the current record to Passthrough.
+func (d *Decoder) passthrough() error {
+	if d.Passthrough == nil {
+		return nil
+	}
+	if _, err := d.Passthrough.Write(d.raw); err != nil {
+		d.err = err
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRaw(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("id,note\r\n1,\"a\r\nb\"\r\n2,x,extra\n"))
+	for _, tt := range []struct {
+		raw string
+		err bool
+	}{
+		{"id,note\r\n", false},
+		{"1,\"a\r\nb\"\r\n", false},
+		{"2,x,extra\n", true},
+	} {
+		_, err := dec.Decode()
+		if (err != nil) != tt.err || string(dec.Raw()) != tt.raw {
+			t.Errorf("Raw() = %q, error %v, want %q", dec.Raw(), err, tt.raw)
+		}
+	}
+
+	dec = NewDecoder(strings.NewReader("a\n\"b\"x\n"))
+	dec.Decode()
+	if _, err := dec.Decode(); err == nil || dec.Raw() != nil {
+		t.Errorf("Raw() = %q, error %v after a syntax error", dec.Raw(), err)
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	big := strings.Repeat("y", 10000)
+	in := "# comment\nid,note\r\n\n1,keep\n2,\"" + big + "\"\n3,drop\n4,\"a\nb\""
+	var audit bytes.Buffer
+	dec := NewDecoderDialect(strings.NewReader(in), Dialect{Comment: '#'})
+	dec.Passthrough = &audit
+	dec.LargeFieldThreshold = 100
+	dec.SetBufferSize(64)
+	dec.Filter(func(r Record) bool { return r.Fields[1] != "drop" })
+	n := 0
+	for dec.More() {
+		if _, err := dec.Decode(); err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 4 {
+		t.Errorf("%d records, want 4", n)
+	}
+	if got, want := audit.String(), "id,note\r\n1,keep\n2,\""+big+"\"\n3,drop\n4,\"a\nb\""; got != want {
+		t.Errorf("passthrough %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package csv
+
+import (
+	"io"
+	"os"
+)
+
+// An Option configures a Decoder created by one of the helper functions
+// such as ReadAll. Any function modifying a *Decoder can be used.
+type Option func(*Decoder)
+
+// WithDialect sets the dialect of the decoder.
+func WithDialect(dialect Dialect) Option {
+	return func(d *Decoder) { d.SetDialect(dialect) }
+}
+
+// WithFieldsPerRecord sets the FieldsPerRecord of the decoder.
+func WithFieldsPerRecord(n int) Option {
+	return func(d *Decoder) { d.FieldsPerRecord = n }
+}
+
+// WithBufferSize sets the buffer size of the decoder.
+func WithBufferSize(n int) Option {
+	return func(d *Decoder) { d.SetBufferSize(n) }
+}
+
+// newDecoder returns a decoder reading from r configured by opts.
+func newDecoder(r io.Reader, opts []Option) *Decoder {
+	d := NewDecoder(r)
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// ForEach decodes every record read from r and calls fn with it. It stops
+// at the first error returned by the decoder or by fn and returns it.
+func ForEach(r io.Reader, fn func([]string) error, opts ...Option) error {
+	d := newDecoder(r, opts)
+	defer d.Close()
+	for d.More() {
+		record, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadAll decodes all the records read from r.
+func ReadAll(r io.Reader, opts ...Option) ([][]string, error) {
+	var records [][]string
+	err := ForEach(r, func(record []string) error {
+		records = append(records, record)
+		return nil
+	}, opts...)
+	return records, err
+}
+
+// ReadFile decodes all the records of the named file.
+func ReadFile(path string, opts ...Option) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadAll(f, opts...)
+}
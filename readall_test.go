@@ -0,0 +1,49 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	records, err := ReadAll(strings.NewReader("a;b\nc;d;e\n"),
+		WithDialect(Dialect{Delimiter: ';'}), WithFieldsPerRecord(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"a", "b"}, {"c", "d", "e"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("records %q, want %q", records, want)
+	}
+
+	_, err = ReadAll(strings.NewReader("a,b\nc,d,e\n"))
+	if perr, ok := err.(*ParseError); !ok || perr.Err != ErrFieldCount {
+		t.Errorf("error %v, want %v", err, ErrFieldCount)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	records, err := ReadFile("sample.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) < 2 || records[0][0] != "lahmanID" || records[1][20] != "Hammer,Hammerin' Hank,Bad Henry" {
+		t.Errorf("unexpected records %q", records[:2])
+	}
+}
+
+func TestForEachStops(t *testing.T) {
+	errStop := errors.New("stop")
+	n := 0
+	err := ForEach(strings.NewReader("1\n2\n3\n"), func([]string) error {
+		n++
+		if n == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || n != 2 {
+		t.Errorf("error %v after %d records, want %v after 2", err, n, errStop)
+	}
+}
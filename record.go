@@ -0,0 +1,70 @@
+package csv
+
+// A Record is a decoded record together with the header of its stream, so
+// fields can be accessed by column name.
+type Record struct {
+	Fields []string
+
+	header []string
+	index  map[string]int
+
+	// pool and arena holding the fields of a record decoded with UsePool
+	pool  *RecordPool
+	arena *recordArena
+}
+
+// NewRecord returns a record with the given header and fields.
+func NewRecord(header, fields []string) Record {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+	return Record{Fields: fields, header: header, index: index}
+}
+
+// Header returns the header of the stream the record was read from, or nil
+// if the stream has no header.
+func (r Record) Header() []string {
+	return r.header
+}
+
+// Len returns the number of fields of the record.
+func (r Record) Len() int {
+	return len(r.Fields)
+}
+
+// Index returns the index of the column called name, or -1 if there is no
+// such column.
+func (r Record) Index(name string) int {
+	if i, ok := r.index[name]; ok {
+		return i
+	}
+	return -1
+}
+
+// Get returns the field of the column called name. It reports false if
+// there is no such column or the record is too short.
+func (r Record) Get(name string) (string, bool) {
+	i := r.Index(name)
+	if i < 0 || i >= len(r.Fields) {
+		return "", false
+	}
+	return r.Fields[i], true
+}
+
+// DecodeRecord reads the next record from the input and returns it with
+// the header read by ReadHeader. If a pool was set with UsePool the record
+// is allocated from it.
+func (d *Decoder) DecodeRecord() (Record, error) {
+	if d.pool == nil {
+		fields, err := d.Decode()
+		return Record{Fields: fields, header: d.header, index: d.headerIndex}, err
+	}
+	if err := d.next(); err != nil {
+		return Record{}, err
+	}
+	r := d.pooledRecord()
+	return r, d.checkFieldCount(r.Len())
+}
@@ -0,0 +1,72 @@
+package csv
+
+import (
+	"regexp"
+	"sync/atomic"
+)
+
+// A Replacer is a transform substituting the matches of a regular
+// expression in selected columns, as returned by Replace.
+type Replacer struct {
+	re      *regexp.Regexp
+	repl    string
+	columns []string
+	cols    []int
+	count   int64
+}
+
+// Replace returns a transform replacing the matches of re with repl in the
+// columns called columns, or in every column if none are given. Inside
+// repl, $ signs are interpreted as in regexp.Regexp.Expand.
+func Replace(re *regexp.Regexp, repl string, columns ...string) *Replacer {
+	return &Replacer{re: re, repl: repl, columns: columns}
+}
+
+// Count returns the number of replacements performed so far.
+func (t *Replacer) Count() int64 {
+	return atomic.LoadInt64(&t.count)
+}
+
+func (t *Replacer) Header(header []string) ([]string, error) {
+	t.cols = nil
+	if len(t.columns) > 0 {
+		cols, err := columnIndexes(header, t.columns)
+		if err != nil {
+			return nil, err
+		}
+		t.cols = cols
+	}
+	return header, nil
+}
+
+func (t *Replacer) Apply(r Record) ([]string, error) {
+	if t.cols == nil {
+		for i := range r.Fields {
+			r.Fields[i] = t.replace(r.Fields[i])
+		}
+		return r.Fields, nil
+	}
+	for _, col := range t.cols {
+		if col < len(r.Fields) {
+			r.Fields[col] = t.replace(r.Fields[col])
+		}
+	}
+	return r.Fields, nil
+}
+
+func (t *Replacer) replace(field string) string {
+	matches := t.re.FindAllStringSubmatchIndex(field, -1)
+	if len(matches) == 0 {
+		return field
+	}
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		out = append(out, field[last:m[0]]...)
+		out = t.re.ExpandString(out, t.repl, field, m)
+		last = m[1]
+	}
+	out = append(out, field[last:]...)
+	atomic.AddInt64(&t.count, int64(len(matches)))
+	return string(out)
+}
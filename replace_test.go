@@ -0,0 +1,38 @@
+package csv
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	in := "name,phone,note\nAnn,555-1234,\"call 555-9999, later\"\nBob,none,-\n"
+	phone := Replace(regexp.MustCompile(`(\d{3})-(\d{4})`), "($1) $2", "phone")
+	dash := Replace(regexp.MustCompile(`^-$`), "")
+	var out bytes.Buffer
+	if err := Reencode(strings.NewReader(in), &out, ReencodeOptions{}, phone, dash); err != nil {
+		t.Fatal(err)
+	}
+	want := "name,phone,note\nAnn,(555) 1234,\"call 555-9999, later\"\nBob,none,\n"
+	if got := out.String(); got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+	if n := phone.Count(); n != 1 {
+		t.Errorf("phone replacements %d, want 1", n)
+	}
+	if n := dash.Count(); n != 1 {
+		t.Errorf("dash replacements %d, want 1", n)
+	}
+}
+
+func TestReplaceAllMatches(t *testing.T) {
+	r := Replace(regexp.MustCompile(`a`), "b")
+	if got := r.replace("banana"); got != "bbnbnb" {
+		t.Errorf("replace = %q", got)
+	}
+	if n := r.Count(); n != 3 {
+		t.Errorf("Count() = %d, want 3", n)
+	}
+}
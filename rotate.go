@@ -0,0 +1,133 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// RotateOptions configures a RotatingEncoder.
+type RotateOptions struct {
+	// Records, if positive, is the maximum number of records per part,
+	// not counting the header.
+	Records int
+	// Bytes, if positive, is the size in bytes after which a part is
+	// closed. A part may exceed it by one record.
+	Bytes int64
+
+	// Pattern is the fmt pattern naming the parts from their 1-based
+	// index. It defaults to "part-%04d.csv".
+	Pattern string
+
+	// Header, if not nil, is written at the start of every part.
+	Header []string
+
+	// Delimiter is the field delimiter of the parts. It is comma (',')
+	// when 0.
+	Delimiter byte
+}
+
+// A RotatingEncoder writes records to a sequence of files in a directory,
+// starting a new part whenever the current one reaches the record count or
+// byte size limit.
+type RotatingEncoder struct {
+	dir   string
+	opts  RotateOptions
+	parts []string
+
+	f       *os.File
+	enc     *Encoder
+	count   *countingWriter
+	records int
+}
+
+// NewRotatingEncoder returns an encoder writing parts to dir. No file is
+// created until the first record is encoded.
+func NewRotatingEncoder(dir string, opts RotateOptions) *RotatingEncoder {
+	if opts.Pattern == "" {
+		opts.Pattern = "part-%04d.csv"
+	}
+	return &RotatingEncoder{dir: dir, opts: opts}
+}
+
+// Encode writes record to the current part, first rotating to a new part
+// if the current one is full.
+func (e *RotatingEncoder) Encode(record []string) error {
+	if e.enc == nil || e.full() {
+		if err := e.rotate(); err != nil {
+			return err
+		}
+	}
+	if err := e.enc.Encode(record); err != nil {
+		return err
+	}
+	e.records++
+	return nil
+}
+
+// Parts returns the paths of the parts written so far.
+func (e *RotatingEncoder) Parts() []string {
+	return e.parts
+}
+
+// Close flushes and closes the current part.
+func (e *RotatingEncoder) Close() error {
+	if e.f == nil {
+		return nil
+	}
+	err := e.enc.Flush()
+	if cerr := e.f.Close(); err == nil {
+		err = cerr
+	}
+	e.f, e.enc = nil, nil
+	return err
+}
+
+// full reports whether the current part has reached a limit.
+func (e *RotatingEncoder) full() bool {
+	if e.opts.Records > 0 && e.records >= e.opts.Records {
+		return true
+	}
+	if e.opts.Bytes > 0 {
+		// Account for records still buffered by the encoder.
+		return e.count.n+int64(e.enc.w.Buffered()) >= e.opts.Bytes
+	}
+	return false
+}
+
+// rotate closes the current part and opens the next one.
+func (e *RotatingEncoder) rotate() error {
+	if err := e.Close(); err != nil {
+		return err
+	}
+	path := filepath.Join(e.dir, fmt.Sprintf(e.opts.Pattern, len(e.parts)+1))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	e.parts = append(e.parts, path)
+	e.f = f
+	e.count = &countingWriter{w: f}
+	e.enc = NewEncoder(e.count)
+	if e.opts.Delimiter != 0 {
+		e.enc.Delimiter = e.opts.Delimiter
+	}
+	e.records = 0
+	if e.opts.Header != nil {
+		return e.enc.Encode(e.opts.Header)
+	}
+	return nil
+}
+
+// A countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
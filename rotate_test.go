@@ -0,0 +1,59 @@
+package csv
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingEncoder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "csv-rotate-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tt := range []struct {
+		Name  string
+		Opts  RotateOptions
+		Parts []string
+	}{
+		{
+			Name:  "Records",
+			Opts:  RotateOptions{Records: 2, Header: []string{"n"}, Pattern: "r-%d.csv"},
+			Parts: []string{"n\n1\n2\n", "n\n3\n4\n", "n\n5\n"},
+		},
+		{
+			Name:  "Bytes",
+			Opts:  RotateOptions{Bytes: 6, Header: []string{"n"}, Pattern: "b-%d.csv"},
+			Parts: []string{"n\n1\n2\n", "n\n3\n4\n", "n\n5\n"},
+		},
+	} {
+		enc := NewRotatingEncoder(dir, tt.Opts)
+		for _, n := range []string{"1", "2", "3", "4", "5"} {
+			if err := enc.Encode([]string{n}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		parts := enc.Parts()
+		if len(parts) != len(tt.Parts) {
+			t.Errorf("%s: got %d parts, want %d", tt.Name, len(parts), len(tt.Parts))
+			continue
+		}
+		for i, path := range parts {
+			if want := filepath.Join(dir, fmt.Sprintf(tt.Opts.Pattern, i+1)); path != want {
+				t.Errorf("%s: part %d is %q, want %q", tt.Name, i, path, want)
+			}
+			b, _ := ioutil.ReadFile(path)
+			if string(b) != tt.Parts[i] {
+				t.Errorf("%s: part %d content=%q want %q", tt.Name, i, b, tt.Parts[i])
+			}
+		}
+	}
+}
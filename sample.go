@@ -0,0 +1,65 @@
+package csv
+
+import (
+	"io"
+	"math/rand"
+	"sort"
+)
+
+// Sample makes the decoder return only every n'th record, skipping the
+// others without converting them. Records rejected by Filter and the
+// header read by ReadHeader are not counted. A value of n below 2
+// disables sampling.
+func (d *Decoder) Sample(n int) {
+	d.sampleEvery = n
+	d.sampleSeen = 0
+}
+
+// sampled reports whether the current record is kept by Sample.
+func (d *Decoder) sampled() bool {
+	if d.sampleEvery < 2 || d.inHeader {
+		return true
+	}
+	d.sampleSeen++
+	return d.sampleSeen%d.sampleEvery == 0
+}
+
+// ReservoirSample reads all records from r and returns a uniform random
+// sample of k of them, in input order, using reservoir sampling so that
+// memory use is bounded by k regardless of the size of the input. The
+// first record is a header and is returned separately; it is never part
+// of the sample.
+func ReservoirSample(r io.Reader, k int) (header []string, sample [][]string, err error) {
+	dec := NewDecoder(r)
+	dec.FieldsPerRecord = -1
+	if !dec.More() {
+		return nil, nil, nil
+	}
+	if header, err = dec.Decode(); err != nil {
+		return nil, nil, err
+	}
+
+	type entry struct {
+		n      int
+		record []string
+	}
+	var reservoir []entry
+	for n := 0; dec.More(); n++ {
+		record, err := dec.Decode()
+		if err != nil {
+			return header, nil, err
+		}
+		if len(reservoir) < k {
+			reservoir = append(reservoir, entry{n, record})
+		} else if j := rand.Intn(n + 1); j < k {
+			reservoir[j] = entry{n, record}
+		}
+	}
+
+	sort.Slice(reservoir, func(i, j int) bool { return reservoir[i].n < reservoir[j].n })
+	sample = make([][]string, len(reservoir))
+	for i, e := range reservoir {
+		sample[i] = e.record
+	}
+	return header, sample, nil
+}
@@ -0,0 +1,89 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("1\n2\n3\n4\n5\n6\n7\n"))
+	dec.Sample(3)
+
+	var out []string
+	for dec.More() {
+		record, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, record[0])
+	}
+	if want := []string{"3", "6"}; !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%q want %q", out, want)
+	}
+}
+
+func TestSampleHeader(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("n,v\na,1\nb,2\nc,3\nd,4\n"))
+	dec.Sample(2)
+	header, err := dec.ReadHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"n", "v"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header %q, want %q", header, want)
+	}
+	var out []string
+	for {
+		record, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, record[0])
+	}
+	if want := []string{"b", "d"}; !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%q want %q", out, want)
+	}
+}
+
+func TestReservoirSample(t *testing.T) {
+	var in strings.Builder
+	in.WriteString("n\n")
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&in, "%d\n", i)
+	}
+
+	header, sample, err := ReservoirSample(strings.NewReader(in.String()), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(header, []string{"n"}) {
+		t.Errorf("header %q, want [n]", header)
+	}
+	if len(sample) != 10 {
+		t.Fatalf("got %d records, want 10", len(sample))
+	}
+	last := -1
+	for _, record := range sample {
+		n, err := strconv.Atoi(record[0])
+		if err != nil || n <= last || n >= 1000 {
+			t.Errorf("sample %q is not an ordered subset of the input", sample)
+			break
+		}
+		last = n
+	}
+
+	_, sample, _ = ReservoirSample(strings.NewReader("n\n1\n2\n"), 10)
+	if want := [][]string{{"1"}, {"2"}}; !reflect.DeepEqual(sample, want) {
+		t.Errorf("sample %q, want %q", sample, want)
+	}
+}
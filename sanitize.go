@@ -0,0 +1,60 @@
+package csv
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Sanitizer cleans up the raw bytes of a field before it is converted.
+// Sanitizers are enabled per column with Column.Sanitizers and may modify
+// b in place.
+type Sanitizer func(b []byte) []byte
+
+// StripSpace removes leading and trailing white space.
+func StripSpace(b []byte) []byte {
+	return bytes.TrimSpace(b)
+}
+
+// StripCurrency removes currency symbols such as '$', '€' or '£' and the
+// white space around the value, so "$ 1,234.50" becomes "1,234.50".
+func StripCurrency(b []byte) []byte {
+	return bytes.TrimSpace(stripRunes(b, func(r rune) bool {
+		return unicode.Is(unicode.Sc, r)
+	}))
+}
+
+// StripPercent removes percent signs and the white space around the value,
+// so " 12.5 %" becomes "12.5". The value is not scaled.
+func StripPercent(b []byte) []byte {
+	return bytes.TrimSpace(stripRunes(b, func(r rune) bool {
+		return r == '%' || r == '‰'
+	}))
+}
+
+// stripRunes removes the runes of b for which drop returns true, reusing
+// the storage of b.
+func stripRunes(b []byte, drop func(rune) bool) []byte {
+	out := b[:0]
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if !drop(r) {
+			out = append(out, b[i:i+size]...)
+		}
+		i += size
+	}
+	return out
+}
+
+// sanitize applies the sanitizers of col to field. The field is copied
+// first so the record itself is left untouched.
+func sanitize(field []byte, col *Column) []byte {
+	if col == nil || len(col.Sanitizers) == 0 {
+		return field
+	}
+	field = append([]byte(nil), field...)
+	for _, s := range col.Sanitizers {
+		field = s(field)
+	}
+	return field
+}
@@ -21,14 +21,36 @@ type scanner struct {
 	// If TrimLeadingSpace is true, leading white space in a field is ignored.
 	// This is done even if the field delimiter, Delimiter, is white space.
 	TrimLeadingSpace bool
-	// Comment, if not 0, is the comment character. Lines beginning with the
-	// Comment character without preceding whitespace are ignored.
-	// With leading whitespace the Comment character becomes part of the
-	// field, even if TrimLeadingSpace is true.
+	// Comment, if not 0, is the comment character. Comment lines are
+	// skipped by the decoder before the scanner sees them.
 	Comment byte
+	// InlineComment, if not empty, is a prefix starting a comment that
+	// runs to the end of the line when it appears outside quotes.
+	InlineComment string
 	// If LazyQuotes is true, a quote may appear in an unquoted field and a
 	// non-doubled quote may appear in a quoted field.
 	LazyQuotes bool
+	// If UnwrapFormulas is true, a field written as ="..." is read as the
+	// quoted field alone.
+	UnwrapFormulas bool
+	// If CRLineEndings is true, a lone \r outside quotes ends a record.
+	CRLineEndings bool
+	// If Escapes is true, a backslash in an unquoted field escapes the
+	// next byte.
+	Escapes bool
+	// If Whitespace is true, runs of spaces and tabs separate fields.
+	Whitespace bool
+	
+	// trimLeading is set by the decoder when the current field has
+	// leading white space trimmed by its TrimMode, and quoted records
+	// whether the field started with a quote.
+	trimLeading bool
+	quoted      bool
+	
+	// bytes of InlineComment matched so far, and bytes of it the decoder
+	// must write to the field because the match failed
+	pending int
+	flush   int
 	
 	step       func(*scanner, byte) int
 	
@@ -49,8 +71,13 @@ const (
 	scanFieldDelimiter  // field delimiter
 	scanSkip            // space byte; can skip
 	scanEndRecord       // end of record
+	scanEndRecordCR     // end of record at the preceding lone \r
 	scanCarriageReturn
 	scanBareQuotes
+	scanBareQuoteCR     // a lazy quote and \r are data; step c again
+	scanUnwrap          // drop the formula sign written before a quote
+	scanEscaped         // byte after a backslash; write what it stands for
+	scanDelimiterBefore // the white space before c ended the field; step c again
 	
 	// Stop
 	scanError  // hit an error, scanner.err
@@ -62,24 +89,73 @@ func (s *scanner) reset() {
 	s.step = stateBeginValue
 	s.err = nil
 	s.redo = false
+	s.pending = 0
+	s.flush = 0
+}
+
+// isInlineComment reports whether c may start an inline comment.
+func (s *scanner) isInlineComment(c byte) bool {
+	return s.InlineComment != "" && c == s.InlineComment[0]
+}
+
+// beginInlineComment holds c, the first byte of an inline comment prefix.
+func (s *scanner) beginInlineComment() int {
+	s.pending = 1
+	s.step = stateCommentPrefix
+	if len(s.InlineComment) == 1 {
+		s.pending = 0
+		s.step = stateInlineComment
+	}
+	return scanSkip
 }
 
-func stateBeginComment(s *scanner, c byte) int {
+// stateCommentPrefix is the state after the first bytes of an inline
+// comment prefix.
+func stateCommentPrefix(s *scanner, c byte) int {
+	if c == s.InlineComment[s.pending] {
+		s.pending++
+		if s.pending == len(s.InlineComment) {
+			s.pending = 0
+			s.step = stateInlineComment
+		}
+		return scanSkip
+	}
+	// not a comment: the held bytes belong to the field
+	s.flush, s.pending = s.pending, 0
+	s.step = stateInUnquotedField
+	return stateInUnquotedField(s, c)
+}
+
+// stateInlineComment is the state inside an inline comment.
+func stateInlineComment(s *scanner, c byte) int {
 	if c == '\n' {
 		s.step = stateBeginValue
-		return scanSkip
+		return scanEndRecord
 	}
 	return scanSkip
 }
 
 // stateBeginValue is the state at the beginning of the input.
 func stateBeginValue(s *scanner, c byte) int {
-	if c == ' ' && s.TrimLeadingSpace {
+	if c == ' ' && s.TrimLeadingSpace || (c == ' ' || c == '\t') && c != s.Delimiter && s.trimLeading {
 		return scanSkip
 	}
 	
-	if c == s.Comment {
-		s.step = stateBeginComment
+	if s.Whitespace && (c == ' ' || c == '\t') {
+		return scanSkip
+	}
+	
+	if s.isInlineComment(c) {
+		return s.beginInlineComment()
+	}
+	
+	if c == '=' && s.UnwrapFormulas {
+		s.step = stateFormula
+		return scanBeginField
+	}
+	
+	if c == '\\' && s.Escapes {
+		s.step = stateEscape
 		return scanSkip
 	}
 	
@@ -88,9 +164,14 @@ func stateBeginValue(s *scanner, c byte) int {
 	case s.Delimiter:
 	case '"':
 		s.step = stateInQuotedField
+		s.quoted = true
 		return scanSkip
 	case '\n':
 		return scanEndRecord
+	case '\r':
+		s.redoState = stateInUnquotedField
+		s.step = stateCarriageReturn
+		return scanSkip
 	default:
 		s.step = stateInUnquotedField
 		return scanBeginField
@@ -106,6 +187,17 @@ func stateBeginValue(s *scanner, c byte) int {
 	return scanFieldDelimiter
 }
 
+// stateFormula is the state after a leading '=' when unwrapping formulas.
+func stateFormula(s *scanner, c byte) int {
+	if c == '"' {
+		s.step = stateInQuotedField
+		s.quoted = true
+		return scanUnwrap
+	}
+	s.step = stateInUnquotedField
+	return stateInUnquotedField(s, c)
+}
+
 func stateCarriageReturn(s *scanner, c byte) int {
 	if s.TrimLeadingSpace && c != '\n' && unicode.IsSpace(rune(c)) {
 		s.step = stateCarriageReturn
@@ -116,11 +208,20 @@ func stateCarriageReturn(s *scanner, c byte) int {
 		return stateEndValue(s, c)
 	}
 	
+	if s.CRLineEndings {
+		return scanEndRecordCR
+	}
+	
 	s.step = s.redoState
 	return scanCarriageReturn
 }
 
 func stateBareQuote(s *scanner, c byte) int {
+	if s.Whitespace && (c == ' ' || c == '\t') {
+		s.step = stateAfterField
+		return scanSkip
+	}
+	
 	if c == s.Delimiter {
 		return stateEndValue(s, c)
 	}
@@ -130,6 +231,11 @@ func stateBareQuote(s *scanner, c byte) int {
 		return stateEndValue(s, c)
 	}
 	
+	if c == '\r' {
+		s.step = stateQuoteCarriageReturn
+		return scanSkip
+	}
+	
 	if c != '"' {
 		if !s.LazyQuotes {
 			s.err = ErrQuote
@@ -143,6 +249,26 @@ func stateBareQuote(s *scanner, c byte) int {
 	return scanContinue
 }
 
+// stateQuoteCarriageReturn is the state after a \r following a closing
+// quote, which must end the line.
+func stateQuoteCarriageReturn(s *scanner, c byte) int {
+	if c == '\n' {
+		s.step = stateBeginValue
+		return scanEndRecord
+	}
+	
+	if s.CRLineEndings {
+		return scanEndRecordCR
+	}
+	
+	if !s.LazyQuotes {
+		s.err = ErrQuote
+		return scanError
+	}
+	s.step = stateInQuotedField
+	return scanBareQuoteCR
+}
+
 func stateInQuotedField(s *scanner, c byte) int {
 	
 	if c == '"' {
@@ -153,6 +279,11 @@ func stateInQuotedField(s *scanner, c byte) int {
 }
 
 func stateInUnquotedField(s *scanner, c byte) int {
+	if s.Whitespace && (c == ' ' || c == '\t') {
+		s.step = stateAfterField
+		return scanSkip
+	}
+	
 	if c == s.Delimiter {
 		s.step = stateBeginValue
 		return stateBeginValue(s, c)
@@ -169,14 +300,63 @@ func stateInUnquotedField(s *scanner, c byte) int {
 		return scanEndRecord
 	}
 	
+	if c == '\\' && s.Escapes {
+		s.step = stateEscape
+		return scanSkip
+	}
+	
 	if !s.LazyQuotes && c == '"' {
 		s.err = ErrBareQuote
 		return scanError
 	}
 	
+	if s.isInlineComment(c) {
+		return s.beginInlineComment()
+	}
+	
 	return scanContinue
 }
 
+// stateAfterField is the state in the white space after a field when
+// white space separates fields. It ends the record or, at the next field,
+// the field.
+func stateAfterField(s *scanner, c byte) int {
+	switch {
+	case c == ' ' || c == '\t':
+		return scanSkip
+	case c == '\n':
+		s.step = stateBeginValue
+		return scanEndRecord
+	case c == '\r':
+		s.redoState = stateAfterField
+		s.step = stateCarriageReturn
+		return scanSkip
+	case s.isInlineComment(c):
+		return s.beginInlineComment()
+	}
+	s.step = stateBeginValue
+	return scanDelimiterBefore
+}
+
+// stateEscape is the state after a backslash in an unquoted field.
+func stateEscape(s *scanner, c byte) int {
+	s.step = stateInUnquotedField
+	return scanEscaped
+}
+
+// unescape returns the byte the escape sequence \c stands for.
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	}
+	return c
+}
+
 func stateEndValue(s *scanner, c byte) int {
 	if c == s.Delimiter {
 		s.step = stateBeginValue
@@ -0,0 +1,98 @@
+package csv
+
+// A Schema describes the columns of a stream. Columns are matched to
+// fields by name when a header has been read with ReadHeader, and by
+// position otherwise.
+type Schema struct {
+	Columns []Column
+}
+
+// WithSchema sets the Schema of the decoder.
+func WithSchema(s *Schema) Option {
+	return func(d *Decoder) { d.Schema = s }
+}
+
+// A Column describes how the fields of one column are decoded.
+type Column struct {
+	Name string
+
+	// NumberFormat, if not nil, overrides the decoder's NumberFormat for
+	// this column.
+	NumberFormat *NumberFormat
+
+	// Sanitizers are applied in order to every field of the column before
+	// it is converted, e.g. StripCurrency to read "$1,200" as a number.
+	Sanitizers []Sanitizer
+
+	// Trim, if not nil, overrides the decoder's Trim for this column.
+	Trim *TrimMode
+
+	// MaxLength, if positive, is the maximum number of characters of the
+	// fields of the column. Longer fields are handled according to
+	// LengthPolicy.
+	MaxLength    int
+	LengthPolicy LengthPolicy
+
+	// Type, if not TypeUnknown, is the type of the values of the column
+	// checked by a DriftDetector, instead of one learned from the data.
+	Type FieldType
+}
+
+// A NumberFormat describes how numbers are written. The zero value accepts
+// plain numbers such as "1234.56".
+type NumberFormat struct {
+	// Thousands is the digit group separator, or 0 if digits are not grouped.
+	Thousands byte
+	// Decimal is the decimal mark. It is '.' when 0.
+	Decimal byte
+}
+
+// Common number formats.
+var (
+	// EnglishNumbers accepts numbers such as "1,234.56".
+	EnglishNumbers = NumberFormat{Thousands: ',', Decimal: '.'}
+	// EuropeanNumbers accepts numbers such as "1.234,56".
+	EuropeanNumbers = NumberFormat{Thousands: '.', Decimal: ','}
+)
+
+// normalize rewrites b into the plain form accepted by strconv, dropping
+// thousands separators and replacing the decimal mark with '.'.
+func (f NumberFormat) normalize(b []byte) []byte {
+	if f.Thousands == 0 && (f.Decimal == 0 || f.Decimal == '.') {
+		return b
+	}
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		switch {
+		case f.Thousands != 0 && c == f.Thousands:
+		case f.Decimal != 0 && c == f.Decimal:
+			out = append(out, '.')
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// schemaColumn returns the schema column describing the i'th field of the
+// current record, or nil if there is none.
+func (d *Decoder) schemaColumn(i int) *Column {
+	if d.Schema == nil {
+		return nil
+	}
+	if d.header != nil {
+		if i >= len(d.header) {
+			return nil
+		}
+		for j := range d.Schema.Columns {
+			if d.Schema.Columns[j].Name == d.header[i] {
+				return &d.Schema.Columns[j]
+			}
+		}
+		return nil
+	}
+	if i < len(d.Schema.Columns) {
+		return &d.Schema.Columns[i]
+	}
+	return nil
+}
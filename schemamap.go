@@ -0,0 +1,86 @@
+package csv
+
+// A SchemaMapper is a Transform mapping the records of any version of a
+// layout to a target layout, so ingestion survives columns being added,
+// removed, reordered or renamed upstream. Columns are matched by name:
+// target columns missing from the input get their default value, and
+// input columns not in the target are dropped.
+type SchemaMapper struct {
+	// Target is the header of the output.
+	Target []string
+	// Renames maps former column names to their names in Target.
+	Renames map[string]string
+	// Defaults holds the fields of target columns missing from the input.
+	// The fields of columns without a default are empty.
+	Defaults map[string]string
+	// Required lists target columns that must be in the input. Header
+	// fails with a *MismatchError if one is missing.
+	Required []string
+
+	cols    []int // input column of each target column, or -1
+	dropped []string
+}
+
+// NewSchemaMapper returns a SchemaMapper mapping records to target.
+func NewSchemaMapper(target []string) *SchemaMapper {
+	return &SchemaMapper{Target: target}
+}
+
+// Header maps the input header to Target.
+func (m *SchemaMapper) Header(header []string) ([]string, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if to, ok := m.Renames[name]; ok {
+			name = to
+		}
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	m.cols = m.cols[:0]
+	used := make(map[int]bool, len(m.Target))
+	for _, name := range m.Target {
+		i, ok := index[name]
+		if !ok {
+			i = -1
+		}
+		m.cols = append(m.cols, i)
+		used[i] = true
+	}
+	var missing []string
+	for _, name := range m.Required {
+		if _, ok := index[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if missing != nil {
+		return nil, &MismatchError{Report: &MismatchReport{Missing: missing}}
+	}
+	m.dropped = m.dropped[:0]
+	for i, name := range header {
+		if !used[i] {
+			m.dropped = append(m.dropped, name)
+		}
+	}
+	return m.Target, nil
+}
+
+// Apply returns the fields of r in the order of Target.
+func (m *SchemaMapper) Apply(r Record) ([]string, error) {
+	out := make([]string, len(m.cols))
+	for j, i := range m.cols {
+		if i < 0 {
+			out[j] = m.Defaults[m.Target[j]]
+		} else {
+			out[j] = fieldAt(r.Fields, i)
+		}
+	}
+	return out, nil
+}
+
+// Dropped returns the names of the input columns that are not mapped to
+// Target, as found by the last call to Header.
+func (m *SchemaMapper) Dropped() []string {
+	return m.dropped
+}
@@ -0,0 +1,51 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchemaMapper(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		dropped []string
+	}{
+		// the current version
+		{"id,name,country\n1,ann,fr\n", "id,name,country\n1,ann,fr\n", nil},
+		// an old version without country and with name called full_name
+		{"full_name,id\nbob,2\n", "id,name,country\n2,bob,us\n", nil},
+		// a newer version with an extra column, and a short record
+		{"id,name,country,vip\n3,cy,de,y\n4\n", "id,name,country\n3,cy,de\n4,,\n", []string{"vip"}},
+	}
+	for _, tt := range tests {
+		m := NewSchemaMapper([]string{"id", "name", "country"})
+		m.Renames = map[string]string{"full_name": "name"}
+		m.Defaults = map[string]string{"country": "us"}
+		m.Required = []string{"id"}
+		var out bytes.Buffer
+		if err := Reencode(strings.NewReader(tt.in), &out, ReencodeOptions{}, m); err != nil {
+			t.Fatalf("%q: %v", tt.in, err)
+		}
+		if got := out.String(); got != tt.want {
+			t.Errorf("%q: output %q, want %q", tt.in, got, tt.want)
+		}
+		if got := m.Dropped(); len(got) != len(tt.dropped) || len(got) > 0 && !reflect.DeepEqual(got, tt.dropped) {
+			t.Errorf("%q: dropped %q, want %q", tt.in, got, tt.dropped)
+		}
+	}
+}
+
+func TestSchemaMapperRequired(t *testing.T) {
+	m := NewSchemaMapper([]string{"id", "name"})
+	m.Required = []string{"id"}
+	var out bytes.Buffer
+	err := Reencode(strings.NewReader("name\nann\n"), &out, ReencodeOptions{}, m)
+	var merr *MismatchError
+	if !errors.As(err, &merr) || !reflect.DeepEqual(merr.Report.Missing, []string{"id"}) {
+		t.Errorf("error %v, want id missing", err)
+	}
+}
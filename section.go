@@ -0,0 +1,70 @@
+package csv
+
+import "errors"
+
+// errSectionBreak is returned by peek at the end of a section.
+var errSectionBreak = errors.New("end of section")
+
+// NextSection skips the rest of the current section and reads the header
+// of the next one, which Header then returns. A FieldsPerRecord learned
+// from the previous section is cleared.
+//
+// It reports false at the end of the input or on error; the error is then
+// returned by Decode.
+func (d *Decoder) NextSection() (header []string, ok bool) {
+	for d.sectionStarted && d.More() {
+		if err := d.skipRecord(); err != nil {
+			return nil, false
+		}
+	}
+	d.sectionStarted, d.sectionBreak = false, false
+	d.sectionName = ""
+	d.header, d.headerIndex = nil, nil
+	if d.fieldsLearned {
+		d.FieldsPerRecord = 0
+		d.fieldsLearned = false
+	}
+
+	if !d.More() {
+		return nil, false
+	}
+	if d.atMarker {
+		if err := d.skipRecord(); err != nil {
+			return nil, false
+		}
+		d.sectionName = string(d.field(0))
+		if !d.More() {
+			return nil, false
+		}
+	}
+	d.sectionStarted = true
+	header, err := d.ReadHeader()
+	if err != nil {
+		return nil, false
+	}
+	d.traceEvent("csv.section", map[string]interface{}{
+		"csv.section.name": d.sectionName,
+		"csv.record":       d.records,
+	})
+	return header, true
+}
+
+// SectionName returns the first field of the marker record that started
+// the current section, or "" if it had none.
+func (d *Decoder) SectionName() string {
+	return d.sectionName
+}
+
+// skipRecord reads the next record without validating or returning it.
+func (d *Decoder) skipRecord() error {
+	d.lineBuffer.Reset()
+	d.fieldIndexes = d.fieldIndexes[:0]
+	n, err := d.readRecord()
+	if err != nil {
+		d.err = err
+		return err
+	}
+	d.scanp += n
+	d.records++
+	return nil
+}
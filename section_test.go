@@ -0,0 +1,65 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type section struct {
+	Name    string
+	Header  []string
+	Records [][]string
+}
+
+func readSections(t *testing.T, dec *Decoder, limit int) []section {
+	var out []section
+	for {
+		header, ok := dec.NextSection()
+		if !ok {
+			break
+		}
+		s := section{Name: dec.SectionName(), Header: header}
+		for i := 0; dec.More() && i != limit; i++ {
+			record, err := dec.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Records = append(s.Records, record)
+		}
+		out = append(out, s)
+	}
+	if _, err := dec.Decode(); err == nil {
+		t.Error("Decode after the last section succeeded")
+	}
+	return out
+}
+
+func TestSectionsBlankLines(t *testing.T) {
+	input := "\nid,name\n1,Ann\n2,Bob\n\n\nsku,qty,price\nA1,2,9.5\n\n"
+	dec := NewDecoder(strings.NewReader(input))
+	dec.Sections = true
+	got := readSections(t, dec, -1)
+	want := []section{
+		{Header: []string{"id", "name"}, Records: [][]string{{"1", "Ann"}, {"2", "Bob"}}},
+		{Header: []string{"sku", "qty", "price"}, Records: [][]string{{"A1", "2", "9.5"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sections %+v, want %+v", got, want)
+	}
+}
+
+func TestSectionsMarkers(t *testing.T) {
+	input := "[customers]\nid,name\n1,Ann\n2,Bob\n[orders]\n\nid,total\n7,9.5\n[empty]\n"
+	dec := NewDecoder(strings.NewReader(input))
+	dec.Sections = true
+	dec.SectionMarker = "["
+	got := readSections(t, dec, 1)
+	want := []section{
+		{Name: "[customers]", Header: []string{"id", "name"}, Records: [][]string{{"1", "Ann"}}},
+		{Name: "[orders]", Header: []string{"id", "total"}, Records: [][]string{{"7", "9.5"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sections %+v, want %+v", got, want)
+	}
+}
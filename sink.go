@@ -0,0 +1,278 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SinkFunc is a Sink calling a function with every record. Close does
+// nothing.
+type SinkFunc func(r Record) error
+
+func (f SinkFunc) Write(r Record) error { return f(r) }
+func (f SinkFunc) Close() error         { return nil }
+
+// TeeSink returns a Sink writing every record to each of sinks, in order.
+// The sinks receive the same fields, which they must not modify. Closing
+// the returned sink closes all of them and returns the first error.
+func TeeSink(sinks ...Sink) Sink {
+	return teeSink(sinks)
+}
+
+type teeSink []Sink
+
+func (t teeSink) Write(r Record) error {
+	for _, s := range t {
+		if err := s.Write(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t teeSink) Close() error {
+	var err error
+	for _, s := range t {
+		if cerr := s.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// A CSVSink is a Sink encoding records as CSV. The header of the first
+// record, if any, is written before it.
+type CSVSink struct {
+	// Encoder writes the records and may be configured before the first
+	// one is written.
+	Encoder *Encoder
+
+	closer  io.Closer
+	started bool
+}
+
+// NewCSVSink returns a sink writing to w, which it does not close.
+func NewCSVSink(w io.Writer) *CSVSink {
+	return &CSVSink{Encoder: NewEncoder(w)}
+}
+
+// CreateCSVSink returns a sink writing to the named file, which is created
+// or truncated, and closed with the sink.
+func CreateCSVSink(name string) (*CSVSink, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	s := NewCSVSink(f)
+	s.closer = f
+	return s, nil
+}
+
+func (s *CSVSink) Write(r Record) error {
+	if !s.started {
+		s.started = true
+		if h := r.Header(); h != nil {
+			if err := s.Encoder.Encode(h); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Encoder.Encode(r.Fields)
+}
+
+// Close closes the encoder and the file of a sink created with
+// CreateCSVSink.
+func (s *CSVSink) Close() error {
+	err := s.Encoder.Close()
+	if s.closer != nil {
+		if cerr := s.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// An NDJSONSink is a Sink writing each record as a line of JSON: an object
+// keyed by the column names of the header, in order, or an array of its
+// fields if the record has no header. Fields beyond the header are
+// dropped and missing ones are empty strings.
+type NDJSONSink struct {
+	w      *bufio.Writer
+	closer io.Closer
+	buf    bytes.Buffer
+}
+
+// NewNDJSONSink returns a sink writing to w, which it does not close.
+func NewNDJSONSink(w io.Writer) *NDJSONSink {
+	return &NDJSONSink{w: bufio.NewWriter(w)}
+}
+
+// CreateNDJSONSink returns a sink writing to the named file, which is
+// created or truncated, and closed with the sink.
+func CreateNDJSONSink(name string) (*NDJSONSink, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	s := NewNDJSONSink(f)
+	s.closer = f
+	return s, nil
+}
+
+func (s *NDJSONSink) Write(r Record) error {
+	s.buf.Reset()
+	if h := r.Header(); h != nil {
+		writeJSONObject(&s.buf, h, func(i int) string { return fieldAt(r.Fields, i) })
+	} else {
+		b, err := json.Marshal(r.Fields)
+		if err != nil {
+			return err
+		}
+		s.buf.Write(b)
+	}
+	s.buf.WriteByte('\n')
+	_, err := s.w.Write(s.buf.Bytes())
+	return err
+}
+
+// Close flushes the output and closes the file of a sink created with
+// CreateNDJSONSink.
+func (s *NDJSONSink) Close() error {
+	err := s.w.Flush()
+	if s.closer != nil {
+		if cerr := s.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// An Execer executes SQL statements. It is implemented by *sql.DB,
+// *sql.Tx and *sql.Conn.
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// SQLSinkOptions configures an SQLSink.
+type SQLSinkOptions struct {
+	// Table is the name of the table the records are inserted into.
+	Table string
+
+	// Columns are the names of the table columns receiving the fields of
+	// each record, in order. They default to the header of the first
+	// record.
+	Columns []string
+
+	// BatchSize is the number of records inserted by one statement. It
+	// defaults to 100.
+	BatchSize int
+
+	// Placeholder returns the placeholder of the n'th argument of a
+	// statement, counted from 1. It defaults to "?"; PostgreSQL drivers
+	// need DollarPlaceholder.
+	Placeholder func(n int) string
+}
+
+// DollarPlaceholder returns the placeholder "$n".
+func DollarPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// identifier matches the table and column names accepted by SQLSink,
+// which are written into statements as they are.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// An SQLSink is a Sink inserting records into a database table in batches
+// with multi-row INSERT statements. Empty fields are inserted as empty
+// strings.
+type SQLSink struct {
+	db   Execer
+	opts SQLSinkOptions
+	rows [][]string
+}
+
+// NewSQLSink returns a sink inserting records with db.
+func NewSQLSink(db Execer, opts SQLSinkOptions) (*SQLSink, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.Placeholder == nil {
+		opts.Placeholder = func(int) string { return "?" }
+	}
+	if !identifier.MatchString(opts.Table) {
+		return nil, fmt.Errorf("csv: invalid table name %q", opts.Table)
+	}
+	if err := checkIdentifiers(opts.Columns); err != nil {
+		return nil, err
+	}
+	return &SQLSink{db: db, opts: opts}, nil
+}
+
+func checkIdentifiers(names []string) error {
+	for _, name := range names {
+		if !identifier.MatchString(name) {
+			return fmt.Errorf("csv: invalid column name %q", name)
+		}
+	}
+	return nil
+}
+
+func (s *SQLSink) Write(r Record) error {
+	if s.opts.Columns == nil {
+		if err := checkIdentifiers(r.Header()); err != nil {
+			return err
+		}
+		if len(r.Header()) == 0 {
+			return fmt.Errorf("csv: no columns to insert into %s", s.opts.Table)
+		}
+		s.opts.Columns = r.Header()
+	}
+	row := make([]string, len(s.opts.Columns))
+	for i := range row {
+		row[i] = fieldAt(r.Fields, i)
+	}
+	s.rows = append(s.rows, row)
+	if len(s.rows) >= s.opts.BatchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+// flush inserts the buffered rows.
+func (s *SQLSink) flush() error {
+	if len(s.rows) == 0 {
+		return nil
+	}
+	var q strings.Builder
+	args := make([]interface{}, 0, len(s.rows)*len(s.opts.Columns))
+	fmt.Fprintf(&q, "INSERT INTO %s (%s) VALUES ", s.opts.Table, strings.Join(s.opts.Columns, ", "))
+	for i, row := range s.rows {
+		if i > 0 {
+			q.WriteString(", ")
+		}
+		q.WriteByte('(')
+		for j, field := range row {
+			if j > 0 {
+				q.WriteString(", ")
+			}
+			args = append(args, field)
+			q.WriteString(s.opts.Placeholder(len(args)))
+		}
+		q.WriteByte(')')
+	}
+	s.rows = s.rows[:0]
+	_, err := s.db.Exec(q.String(), args...)
+	return err
+}
+
+// Close inserts the records still buffered.
+func (s *SQLSink) Close() error {
+	return s.flush()
+}
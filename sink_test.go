@@ -0,0 +1,122 @@
+package csv
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCSVSink(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.csv")
+	s, err := CreateCSVSink(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Encoder.UseCRLF = true
+	header := []string{"a", "b"}
+	s.Write(NewRecord(header, []string{"1", "x,y"}))
+	s.Write(NewRecord(header, []string{"2", ""}))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadFile(name)
+	if want := "a,b\r\n1,\"x,y\"\r\n2,\r\n"; string(b) != want {
+		t.Errorf("file %q, want %q", b, want)
+	}
+}
+
+func TestNDJSONSink(t *testing.T) {
+	var out bytes.Buffer
+	s := NewNDJSONSink(&out)
+	s.Write(NewRecord([]string{"z", "a"}, []string{"1", `q"`}))
+	s.Write(NewRecord([]string{"z", "a"}, []string{"2"}))
+	s.Write(Record{Fields: []string{"x", "y"}})
+	s.Close()
+	want := `{"z":"1","a":"q\""}` + "\n" + `{"z":"2","a":""}` + "\n" + `["x","y"]` + "\n"
+	if out.String() != want {
+		t.Errorf("out %q, want %q", out.String(), want)
+	}
+}
+
+// fakeDB records the statements executed.
+type fakeDB struct {
+	queries []string
+	args    [][]interface{}
+}
+
+func (db *fakeDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	db.queries = append(db.queries, query)
+	db.args = append(db.args, args)
+	return nil, nil
+}
+
+func TestSQLSink(t *testing.T) {
+	db := &fakeDB{}
+	s, err := NewSQLSink(db, SQLSinkOptions{Table: "public.people", BatchSize: 2, Placeholder: DollarPlaceholder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := []string{"id", "name"}
+	for _, f := range [][]string{{"1", "ann"}, {"2", "bob"}, {"3"}} {
+		if err := s.Write(NewRecord(header, f)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Close()
+	wantQueries := []string{
+		"INSERT INTO public.people (id, name) VALUES ($1, $2), ($3, $4)",
+		"INSERT INTO public.people (id, name) VALUES ($1, $2)",
+	}
+	if !reflect.DeepEqual(db.queries, wantQueries) {
+		t.Errorf("queries %q", db.queries)
+	}
+	if want := []interface{}{"3", ""}; !reflect.DeepEqual(db.args[1], want) {
+		t.Errorf("args %q, want %q", db.args[1], want)
+	}
+
+	if _, err := NewSQLSink(db, SQLSinkOptions{Table: "t; drop table x"}); err == nil {
+		t.Error("invalid table name accepted")
+	}
+	s, _ = NewSQLSink(db, SQLSinkOptions{Table: "t"})
+	if err := s.Write(NewRecord([]string{"a b"}, []string{"1"})); err == nil {
+		t.Error("invalid column name accepted")
+	}
+}
+
+func TestSinkFunc(t *testing.T) {
+	errStop := errors.New("stop")
+	var n int
+	err := NewPipeline(&testSource{n: 5}).To(SinkFunc(func(r Record) error {
+		if n++; n == 3 {
+			return errStop
+		}
+		return nil
+	})).Run(context.Background())
+	if err != errStop {
+		t.Errorf("error %v", err)
+	}
+}
+
+func TestTeeSink(t *testing.T) {
+	a, b := &testSink{}, &testSink{}
+	if err := NewPipeline(&testSource{n: 3}).To(TeeSink(a, b)).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.records) != 3 || !reflect.DeepEqual(a.records, b.records) || !a.closed || !b.closed {
+		t.Errorf("sinks got %q and %q, closed %v and %v", a.records, b.records, a.closed, b.closed)
+	}
+
+	errFull := errors.New("full")
+	a, b = &testSink{fail: errFull}, &testSink{}
+	if err := NewPipeline(&testSource{n: 3}).To(TeeSink(a, b)).Run(context.Background()); err != errFull {
+		t.Errorf("error %v, want %v", err, errFull)
+	}
+	if len(b.records) != 0 || !b.closed {
+		t.Errorf("second sink got %q after the first failed, closed %v", b.records, b.closed)
+	}
+}
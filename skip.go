@@ -0,0 +1,59 @@
+package csv
+
+// SkipColumns makes the decoder drop the fields at the given column
+// indexes of the input. The fields are still scanned but never stored, so
+// a few large columns that are not needed cost no memory. Column indexes
+// used elsewhere, including by the header, field counts, FieldPos and
+// positional schemas, refer to the remaining fields, and per-column work
+// such as the type inference of a DriftDetector and the statistics of a
+// Profiler is only done for them. A
+// nil cols keeps all columns.
+//
+// SkipColumns should be called before the first record is decoded.
+func (d *Decoder) SkipColumns(cols []int) {
+	d.skipCols = nil
+	for _, col := range cols {
+		if col < 0 {
+			continue
+		}
+		for len(d.skipCols) <= col {
+			d.skipCols = append(d.skipCols, false)
+		}
+		d.skipCols[col] = true
+	}
+}
+
+// writeField appends c to the current field unless it is skipped.
+func (d *Decoder) writeField(c byte) {
+	if !d.skipField {
+		d.lineBuffer.WriteByte(c)
+	}
+}
+
+// writeFieldString is like writeField for a string.
+func (d *Decoder) writeFieldString(s string) {
+	if !d.skipField {
+		d.lineBuffer.WriteString(s)
+	}
+}
+
+// nextField ends the current field of the input and starts the next one
+// at pos. A skipped field leaves no index, so the next field takes its
+// place.
+func (d *Decoder) nextField(pos fieldPos) {
+	d.inputField++
+	if d.skipField {
+		d.fieldPos[len(d.fieldPos)-1] = pos
+		return
+	}
+	d.fieldIndexes = append(d.fieldIndexes, d.lineBuffer.Len())
+	d.fieldPos = append(d.fieldPos, pos)
+}
+
+// dropSkippedField removes the last field of the record if it is skipped.
+func (d *Decoder) dropSkippedField() {
+	if d.skipField {
+		d.fieldIndexes = d.fieldIndexes[:len(d.fieldIndexes)-1]
+		d.fieldPos = d.fieldPos[:len(d.fieldPos)-1]
+	}
+}
@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSkipColumns(t *testing.T) {
+	tests := []struct {
+		in   string
+		cols []int
+		want [][]string
+	}{
+		{"a,b,c\n1,2,3\n", []int{1}, [][]string{{"a", "c"}, {"1", "3"}}},
+		{"a,b,c\n1,2,3\n", []int{0, 2}, [][]string{{"b"}, {"2"}}},
+		{"a,b,c\n1,2,3", []int{2, 7}, [][]string{{"a", "b"}, {"1", "2"}}},
+		{"a,\"b,\"\"x\"\"\nb\",c\n", []int{1}, [][]string{{"a", "c"}}},
+		{"a,=\"b\",c\n", []int{1}, [][]string{{"a", "c"}}},
+		{"a,b\n", nil, [][]string{{"a", "b"}}},
+	}
+	for _, tt := range tests {
+		dec := NewDecoderDialect(strings.NewReader(tt.in), Dialect{UnwrapFormulas: true})
+		dec.SkipColumns(tt.cols)
+		var got [][]string
+		for {
+			record, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%q: %v", tt.in, err)
+			}
+			got = append(got, record)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q, skip %v: got %q, want %q", tt.in, tt.cols, got, tt.want)
+		}
+	}
+}
+
+func TestSkipColumnsHeader(t *testing.T) {
+	type row struct {
+		ID   int
+		Name string
+	}
+	dec := NewDecoder(strings.NewReader("ID,Notes,Name\n7,\"long text\",ann\n"))
+	dec.SkipColumns([]int{1})
+	if header, err := dec.ReadHeader(); err != nil || !reflect.DeepEqual(header, []string{"ID", "Name"}) {
+		t.Fatalf("header %q, %v", header, err)
+	}
+	var r row
+	if err := dec.DecodeStruct(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r != (row{7, "ann"}) {
+		t.Errorf("got %+v", r)
+	}
+	if line, col := dec.FieldPos(1); line != 2 || col != 15 {
+		t.Errorf("FieldPos(1) = %d, %d, want 2, 15", line, col)
+	}
+}
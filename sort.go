@@ -0,0 +1,273 @@
+package csv
+
+import (
+	"container/heap"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// A SortKey selects a column to sort by.
+type SortKey struct {
+	Column     int  // index of the column
+	Numeric    bool // compare as numbers; fields that do not parse sort first
+	Descending bool
+}
+
+// sortRunSize is the approximate number of bytes of records ExternalSort
+// keeps in memory before spilling a sorted run to disk.
+var sortRunSize = 64 << 20
+
+// ExternalSort sorts the records read from r by keys and writes them to w.
+// The first record is a header: it is written first and not sorted. The
+// sort is stable.
+//
+// Input larger than the in-memory run size is split into sorted runs that
+// are spilled to temporary files in tmpDir (the default temporary directory
+// if empty) and then merged, so memory use is bounded regardless of the
+// size of the input. Temporary files are removed before ExternalSort
+// returns.
+func ExternalSort(r io.Reader, w io.Writer, keys []SortKey, tmpDir string) error {
+	return ExternalSortStore(r, w, keys, TempDirStore(tmpDir))
+}
+
+// ExternalSortStore is like ExternalSort but spills sorted runs to store.
+func ExternalSortStore(r io.Reader, w io.Writer, keys []SortKey, store SpillStore) error {
+	dec := NewDecoder(r)
+	dec.FieldsPerRecord = -1
+	enc := NewEncoder(w)
+
+	if !dec.More() {
+		return nil
+	}
+	header, err := dec.Decode()
+	if err != nil {
+		return err
+	}
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+
+	var (
+		runs    []string
+		records [][]string
+		size    int
+	)
+	defer func() {
+		for _, name := range runs {
+			store.Remove(name)
+		}
+	}()
+
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+		size += recordSize(record)
+		if size < sortRunSize {
+			continue
+		}
+		name, err := spillRun(store, records, keys)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, name)
+		records, size = records[:0], 0
+	}
+
+	sortRecords(records, keys)
+	if len(runs) == 0 {
+		for _, record := range records {
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+		return enc.Flush()
+	}
+
+	if len(records) > 0 {
+		name, err := spillRun(store, records, keys)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, name)
+	}
+	if err := mergeRuns(store, runs, keys, enc.Encode); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// recordSize estimates the memory held by record.
+func recordSize(record []string) int {
+	n := 24
+	for _, field := range record {
+		n += len(field) + 16
+	}
+	return n
+}
+
+// sortRecords sorts records in place by keys.
+func sortRecords(records [][]string, keys []SortKey) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return compareRecords(records[i], records[j], keys) < 0
+	})
+}
+
+// compareRecords compares a and b by keys, returning -1, 0 or +1.
+func compareRecords(a, b []string, keys []SortKey) int {
+	for _, k := range keys {
+		c := compareFields(fieldAt(a, k.Column), fieldAt(b, k.Column), k.Numeric)
+		if k.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// fieldAt returns the i'th field of record, or "" if record is too short.
+func fieldAt(record []string, i int) string {
+	if i < len(record) {
+		return record[i]
+	}
+	return ""
+}
+
+func compareFields(a, b string, numeric bool) int {
+	if numeric {
+		x, errx := strconv.ParseFloat(a, 64)
+		y, erry := strconv.ParseFloat(b, 64)
+		switch {
+		case errx != nil && erry != nil:
+		case errx != nil:
+			return -1
+		case erry != nil:
+			return 1
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// spillRun sorts records and writes them to a new object in store,
+// returning its name.
+func spillRun(store SpillStore, records [][]string, keys []SortKey) (string, error) {
+	sortRecords(records, keys)
+
+	f, name, err := store.Create("csv-sort-")
+	if err != nil {
+		return "", err
+	}
+	enc := NewEncoder(f)
+	for _, record := range records {
+		enc.Encode(record)
+	}
+	err = enc.Flush()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		store.Remove(name)
+		return "", err
+	}
+	return name, nil
+}
+
+// mergeRuns merges the sorted runs stored in the named objects of store,
+// calling fn for each record in order.
+func mergeRuns(store SpillStore, runs []string, keys []SortKey, fn func([]string) error) error {
+	h := &runHeap{keys: keys}
+	for i, name := range runs {
+		f, err := store.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		dec := NewDecoder(f)
+		dec.FieldsPerRecord = -1
+		c := &runCursor{dec: dec, run: i}
+		if err := c.advance(); err != nil {
+			return err
+		}
+		if c.record != nil {
+			h.cursors = append(h.cursors, c)
+		}
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		c := h.cursors[0]
+		if err := fn(c.record); err != nil {
+			return err
+		}
+		if err := c.advance(); err != nil {
+			return err
+		}
+		if c.record == nil {
+			heap.Pop(h)
+		} else {
+			heap.Fix(h, 0)
+		}
+	}
+	return nil
+}
+
+// A runCursor is the read position in a sorted run.
+type runCursor struct {
+	dec    *Decoder
+	run    int      // index of the run, used to keep the merge stable
+	record []string // current record, nil when the run is exhausted
+}
+
+func (c *runCursor) advance() error {
+	if !c.dec.More() {
+		c.record = nil
+		return nil
+	}
+	record, err := c.dec.Decode()
+	c.record = record
+	return err
+}
+
+// runHeap orders run cursors by their current record.
+type runHeap struct {
+	keys    []SortKey
+	cursors []*runCursor
+}
+
+func (h *runHeap) Len() int { return len(h.cursors) }
+
+func (h *runHeap) Less(i, j int) bool {
+	a, b := h.cursors[i], h.cursors[j]
+	if c := compareRecords(a.record, b.record, h.keys); c != 0 {
+		return c < 0
+	}
+	return a.run < b.run
+}
+
+func (h *runHeap) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *runHeap) Push(x interface{}) { h.cursors = append(h.cursors, x.(*runCursor)) }
+
+func (h *runHeap) Pop() interface{} {
+	c := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return c
+}
@@ -0,0 +1,60 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExternalSort(t *testing.T) {
+	in := "name,age\nbob,30\nann,9\ncid,30\ndan,100\n"
+	keys := []SortKey{{Column: 1, Numeric: true, Descending: true}, {Column: 0}}
+	want := "name,age\ndan,100\nbob,30\ncid,30\nann,9\n"
+
+	var out bytes.Buffer
+	if err := ExternalSort(strings.NewReader(in), &out, keys, ""); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("out=%q want %q", out.String(), want)
+	}
+}
+
+func TestExternalSortSpill(t *testing.T) {
+	defer func(n int) { sortRunSize = n }(sortRunSize)
+	sortRunSize = 200
+
+	dir, err := ioutil.TempDir("", "csv-sort-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var in, want bytes.Buffer
+	in.WriteString("id,stable\n")
+	want.WriteString("id,stable\n")
+	for i := 99; i >= 0; i-- {
+		fmt.Fprintf(&in, "%d,%d\n", i%10, i)
+	}
+	for k := 0; k < 10; k++ {
+		for i := 99; i >= 0; i-- {
+			if i%10 == k {
+				fmt.Fprintf(&want, "%d,%d\n", k, i)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	if err := ExternalSort(&in, &out, []SortKey{{Column: 0, Numeric: true}}, dir); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want.String() {
+		t.Errorf("out=%q\nwant %q", out.String(), want.String())
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("%d temporary files left behind", len(files))
+	}
+}
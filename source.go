@@ -0,0 +1,141 @@
+package csv
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// A DecoderSource is a Source reading the records of a Decoder.
+type DecoderSource struct {
+	dec        *Decoder
+	header     bool // whether the first record is a header still to read
+	closer     io.Closer
+	headerRead bool
+}
+
+// NewDecoderSource returns a source reading from dec. If header is true
+// the first record is read with ReadHeader and carried by the following
+// records instead of being returned.
+func NewDecoderSource(dec *Decoder, header bool) *DecoderSource {
+	return &DecoderSource{dec: dec, header: header}
+}
+
+// NewReaderSource returns a source decoding r, configured by opts.
+func NewReaderSource(r io.Reader, header bool, opts ...Option) *DecoderSource {
+	return NewDecoderSource(newDecoder(r, opts), header)
+}
+
+// OpenFileSource returns a source decoding the named file, which is closed
+// with the source.
+func OpenFileSource(name string, header bool, opts ...Option) (*DecoderSource, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	s := NewReaderSource(f, header, opts...)
+	s.closer = f
+	return s, nil
+}
+
+// Decoder returns the decoder of the source.
+func (s *DecoderSource) Decoder() *Decoder {
+	return s.dec
+}
+
+func (s *DecoderSource) Next() (Record, error) {
+	if s.header && !s.headerRead {
+		s.headerRead = true
+		if _, err := s.dec.ReadHeader(); err != nil {
+			return Record{}, err
+		}
+	}
+	return s.dec.DecodeRecord()
+}
+
+// Close closes the decoder and the file of a source opened with
+// OpenFileSource.
+func (s *DecoderSource) Close() error {
+	err := s.dec.Close()
+	if s.closer != nil {
+		if cerr := s.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// GlobSource returns a source reading the files matching pattern, as
+// interpreted by filepath.Glob, one after the other in lexical order. Each
+// file is read as by OpenFileSource, so with header set every file has its
+// own header. No file matching is not an error.
+func GlobSource(pattern string, header bool, opts ...Option) (Source, error) {
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &globSource{names: names, header: header, opts: opts}, nil
+}
+
+type globSource struct {
+	names  []string
+	header bool
+	opts   []Option
+	cur    *DecoderSource
+}
+
+func (s *globSource) Next() (Record, error) {
+	for {
+		if s.cur == nil {
+			if len(s.names) == 0 {
+				return Record{}, io.EOF
+			}
+			src, err := OpenFileSource(s.names[0], s.header, s.opts...)
+			if err != nil {
+				return Record{}, err
+			}
+			s.cur, s.names = src, s.names[1:]
+		}
+		r, err := s.cur.Next()
+		if err != io.EOF {
+			return r, err
+		}
+		err = s.cur.Close()
+		s.cur = nil
+		if err != nil {
+			return Record{}, err
+		}
+	}
+}
+
+func (s *globSource) Close() error {
+	s.names = nil
+	if s.cur == nil {
+		return nil
+	}
+	err := s.cur.Close()
+	s.cur = nil
+	return err
+}
+
+// SliceSource returns a source yielding records in order, each with the
+// given header, which may be nil. The records are not copied.
+func SliceSource(header []string, records [][]string) Source {
+	return &sliceSource{template: NewRecord(header, nil), records: records}
+}
+
+type sliceSource struct {
+	template Record
+	records  [][]string
+}
+
+func (s *sliceSource) Next() (Record, error) {
+	if len(s.records) == 0 {
+		return Record{}, io.EOF
+	}
+	r := s.template
+	r.Fields, s.records = s.records[0], s.records[1:]
+	return r, nil
+}
+
+func (s *sliceSource) Close() error { return nil }
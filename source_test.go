@@ -0,0 +1,83 @@
+package csv
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// drain reads all the records of src.
+func drain(t *testing.T, src Source) []Record {
+	t.Helper()
+	var records []Record
+	for {
+		r, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	if err := src.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestReaderSource(t *testing.T) {
+	src := NewReaderSource(strings.NewReader("a;b\n1;2\n3;4\n"), true, WithDialect(Dialect{Delimiter: ';'}))
+	records := drain(t, src)
+	if len(records) != 2 || !reflect.DeepEqual(records[1].Fields, []string{"3", "4"}) {
+		t.Fatalf("records %v", records)
+	}
+	if v, _ := records[0].Get("b"); v != "2" {
+		t.Errorf("b = %q, want 2", v)
+	}
+
+	records = drain(t, NewReaderSource(strings.NewReader("a\nb\n"), false))
+	if len(records) != 2 || records[0].Header() != nil {
+		t.Errorf("records without header %v", records)
+	}
+}
+
+func TestGlobSource(t *testing.T) {
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "2.csv"), []byte("id,v\n3,c\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "1.csv"), []byte("v,id\na,1\nb,2\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "empty.csv"), nil, 0644)
+	ioutil.WriteFile(filepath.Join(dir, "x.txt"), []byte("x\ny\n"), 0644)
+
+	src, err := GlobSource(filepath.Join(dir, "*.csv"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, r := range drain(t, src) {
+		id, _ := r.Get("id")
+		ids = append(ids, id)
+	}
+	if strings.Join(ids, ",") != "1,2,3" {
+		t.Errorf("ids %q", ids)
+	}
+
+	if _, err := GlobSource("[", true); err == nil {
+		t.Error("bad pattern accepted")
+	}
+}
+
+func TestSliceSource(t *testing.T) {
+	var out strings.Builder
+	src := SliceSource([]string{"k", "v"}, [][]string{{"a", "1"}, {"b", "2"}})
+	if err := NewPipeline(src).To(NewCSVSink(&out)).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "k,v\na,1\nb,2\n"; out.String() != want {
+		t.Errorf("out %q, want %q", out.String(), want)
+	}
+}
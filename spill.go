@@ -0,0 +1,122 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// ErrSpillLimit is returned when writing to a store returned by LimitSpill
+// would exceed its size limit.
+var ErrSpillLimit = errors.New("csv: spill size limit exceeded")
+
+// A SpillStore holds the temporary data written by operations whose input
+// does not fit in memory, such as ExternalSortStore, an Aggregator with
+// MaxGroups and Diff with Buckets. Every operation removes the objects it
+// created before it returns, or, for an Aggregator, when it is closed,
+// whether or not it succeeds.
+//
+// A SpillStore may be used by several operations at once.
+type SpillStore interface {
+	// Create creates a new object whose name starts with prefix and
+	// returns it open for writing together with its name.
+	Create(prefix string) (io.WriteCloser, string, error)
+	// Open opens the named object for reading.
+	Open(name string) (io.ReadCloser, error)
+	// Remove removes the named object.
+	Remove(name string) error
+}
+
+// TempDirStore returns a SpillStore keeping objects in temporary files in
+// dir, or in the default directory for temporary files if dir is empty.
+func TempDirStore(dir string) SpillStore {
+	return tempDirStore(dir)
+}
+
+type tempDirStore string
+
+func (dir tempDirStore) Create(prefix string) (io.WriteCloser, string, error) {
+	f, err := ioutil.TempFile(string(dir), prefix)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, f.Name(), nil
+}
+
+func (dir tempDirStore) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (dir tempDirStore) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// LimitSpill returns a SpillStore storing objects in s that fails with
+// ErrSpillLimit once the objects it holds would exceed max bytes in total.
+// Removed objects no longer count towards the limit.
+func LimitSpill(s SpillStore, max int64) SpillStore {
+	return &limitStore{store: s, max: max, sizes: make(map[string]int64)}
+}
+
+type limitStore struct {
+	store SpillStore
+	max   int64
+
+	mu    sync.Mutex
+	used  int64
+	sizes map[string]int64
+}
+
+func (s *limitStore) Create(prefix string) (io.WriteCloser, string, error) {
+	w, name, err := s.store.Create(prefix)
+	if err != nil {
+		return nil, "", err
+	}
+	s.mu.Lock()
+	s.sizes[name] = 0
+	s.mu.Unlock()
+	return &limitWriter{s: s, w: w, name: name}, name, nil
+}
+
+func (s *limitStore) Open(name string) (io.ReadCloser, error) {
+	return s.store.Open(name)
+}
+
+func (s *limitStore) Remove(name string) error {
+	s.mu.Lock()
+	s.used -= s.sizes[name]
+	delete(s.sizes, name)
+	s.mu.Unlock()
+	return s.store.Remove(name)
+}
+
+// reserve accounts for n more bytes written to the named object.
+func (s *limitStore) reserve(name string, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used+int64(n) > s.max {
+		return ErrSpillLimit
+	}
+	s.used += int64(n)
+	s.sizes[name] += int64(n)
+	return nil
+}
+
+type limitWriter struct {
+	s    *limitStore
+	w    io.WriteCloser
+	name string
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if err := w.s.reserve(w.name, len(p)); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
+
+func (w *limitWriter) Close() error {
+	return w.w.Close()
+}
@@ -0,0 +1,92 @@
+package csv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memStore is a SpillStore keeping objects in memory.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string]*bytes.Buffer
+	created int
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: make(map[string]*bytes.Buffer)}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func (s *memStore) Create(prefix string) (io.WriteCloser, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created++
+	name := fmt.Sprintf("%s%d", prefix, s.created)
+	b := new(bytes.Buffer)
+	s.objects[name] = b
+	return nopWriteCloser{b}, name, nil
+}
+
+func (s *memStore) Open(name string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ioutil.NopCloser(bytes.NewReader(s.objects[name].Bytes())), nil
+}
+
+func (s *memStore) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, name)
+	return nil
+}
+
+func TestSpillStore(t *testing.T) {
+	defer func(n int) { sortRunSize = n }(sortRunSize)
+	sortRunSize = 100
+
+	var in bytes.Buffer
+	in.WriteString("n\n")
+	for i := 20; i > 0; i-- {
+		fmt.Fprintf(&in, "%d\n", i)
+	}
+
+	store := newMemStore()
+	var out bytes.Buffer
+	if err := ExternalSortStore(bytes.NewReader(in.Bytes()), &out, []SortKey{{Numeric: true}}, store); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "n\n1\n2\n3\n") {
+		t.Errorf("sorted output %q", out.String())
+	}
+	if store.created < 2 {
+		t.Errorf("%d runs spilled, want several", store.created)
+	}
+	if len(store.objects) != 0 {
+		t.Errorf("%d spilled objects left behind", len(store.objects))
+	}
+
+	agg := AggregateOptions{GroupBy: []int{0}, MaxGroups: 2, Spill: store}
+	if err := Aggregate(bytes.NewReader(in.Bytes()), ioutil.Discard, agg); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.objects) != 0 {
+		t.Errorf("aggregate: %d spilled objects left behind", len(store.objects))
+	}
+
+	limited := LimitSpill(store, 50)
+	err := ExternalSortStore(bytes.NewReader(in.Bytes()), ioutil.Discard, []SortKey{{Numeric: true}}, limited)
+	if err != ErrSpillLimit {
+		t.Errorf("limited store: error %v, want ErrSpillLimit", err)
+	}
+	if len(store.objects) != 0 {
+		t.Errorf("limited store: %d spilled objects left behind", len(store.objects))
+	}
+}
@@ -0,0 +1,37 @@
+package csv
+
+import "bytes"
+
+// ScanRecords is a split function for a bufio.Scanner that returns each
+// raw record of the input, with its line terminator removed. Unlike
+// bufio.ScanLines it does not split records at newlines inside quoted
+// fields. Fields are not parsed: quotes are left in place and the tokens
+// are suitable for sharding or grep-like tools that need whole records.
+//
+// Quoting must follow RFC 4180; a quote in an unquoted field, as accepted
+// by LazyQuotes, may make records run together.
+func ScanRecords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	quoted := false
+	for i, c := range data {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case '\n':
+			if !quoted {
+				return i + 1, dropCR(data[:i]), nil
+			}
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), dropCR(data), nil
+	}
+	return 0, nil, nil
+}
+
+// dropCR drops a terminal \r from data.
+func dropCR(data []byte) []byte {
+	if bytes.HasSuffix(data, []byte{'\r'}) {
+		return data[:len(data)-1]
+	}
+	return data
+}
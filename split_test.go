@@ -0,0 +1,27 @@
+package csv
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanRecords(t *testing.T) {
+	in := "a,b\r\n\"multi\nline\",\"q\"\"\n\"\n\nlast,\"x\""
+	s := bufio.NewScanner(strings.NewReader(in))
+	s.Buffer(make([]byte, 4), 1024)
+	s.Split(ScanRecords)
+
+	var out []string
+	for s.Scan() {
+		out = append(out, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a,b", "\"multi\nline\",\"q\"\"\n\"", "", "last,\"x\""}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%q want %q", out, want)
+	}
+}
@@ -0,0 +1,45 @@
+package csv
+
+import "errors"
+
+// Err returns the error that stopped the decoder, or nil if it can read
+// on. Once stopped, the decoder returns the error from every call that
+// reads a record. Errors found in a record are returned by Err as their
+// cause, such as ErrFieldCount, and by those calls in a ParseError; match
+// them with errors.Is. Err returns io.EOF once a Limit is reached.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// ClearError clears the error that stopped the decoder so that it carries
+// on with the next record, and reports whether it did. It can clear the
+// errors returned in a ParseError: after a record rejected by a check,
+// such as a wrong field count, the decoder reads the next record; after a
+// syntax error, it skips the rest of the line where the error was found,
+// which is counted as a record and returned by Raw. Errors reading the
+// input and the end of the input are not cleared.
+func (d *Decoder) ClearError() bool {
+	if d.err == nil {
+		return true
+	}
+	var perr *ParseError
+	if !errors.As(d.stopErr, &perr) {
+		return false
+	}
+	if d.raw == nil {
+		// a syntax error in the middle of the record
+		d.raw = d.resync(perr.Offset)
+		d.lineBuffer.Reset()
+		d.fieldIndexes = d.fieldIndexes[:0]
+	}
+	d.err, d.stopErr = nil, nil
+	return true
+}
+
+// stopped notes err, returned by next, if it stopped the decoder.
+func (d *Decoder) stopped(err error) error {
+	if err != nil && d.err != nil {
+		d.stopErr = err
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestClearError(t *testing.T) {
+	in := "a,b\n1\n2,\"x\"y,z\n3,4\n"
+	dec := NewDecoder(strings.NewReader(in))
+	var got []string
+	var errs []error
+	for {
+		fields, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errs = append(errs, dec.Err())
+			if !dec.ClearError() {
+				t.Fatalf("ClearError() failed after %v", err)
+			}
+			if dec.Err() != nil {
+				t.Errorf("Err() = %v after ClearError", dec.Err())
+			}
+			continue
+		}
+		got = append(got, fields[0])
+	}
+	if strings.Join(got, " ") != "a 3" {
+		t.Errorf("records %q, want a and 3", got)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrFieldCount) || !errors.Is(errs[1], ErrQuote) {
+		t.Errorf("errors %v, want %v and %v", errs, ErrFieldCount, ErrQuote)
+	}
+	if n := dec.RecordNumber(); n != 4 {
+		t.Errorf("RecordNumber() = %d, want 4", n)
+	}
+}
+
+func TestClearErrorRaw(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("\"a\"b,c\nd\n"))
+	if _, err := dec.Decode(); !errors.Is(err, ErrQuote) {
+		t.Fatalf("error %v, want %v", err, ErrQuote)
+	}
+	if !dec.ClearError() || string(dec.Raw()) != "\"a\"b,c\n" {
+		t.Errorf("Raw() = %q after ClearError", dec.Raw())
+	}
+	if fields, err := dec.Decode(); err != nil || fields[0] != "d" {
+		t.Errorf("Decode() = %q, %v", fields, err)
+	}
+}
+
+func TestClearErrorFatal(t *testing.T) {
+	errRead := errors.New("connection reset")
+	dec := NewDecoder(io.MultiReader(strings.NewReader("a,b\n1,"), failReader{errRead}))
+	dec.Decode()
+	if _, err := dec.Decode(); err != errRead {
+		t.Fatalf("error %v, want %v", err, errRead)
+	}
+	if dec.Err() != errRead || dec.ClearError() {
+		t.Errorf("Err() = %v, ClearError() cleared a read error", dec.Err())
+	}
+	if _, err := dec.Decode(); err != errRead {
+		t.Errorf("error %v after ClearError, want %v", err, errRead)
+	}
+
+	dec = NewDecoder(strings.NewReader(""))
+	dec.Decode()
+	if dec.ClearError() != true || dec.Err() != nil {
+		t.Errorf("at EOF: Err() = %v", dec.Err())
+	}
+}
+
+type failReader struct{ err error }
+
+func (r failReader) Read([]byte) (int, error) { return 0, r.err }
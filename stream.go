@@ -1,19 +1,26 @@
 package csv
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"math/big"
+	"reflect"
+	"time"
 )
 
 type SyntaxError struct {
 	msg    string // description of error
 	Offset int64  // error occurred after reading Offset bytes
+	Err    error  // underlying error, if any
 }
 
 func (e *SyntaxError) Error() string { return e.msg }
 
+// Unwrap returns the underlying error, so errors.Is and errors.As see it.
+func (e *SyntaxError) Unwrap() error { return e.Err }
+
 // A Decoder reads and decodes CSV values from an input stream.
 type Decoder struct {
 	// FieldsPerRecord is the number of expected fields per record.
@@ -25,15 +32,190 @@ type Decoder struct {
 	FieldsPerRecord int
 	
 	TrailingComma bool // ignored; here for backwards compatibility
+	
+	// NumberFormat is the format of numbers converted by DecodeValues and
+	// DecodeStruct. It can be overridden per column by Schema.
+	NumberFormat NumberFormat
+	
+	// Schema, if not nil, describes how individual columns are decoded.
+	Schema *Schema
+	
+	// DuplicateHeaders controls how ReadHeader handles column names that
+	// appear more than once.
+	DuplicateHeaders DuplicatePolicy
+	
+	// MissingColumns controls how DecodeStruct handles required fields
+	// without a matching column.
+	MissingColumns MissingPolicy
+	
+	// NormalizeHeader, if not nil, rewrites the column names read by
+	// ReadHeader, and the names struct fields are bound by. Once records
+	// have been decoded, change it with SetNormalizeHeader.
+	NormalizeHeader HeaderNormalizer
+	
+	// If AllowTrailingDelimiter is true, a delimiter at the end of a record
+	// does not start another field: "a,b," is read as two fields.
+	AllowTrailingDelimiter bool
+	
+	// If PadShortRows is true and FieldsPerRecord is positive, records with
+	// fewer fields are padded with empty fields instead of failing with
+	// ErrFieldCount. If TruncateLongRows is true, extra fields are dropped.
+	PadShortRows     bool
+	TruncateLongRows bool
+	
+	// Trim selects the white space trimmed from unquoted fields. It can be
+	// overridden per column by Schema.
+	Trim TrimMode
+	
+	// InvalidUTF8 controls how records that are not valid UTF-8 are
+	// handled.
+	InvalidUTF8 UTF8Policy
+	
+	// ControlBytes controls how NUL and other control bytes in fields,
+	// which are a sign of binary content, are handled.
+	ControlBytes ControlPolicy
+	
+	// Checksum, if not nil, verifies the last field of every record as a
+	// checksum of its other fields and strips it.
+	Checksum RowChecksum
+	
+	// Trailer, if not nil, describes a trailer record ending the input,
+	// which is validated instead of being returned.
+	Trailer *Trailer
+	
+	// If Sections is true, the input is made of sections, each starting
+	// with a header and read with NextSection. Sections are separated by
+	// blank lines or, if SectionMarker is not empty, by marker records
+	// starting with SectionMarker.
+	Sections      bool
+	SectionMarker string
+	
+	// If RequireFinalNewline is true, a last record not terminated by a
+	// newline fails with ErrNoFinalNewline instead of being returned.
+	RequireFinalNewline bool
+	
+	// If Frontmatter is true, the input may start with a block of
+	// metadata lines such as "# source: crm", read by Metadata, before
+	// the header.
+	Frontmatter bool
+	
+	// Passthrough, if not nil, receives the exact bytes of every record
+	// read, including the header and records that are rejected or
+	// skipped, with their line terminators. Blank and comment lines
+	// between records are not written.
+	Passthrough io.Writer
+	
+	// Quarantine, if not nil, receives the exact bytes of every record
+	// rejected with a ParseError, such as a syntax error, a wrong field
+	// count or a failed expectation, and decoding carries on with the
+	// next record instead of returning the error. The rest of the line
+	// of a syntax error is quarantined with it. Errors about the stream
+	// as a whole, such as ErrTrailer, are still returned. If
+	// QuarantineReason is true, the error is appended to each record as
+	// an extra field.
+	Quarantine       io.Writer
+	QuarantineReason bool
+	
+	// MaxRecordSize and MaxFields, if positive, bound the size in bytes,
+	// including quotes and the line terminator, and the number of fields
+	// of a record, so that hostile input cannot make the decoder buffer
+	// without end. A record exceeding them stops the decoder with
+	// ErrRecordTooLarge or ErrTooManyFields.
+	MaxRecordSize int
+	MaxFields     int
+	
+	// EmptyRecords is what the decoder does with blank lines. They are
+	// skipped by default. Empty records are not passed to Filter or
+	// checked against the field count. Blank lines ending a Section are
+	// always skipped.
+	EmptyRecords EmptyRecordPolicy
+	
+	// If ReuseMap is true, DecodeMap returns the same map for every record.
+	ReuseMap bool
+	
+	// LargeFieldThreshold, if positive, bounds the bytes of a record kept
+	// in the input buffer. Records of any size are read correctly, but by
+	// default a record is held twice while it is scanned: as input and as
+	// decoded fields. Once a record grows beyond the threshold, the input
+	// already scanned is dropped and only the decoded fields are kept, so
+	// a record with multi-megabyte fields is held in memory about once.
+	// WriteTo, Encoder.ReadFrom and Passthrough, which copy records byte
+	// for byte, keep whole records regardless.
+	LargeFieldThreshold int
+	
+	// Drift, if not nil, learns the type of each column and reports the
+	// records after the header where a field does not have it.
+	Drift *DriftDetector
+	
+	// Profile, if not nil, describes the columns of the records after the
+	// header.
+	Profile *Profiler
+	
+	// RateLimit bounds the rate at which records are returned. Decoding
+	// waits as needed after reading each record, measured by its size in
+	// the input, until Context is done.
+	RateLimit RateLimit
+	Context   context.Context
+	
+	// Metrics, if not nil, receives the number of records and bytes read,
+	// errors, and the time spent decoding each record.
+	Metrics Metrics
+	
+	// Tracer, if not nil, traces the decoding of the stream in a span
+	// starting with the first record and ending at the end of the input,
+	// at the first error the decoder cannot recover from, or on Close.
+	Tracer Tracer
+	
+	// Logger, if not nil, logs skipped and reshaped records, rejected
+	// records and the error or end of input stopping the decoder.
+	Logger Logger
 
-	line   int
+	line   int // lines consumed so far
 	column int
 	
-	r *bufio.Reader
+	// input offset of the start of the current line, and the line and
+	// column of each field of the current record
+	lineStart int64
+	fieldPos  []fieldPos
+	
+	// line terminators read so far
+	lineEndings LineEndings
+	
+	// columns dropped by SkipColumns, the input column of the current
+	// field, and whether it is dropped
+	skipCols   []bool
+	inputField int
+	skipField  bool
+	
+	// bytes of the current record dropped from buf under
+	// LargeFieldThreshold, and whether whole records must be kept
+	spilled int64
+	keepRaw bool
+	
+	// expectations set with Expect, and the metadata of the Frontmatter
+	expect   *Expectations
+	metadata map[string]string
+	
+	// token buckets of RateLimit, created on first use
+	limiter *rateLimiter
+	
+	// scratch space of DecodeBatch
+	batch batchBuffer
+	
+	// line the current record starts on, and number of records read
+	recordLine int
+	records    int64
+	
+	// r is read directly into buf, without an intermediate buffer
+	r io.Reader
+	
+	// minimum number of bytes requested by refill, set by SetBufferSize
+	minRead int
 	
 	buf   []byte
 	//d     decodeState
 	scanp int // start of unread data in buf
+	offset int64 // input offset of buf[0]
 	scan  scanner
 	err   error
 	
@@ -49,21 +231,179 @@ type Decoder struct {
 	
 	tokenState int
 	tokenStack []int
+	
+	// header read by ReadHeader and the index of each of its names
+	header      []string
+	headerIndex map[string]int
+	headerID    string // groupID of header, identifying it in decode plans
+	
+	// converters registered with RegisterConverter, by destination type
+	converters map[reflect.Type]Converter
+	
+	// map returned by DecodeMap when ReuseMap is true
+	recordMap map[string]string
+	
+	// decode plans built by DecodeStruct
+	plans map[planKey]*decodePlan
+	
+	// filter set by Filter, and the fields of the record it last tested
+	filter func(Record) bool
+	record []string
+	
+	// sampling interval set by Sample and records counted towards it
+	sampleEvery int
+	sampleSeen  int
+	
+	// manifest of the input, when enabled by EnableManifest
+	manifest *manifestCounter
+	
+	// raw bytes of the current record, including its terminator; only
+	// valid until the next refill
+	raw []byte
+	
+	// background reader started by Prefetch
+	prefetch *prefetchReader
+	
+	// reader set by Follow
+	follow *followReader
+	
+	// pool set by UsePool
+	pool *RecordPool
+	
+	// whether FieldsPerRecord was set from the first record
+	fieldsLearned bool
+	
+	// trim mode of the field being scanned
+	fieldTrim TrimMode
+	
+	// scratch space for replacing invalid UTF-8 and computing checksums
+	utf8Buf []byte
+	sumBuf  []byte
+	
+	// fields of the current record truncated to their MaxLength, and
+	// whether the record being read is the header
+	truncated []int
+	inHeader  bool
+	
+	// state of the trailer check: the data records read and the total of
+	// the trailer's column, and the trailer once read
+	trailerCount int64
+	trailerTotal big.Rat
+	trailer      []string
+	
+	// whether an error returned by nextRecord is about the record it read,
+	// whose bytes are raw, rather than about the stream
+	inRecord bool
+	
+	// section state: whether a section has started and ended, whether the
+	// next record is a section marker, and the last marker read
+	sectionStarted bool
+	sectionBreak   bool
+	atMarker       bool
+	sectionName    string
+	
+	// prefix of comment lines, whether peek is inside a comment line, and
+	// whether it has skipped white space since the start of the line
+	commentPrefix []byte
+	inComment     bool
+	midLine       bool
+	
+	// preamble handling set by the dialect, and whether it is done
+	skipBOM      bool
+	sepDirective bool
+	started      bool
+	
+	// last error reported to Metrics and Tracer, the span of the stream,
+	// and whether it was started
+	observedErr error
+	span        Span
+	traced      bool
+	
+	// error returned when the decoder stopped, used by ClearError
+	stopErr error
 }
 
+// defaultMinRead is the default minimum number of bytes refill asks the
+// reader for, the size of the reads of a default bufio.Reader.
+const defaultMinRead = 4096
+
 // NewDecoder returns a new decoder that reads from r.
 //
 // The decoder introduces its own buffering and may
 // read data from r beyond the CSV values requested.
+// Input is read directly into that buffer, so there is no need to wrap r
+// in a bufio.Reader; one that is given is simply read from.
 func NewDecoder(r io.Reader) *Decoder {
 	return &Decoder{
 		scan: scanner{
 			Delimiter: ',',
 		},
-		r: bufio.NewReader(r),
+		r:       r,
+		minRead: defaultMinRead,
+	}
+}
+
+// Reset discards the decoder's state and makes it read from r, so a single
+// decoder and its buffers can be reused across many inputs. The
+// configuration of the decoder, such as its dialect, schema, converters,
+// filter and pool, is kept; the header, any error, and a FieldsPerRecord
+// learned from the first record are cleared. A manifest, prefetching or
+// following enabled on the previous input is restarted for r.
+func (d *Decoder) Reset(r io.Reader) {
+	if d.fieldsLearned {
+		d.FieldsPerRecord = 0
+		d.fieldsLearned = false
+	}
+	d.line, d.column = 0, 0
+	d.lineStart = 0
+	d.lineEndings = LineEndings{}
+	d.metadata = nil
+	d.limiter = nil
+	d.recordLine, d.records = 0, 0
+	d.buf = d.buf[:0]
+	d.offset = 0
+	d.scanp = 0
+	d.scan.reset()
+	d.scan.bytes = 0
+	d.endSpan()
+	d.err, d.observedErr, d.stopErr = nil, nil, nil
+	d.traced = false
+	d.lineBuffer.Reset()
+	d.fieldIndexes = d.fieldIndexes[:0]
+	d.raw = nil
+	d.header, d.headerIndex = nil, nil
+	d.sampleSeen = 0
+	d.started = false
+	d.trailerCount, d.trailer = 0, nil
+	d.trailerTotal.SetInt64(0)
+	d.sectionStarted, d.sectionBreak, d.atMarker = false, false, false
+	d.sectionName = ""
+	d.inComment, d.midLine = false, false
+
+	d.r = r
+	if d.manifest != nil {
+		d.EnableManifest()
+	}
+	if d.follow != nil {
+		d.follow.close()
+		d.Follow(d.follow.opts)
+	}
+	if d.prefetch != nil {
+		d.prefetch.close()
+		d.Prefetch(d.prefetch.n)
 	}
 }
 
+// SetBufferSize sets the minimum number of bytes the decoder asks its
+// input for on each read. Large sizes, such as 1MB, reduce the number of
+// reads and buffer copies on fast local files.
+func (d *Decoder) SetBufferSize(n int) {
+	if n <= 0 {
+		n = defaultMinRead
+	}
+	d.minRead = n
+}
+
 // More reports whether there is another element in the
 // current array or object being parsed.
 func (d *Decoder) More() bool {
@@ -71,31 +411,139 @@ func (d *Decoder) More() bool {
 	return err == nil && d.scan.err == nil
 }
 
+// Decode reads the next record from the input and returns its fields.
 func (d *Decoder) Decode() (fields []string, err error) {
-	// unexpected error
-	if d.err != nil {
-		return nil, d.err
+	if err := d.next(); err != nil {
+		return nil, err
 	}
 	
-	// Reset the previous line and truncate the indexes slice
-	d.lineBuffer.Reset()
-	d.fieldIndexes = d.fieldIndexes[:0]
+	fields = d.fields(fields)
 	
-	// Parse the existing buffered data
-	n, err := d.readRecord()
-	if err != nil {
-		d.err = err
-		return nil, err
+	if err := d.checkFieldCount(len(fields)); err != nil {
+		if d.observing() {
+			d.observe(time.Time{}, err)
+		}
+		return fields, err
 	}
 	
-	d.scanp += n
-	
+	return fields, nil
+}
+
+// next parses the next record accepted by the filter into lineBuffer and
+// fieldIndexes without materializing its fields.
+func (d *Decoder) next() error {
+	if !d.observing() {
+		return d.stopped(d.throttle(d.nextQuarantined()))
+	}
+	start := time.Now()
+	if d.Tracer != nil {
+		d.startSpan()
+	}
+	err := d.nextQuarantined()
+	d.observe(start, err)
+	return d.stopped(d.throttle(err))
+}
+
+func (d *Decoder) nextRecord() error {
+	for {
+		// unexpected error
+		d.inRecord = false
+		if d.err != nil {
+			return d.err
+		}
+		
+		// Skip blank and comment lines, and stop at the end of the input
+		// or of a section
+		if _, err := d.peek(); err != nil {
+			if err == errSectionBreak {
+				return io.EOF
+			}
+			return err
+		}
+		if d.follow.headerAt(d.offset + int64(d.scanp)) {
+			if _, err := d.ReadHeader(); err != nil {
+				return err
+			}
+			continue
+		}
+		
+		// Reset the previous line and truncate the indexes slice
+		d.lineBuffer.Reset()
+		d.fieldIndexes = d.fieldIndexes[:0]
+		
+		// Parse the existing buffered data
+		d.inRecord = true
+		n, err := d.readRecord()
+		if err != nil {
+			d.raw = nil
+			d.err = err
+			return err
+		}
+		
+		d.raw = d.buf[d.scanp : d.scanp+n]
+		d.scanp += n
+		d.records++
+		if empty, err := d.checkEmpty(); empty {
+			return err
+		}
+		d.countLineEnding()
+		if err := d.passthrough(); err != nil {
+			return err
+		}
+		if err := d.checkFinalNewline(); err != nil {
+			return err
+		}
+		if err := d.checkUTF8(d.scanp - n); err != nil {
+			return err
+		}
+		if err := d.checkControl(d.scanp - n); err != nil {
+			return err
+		}
+		if d.manifest != nil {
+			d.manifest.record(len(d.fieldIndexes))
+		}
+		if done, err := d.checkTrailer(); done || err != nil {
+			if err == nil {
+				d.inRecord = false
+				err = d.afterTrailer()
+			}
+			return err
+		}
+		if err := d.checkChecksum(); err != nil {
+			return err
+		}
+		if err := d.checkExpectations(); err != nil {
+			return err
+		}
+		d.shapeRecord()
+		if err := d.checkLengths(); err != nil {
+			return err
+		}
+		if d.Drift != nil && !d.inHeader {
+			d.Drift.observe(d)
+		}
+		if d.Profile != nil && !d.inHeader {
+			d.Profile.observe(d)
+		}
+		if d.accept() {
+			return nil
+		}
+		d.debug("csv: record skipped")
+		if err := d.skip(); err != nil {
+			return err
+		}
+	}
+}
+
+// fields appends the fields of the current record to dst, which is
+// reused if it has enough room.
+func (d *Decoder) fields(dst []string) []string {
 	// Creates room for the individual fields
 	fieldCount := len(d.fieldIndexes)
-	if cap(fields) >= fieldCount {
-		fields = fields[:fieldCount]
+	if dst != nil && cap(dst) >= fieldCount {
+		dst = dst[:fieldCount]
 	} else {
-		fields = make([]string, fieldCount)
+		dst = make([]string, fieldCount)
 	}
 	// Break down the fields in the line with the help of
 	// the indexes map
@@ -103,23 +551,42 @@ func (d *Decoder) Decode() (fields []string, err error) {
 	
 	for i, idx := range d.fieldIndexes {
 		if i == fieldCount-1 {
-			fields[i] = line[idx:]
+			dst[i] = line[idx:]
 		} else {
-			fields[i] = line[idx:d.fieldIndexes[i+1]]
+			dst[i] = line[idx:d.fieldIndexes[i+1]]
 		}
 	}
-	
+	return dst
+}
+
+// checkFieldCount validates the number of fields of the current record
+// against FieldsPerRecord.
+func (d *Decoder) checkFieldCount(n int) error {
+	if n == 0 {
+		// a blank line kept by EmptyRecords
+		return nil
+	}
 	if d.FieldsPerRecord > 0 {
-		if len(fields) != d.FieldsPerRecord {
-			d.column = 0 // report at start of record
+		if n != d.FieldsPerRecord {
 			d.err = ErrFieldCount
-			return fields, &ParseError{d.line, d.column, d.err }
+			d.stopErr = d.fieldCountError(d.FieldsPerRecord, n)
+			return d.stopErr
 		}
 	} else if d.FieldsPerRecord == 0 {
-		d.FieldsPerRecord = len(fields)
+		d.FieldsPerRecord = n
+		d.fieldsLearned = true
 	}
-	
-	return fields, nil
+	return nil
+}
+
+// field returns the raw bytes of the i'th field of the current record.
+// The slice is only valid until the next call to next.
+func (d *Decoder) field(i int) []byte {
+	line := d.lineBuffer.Bytes()
+	if i == len(d.fieldIndexes)-1 {
+		return line[d.fieldIndexes[i]:]
+	}
+	return line[d.fieldIndexes[i]:d.fieldIndexes[i+1]]
 }
 
 // returns when a record is present
@@ -130,39 +597,84 @@ func (d *Decoder) readRecord() (int, error) {
 	var err error
 	
 	d.column = -1
-	d.line++
+	d.recordLine = d.line + 1
+	d.spilled = 0
 	
 	d.fieldIndexes = append(d.fieldIndexes, 0)
+	d.fieldPos = append(d.fieldPos[:0], d.position(scanp))
+	d.inputField = 0
+	d.beginField()
 Input:
 	for {
 		// Look in the buffer for a new value.
 		for i, c := range d.buf[scanp:] {
 			d.scan.bytes++
 			v := d.scan.step(&d.scan, c)
+			if v == scanBareQuoteCR {
+				d.writeFieldString("\"\r")
+				v = d.scan.step(&d.scan, c)
+			}
+			if v == scanDelimiterBefore {
+				d.endField()
+				d.nextField(d.position(scanp + i))
+				d.beginField()
+				v = d.scan.step(&d.scan, c)
+			}
+			if v == scanEndRecordCR {
+				// c begins the next record
+				d.scan.bytes--
+				d.line++
+				d.lineStart = d.offset + int64(scanp+i)
+				scanp += i
+				break Input
+			}
+			if c == '\n' {
+				d.line++
+				d.lineStart = d.offset + int64(scanp+i) + 1
+			}
+			
+			if d.scan.flush > 0 {
+				d.writeFieldString(d.scan.InlineComment[:d.scan.flush])
+				d.column += d.scan.flush
+				d.scan.flush = 0
+			}
 			
 			if v == scanBareQuotes {
-				d.lineBuffer.WriteByte('"')
+				d.writeField('"')
 				d.column++
 			}
 			
 			if v == scanCarriageReturn {
-				d.lineBuffer.WriteByte('\r')
+				d.writeField('\r')
 				d.column++
+				d.lineEndings.CR++
 			}
 			
-			if v != scanFieldDelimiter && v != scanEndRecord && v != scanSkip && v != scanError {
-				d.lineBuffer.WriteByte(c)
+			if v == scanUnwrap && !d.skipField {
+				d.lineBuffer.Truncate(d.lineBuffer.Len() - 1)
+				d.column++
+			}
+			
+			if v == scanEscaped {
+				d.writeField(unescape(c))
+				d.column++
+			} else if v != scanFieldDelimiter && v != scanEndRecord && v != scanSkip && v != scanError && v != scanUnwrap {
+				d.writeField(c)
 				d.column++
 			}
 			
 			if v == scanFieldDelimiter {
-				d.fieldIndexes = append(d.fieldIndexes, d.lineBuffer.Len())
+				if d.MaxFields > 0 && d.inputField+1 >= d.MaxFields {
+					return 0, d.limitError(ErrTooManyFields, scanp+i)
+				}
+				d.endField()
+				d.nextField(d.position(scanp + i + 1))
+				d.beginField()
 				d.column++
 			}
 			
 			if v == scanEndRecord {
 				scanp += i + 1
-				d.line++
 				break Input
 			}
 			
@@ -172,7 +684,15 @@ Input:
 				}
 				d.column++
 				d.err = d.scan.err
-				return 0, &ParseError{ Line: d.line , Column: d.column, Err: d.err }
+				return 0, &ParseError{
+					Record:  d.records + 1,
+					Line:    d.line + 1,
+					Column:  d.column,
+					Field:   len(d.fieldIndexes) - 1,
+					Offset:  d.offset + int64(scanp+i),
+					Snippet: snippet(d.buf, scanp+i),
+					Err:     d.err,
+				}
 			}
 			
 			if v == scanSkip {
@@ -183,32 +703,115 @@ Input:
 		
 		if err != nil {
 			if err == io.EOF {
-				d.scanp = scanp
 				break Input
 			}
+			return 0, err
 		}
 		
 		n := scanp - d.scanp
+		if d.MaxRecordSize > 0 && d.spilled+int64(n) > int64(d.MaxRecordSize) {
+			return 0, d.limitError(ErrRecordTooLarge, scanp)
+		}
+		if d.LargeFieldThreshold > 0 && n > d.LargeFieldThreshold && !d.keepRaw && d.Passthrough == nil && d.Quarantine == nil {
+			// the scanned bytes are in lineBuffer; let refill drop them
+			d.spilled += int64(n)
+			d.scanp, n = scanp, 0
+		}
 		err = d.refill()
 		scanp = d.scanp + n
 	}
+	if d.scan.pending > 0 {
+		// the input ended inside what looked like a comment prefix
+		d.writeFieldString(d.scan.InlineComment[:d.scan.pending])
+		d.column += d.scan.pending
+	}
+	if d.MaxRecordSize > 0 && d.spilled+int64(scanp-d.scanp) > int64(d.MaxRecordSize) {
+		return 0, d.limitError(ErrRecordTooLarge, scanp)
+	}
+	d.endField()
+	d.dropSkippedField()
+	d.midLine = false
 	return scanp - d.scanp, nil
 }
 
 // peek checks if there is any data interesting to read.
+// Blank and comment lines before the next record are consumed, but not
+// the blanks starting a record, which belong to its first field.
 func (d *Decoder) peek() (byte, error) {
+	if !d.started {
+		d.preamble()
+	}
 	var err error
 	for {
 		// scans the buffer from the actual position (read so far)
 		// to the end of the existing buffered data
+	Scan:
 		for i := d.scanp; i < len(d.buf); i++ {
 			c := d.buf[i]
-			// keep scanning the buffer until it finds something to parse
-			if d.isSpace(c) {
+			if (c == '\n' || c == '\r') && d.stopAtEmpty() {
+				// a blank line read as a record
+				d.scanp = i
+				return c, nil
+			}
+			
+			// blanks starting a line are data unless the line is blank
+			data := false
+			if !d.inComment && !d.midLine && c != '\n' && d.isSpace(c) {
+				blank, more := d.isBlankLine(d.buf[i:])
+				if more && err == nil {
+					d.scanp = i
+					break Scan
+				}
+				data = !blank
+			}
+			
+			// consume skipped bytes so lines are counted once
+			if !data && (d.inComment || d.isSpace(c)) {
+				if c == '\n' {
+					if d.Sections && d.sectionStarted && !d.inComment {
+						// a blank line ends the section
+						d.sectionBreak = true
+					}
+					d.line++
+					d.lineStart = d.offset + int64(i) + 1
+					d.inComment, d.midLine = false, false
+				} else if !d.inComment {
+					d.midLine = true
+				}
+				d.scanp = i + 1
 				continue
 			}
 			
+			if !d.midLine {
+				comment, more := d.isComment(d.buf[i:])
+				if more && err == nil {
+					// buffer more data to tell
+					d.scanp = i
+					break Scan
+				}
+				if comment {
+					d.inComment = true
+					d.scanp = i + 1
+					continue
+				}
+				
+				if d.Sections {
+					marker, more := hasPrefix(d.buf[i:], []byte(d.SectionMarker))
+					if more && err == nil {
+						d.scanp = i
+						break Scan
+					}
+					d.atMarker = marker
+					if marker && d.sectionStarted {
+						d.sectionBreak = true
+					}
+				}
+			}
+			
 			d.scanp = i
+			if d.sectionBreak {
+				return 0, errSectionBreak
+			}
 			return c, nil
 		}
 		
@@ -230,11 +833,12 @@ func (d *Decoder) refill() error {
 	if d.scanp > 0 {
 		n := copy(d.buf, d.buf[d.scanp:])
 		d.buf = d.buf[:n]
+		d.offset += int64(d.scanp)
 		d.scanp = 0
 	}
 	
 	// Grow buffer if not large enough.
-	const minRead = 512
+	minRead := d.minRead
 	if cap(d.buf)-len(d.buf) < minRead {
 		newBuf := make([]byte, len(d.buf), 2*cap(d.buf)+minRead)
 		copy(newBuf, d.buf)
@@ -244,40 +848,142 @@ func (d *Decoder) refill() error {
 	// Read. Delay error for next iteration (after scan).
 	n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
 	d.buf = d.buf[0: len(d.buf)+n]
+	if d.Metrics != nil && n > 0 {
+		d.Metrics.BytesRead(n)
+	}
 	return err
 }
 
 func (d *Decoder) isSpace(c byte) bool {
-	if !d.scan.TrimLeadingSpace {
+	if !d.scan.TrimLeadingSpace && !d.scan.Whitespace {
 		return c == '\t' || c == '\r' || c == '\n'
 	}
 	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
 }
 
-func (d *Decoder) isComment(c byte) {
-	if d.scan.Comment == c {
-	
+// isComment reports whether a comment starts at the beginning of b. It
+// reports more if b is too short to tell.
+func (d *Decoder) isComment(b []byte) (comment, more bool) {
+	return hasPrefix(b, d.commentPrefix)
+}
+
+// isBlankLine reports whether the line at the beginning of b holds only
+// white space. It reports more if b ends before the line does.
+func (d *Decoder) isBlankLine(b []byte) (blank, more bool) {
+	for _, c := range b {
+		if c == '\n' {
+			return true, false
+		}
+		if !d.isSpace(c) {
+			return false, false
+		}
 	}
+	return true, true
+}
+
+// hasPrefix reports whether b begins with the non-empty prefix. It reports
+// more if b is too short to tell.
+func hasPrefix(b, prefix []byte) (match, more bool) {
+	if len(prefix) == 0 || len(b) == 0 || b[0] != prefix[0] {
+		return false, false
+	}
+	if len(b) < len(prefix) {
+		return false, bytes.HasPrefix(prefix, b)
+	}
+	return bytes.HasPrefix(b, prefix), false
 }
 
 // A ParseError is returned for parsing errors.
-// The first line is 1.  The first column is 0.
+// The first record is 1. The first line is 1.  The first column is 0.
+//
+// Syntax errors such as ErrQuote and ErrBareQuote also locate the
+// offending byte: Field is the index of its field in the record, Offset its
+// position in the input, and Snippet the input around it.
 type ParseError struct {
-	Line   int   // Line where the error occurred
-	Column int   // Column (rune index) where the error occurred
-	Err    error // The actual error
+	Record  int64  // Record where the error occurred
+	Line    int    // Line where the error occurred
+	Column  int    // Column (rune index) where the error occurred
+	Field   int    // Field where a syntax error occurred
+	Offset  int64  // Byte offset of a syntax error in the input
+	Snippet string // Input around a syntax error, or the record of an ErrFieldCount error
+	Err     error  // The actual error
+	
+	// Expected and Actual are the field counts of an ErrFieldCount error.
+	Expected, Actual int
+}
+
+// snippetContext is the number of bytes shown on each side of a syntax
+// error.
+const snippetContext = 16
+
+// snippet returns the bytes of buf around buf[i].
+func snippet(buf []byte, i int) string {
+	start, end := i-snippetContext, i+snippetContext+1
+	if start < 0 {
+		start = 0
+	}
+	if end > len(buf) {
+		end = len(buf)
+	}
+	return string(buf[start:end])
+}
+
+// fieldCountError returns the ParseError of an ErrFieldCount error for the
+// current record, which has actual fields instead of expected.
+func (d *Decoder) fieldCountError(expected, actual int) error {
+	raw := bytes.TrimRight(d.raw, "\r\n")
+	if len(raw) > 2*snippetContext+1 {
+		raw = raw[:2*snippetContext+1]
+	}
+	d.column = 0 // report at start of record
+	perr := d.error(ErrFieldCount).(*ParseError)
+	perr.Field = expected
+	if actual < expected {
+		perr.Field = actual
+	}
+	perr.Offset = d.offset + int64(d.scanp-len(d.raw)) - d.spilled
+	perr.Snippet = string(raw)
+	perr.Expected, perr.Actual = expected, actual
+	return perr
 }
 
 // error creates a new ParseError based on err.
 func (d *Decoder) error(err error) error {
 	return &ParseError{
-		Line:   d.line,
+		Record: d.records,
+		Line:   d.recordLine,
 		Column: d.column,
 		Err:    err,
 	}
 }
 
+// RecordNumber returns the number of records read so far, which is the
+// 1-based number of the record most recently returned. The header read by
+// ReadHeader counts as a record, and so do records rejected by Filter or
+// Sample, so the number is the position of the record in the input.
+func (d *Decoder) RecordNumber() int64 {
+	return d.records
+}
+
 
 func (e *ParseError) Error() string {
-	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+	var msg string
+	if e.Record == 0 {
+		msg = fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+	} else {
+		msg = fmt.Sprintf("record %d, line %d, column %d: %s", e.Record, e.Line, e.Column, e.Err)
+	}
+	if e.Err == ErrFieldCount && e.Expected > 0 {
+		msg += fmt.Sprintf(" (got %d, want %d)", e.Actual, e.Expected)
+	}
+	if e.Snippet != "" {
+		msg += fmt.Sprintf(" (field %d, offset %d, near %q)", e.Field, e.Offset, e.Snippet)
+	}
+	return msg
+}
+
+// Unwrap returns the actual error, so errors.Is(err, ErrFieldCount) and
+// errors.As work on a ParseError.
+func (e *ParseError) Unwrap() error {
+	return e.Err
 }
@@ -1,6 +1,7 @@
 package csv
 
 import (
+	"bufio"
 	"io"
 	"reflect"
 	"strings"
@@ -104,12 +105,27 @@ field"`,
 		Input:  " a,  b,   c\n",
 		Output: [][]string{{" a", "  b", "   c"}},
 	},
-//	{
-//		Name:    "Comment",
-//		Comment: '#',
-//		Input:   "#1,2,3\na,b,c\n#comment",
-//		Output:  [][]string{{"a", "b", "c"}},
-//	},
+	{
+		Name:   "LeadingTab",
+		Input:  "a,b\n\tc,d\n",
+		Output: [][]string{{"a", "b"}, {"\tc", "d"}},
+	},
+	{
+		Name:   "LeadingCR",
+		Input:  "\ra,b\n",
+		Output: [][]string{{"\ra", "b"}},
+	},
+	{
+		Name:   "BlankLineSpace",
+		Input:  "a,b\n\t\r\nc,d\n",
+		Output: [][]string{{"a", "b"}, {"c", "d"}},
+	},
+	{
+		Name:    "Comment",
+		Comment: '#',
+		Input:   "#1,2,3\na,b,c\n#comment",
+		Output:  [][]string{{"a", "b", "c"}},
+	},
 	{
 		Name:   "NoComment",
 		Input:  "#1,2,3\na,b,c",
@@ -265,7 +281,9 @@ x,,,
 func TestRead(t *testing.T) {
 	for _, tt := range readTests {
 		r := NewDecoder(strings.NewReader(tt.Input))
-		r.scan.Comment = byte(tt.Comment)
+		if tt.Comment != 0 {
+			r.SetDialect(Dialect{Comment: byte(tt.Comment)})
+		}
 		if tt.UseFieldsPerRecord {
 			r.FieldsPerRecord = tt.FieldsPerRecord
 		} else {
@@ -400,3 +418,166 @@ xxxxxxxxxxxxxxxxxxxxxxxx,yyyyyyyyyyyyyyyyyyyyyyyyyyyyyyyy,zzzzzzzzzzzzzzzzzzzzzz
 xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx,yyyyyyyyyyyyyyyyyyyyyyyyyyyyyyyy,zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz,wwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwwww,vvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvv
 `, 3))
 }
+
+func TestSetBufferSize(t *testing.T) {
+	in := strings.Repeat("xxxxxxxxxx,yyyyyyyyyy,\"zz\nzz\"\n", 1000)
+	for _, size := range []int{1, 16, 1 << 20} {
+		dec := NewDecoder(strings.NewReader(in))
+		dec.SetBufferSize(size)
+		n := 0
+		for dec.More() {
+			record, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("size %d: unexpected error %v", size, err)
+			}
+			if len(record) != 3 || record[2] != "zz\nzz" {
+				t.Fatalf("size %d: record %d is %q", size, n, record)
+			}
+			n++
+		}
+		if n != 1000 {
+			t.Errorf("size %d: got %d records, want 1000", size, n)
+		}
+	}
+}
+
+func TestNewDecoderBufioReader(t *testing.T) {
+	br := bufio.NewReader(strings.NewReader("a,b\n"))
+	dec := NewDecoder(br)
+	if dec.r != br {
+		t.Error("NewDecoder wrapped a *bufio.Reader")
+	}
+}
+
+func TestReset(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("h1;h2\na;b\n"))
+	dec.SetDialect(Dialect{Delimiter: ';'})
+	dec.EnableManifest()
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	for dec.More() {
+		dec.Decode()
+	}
+
+	dec.Reset(strings.NewReader("x;y;z\n1;2;3\n1;2\n"))
+	if dec.Header() != nil {
+		t.Errorf("header %q survived Reset", dec.Header())
+	}
+	var out [][]string
+	var err error
+	for dec.More() {
+		var record []string
+		if record, err = dec.Decode(); err != nil {
+			break
+		}
+		out = append(out, record)
+	}
+	if want := [][]string{{"x", "y", "z"}, {"1", "2", "3"}}; !reflect.DeepEqual(out, want) {
+		t.Errorf("out=%q want %q", out, want)
+	}
+	if perr, ok := err.(*ParseError); !ok || perr.Err != ErrFieldCount || perr.Record != 3 || perr.Line != 3 {
+		t.Errorf("error %v, want %v at record 3, line 3", err, ErrFieldCount)
+	}
+	if m := dec.Manifest(); m.Records != 3 {
+		t.Errorf("manifest counted %d records, want 3", m.Records)
+	}
+}
+
+func TestRecordNumber(t *testing.T) {
+	input := "a,b\n\n\"multi\nline\",c\nd,e\n\nf,g\"h\n"
+	dec := NewDecoder(strings.NewReader(input))
+	for i := int64(1); i <= 3; i++ {
+		if !dec.More() {
+			t.Fatalf("no record %d", i)
+		}
+		if _, err := dec.Decode(); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if n := dec.RecordNumber(); n != i {
+			t.Errorf("RecordNumber() = %d, want %d", n, i)
+		}
+	}
+	if !dec.More() {
+		t.Fatal("no record 4")
+	}
+	_, err := dec.Decode()
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("error %v, want ParseError", err)
+	}
+	if perr.Record != 4 || perr.Line != 7 {
+		t.Errorf("error at record %d, line %d, want record 4, line 7", perr.Record, perr.Line)
+	}
+	if want := "record 4, line 7"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not contain %q", err, want)
+	}
+}
+
+func TestRecordNumberConversionError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("1\n2\n\nx\n"))
+	var n int
+	var err error
+	for dec.More() {
+		if err = dec.DecodeValues(&n); err != nil {
+			break
+		}
+	}
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("error %v, want ParseError", err)
+	}
+	if perr.Record != 3 || perr.Line != 4 {
+		t.Errorf("error at record %d, line %d, want record 3, line 4", perr.Record, perr.Line)
+	}
+}
+
+func TestParseErrorDetails(t *testing.T) {
+	input := "id,name\n1,ok\n2,\"bad\"x,3\n"
+	dec := NewDecoder(strings.NewReader(input))
+	dec.FieldsPerRecord = -1
+	var err error
+	for dec.More() {
+		if _, err = dec.Decode(); err != nil {
+			break
+		}
+	}
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("error %v, want ParseError", err)
+	}
+	if perr.Err != ErrQuote || perr.Field != 1 || perr.Record != 3 || perr.Line != 3 {
+		t.Errorf("error %v at field %d, record %d, line %d", perr.Err, perr.Field, perr.Record, perr.Line)
+	}
+	if want := int64(strings.Index(input, "x")); perr.Offset != want {
+		t.Errorf("offset %d, want %d", perr.Offset, want)
+	}
+	if input[perr.Offset] != 'x' || !strings.Contains(perr.Snippet, `"bad"x`) {
+		t.Errorf("snippet %q", perr.Snippet)
+	}
+	if !strings.Contains(err.Error(), "field 1, offset 20") {
+		t.Errorf("message %q lacks location", err)
+	}
+}
+
+func TestParseErrorOffsetAfterRefill(t *testing.T) {
+	input := strings.Repeat("aaaa,bbbb\n", 300) + "c,d\"e\n"
+	dec := NewDecoder(strings.NewReader(input))
+	var err error
+	for dec.More() {
+		if _, err = dec.Decode(); err != nil {
+			break
+		}
+	}
+	perr, ok := err.(*ParseError)
+	if !ok || perr.Err != ErrBareQuote {
+		t.Fatalf("error %v, want ErrBareQuote", err)
+	}
+	if want := int64(strings.Index(input, `"`)); perr.Offset != want {
+		t.Errorf("offset %d, want %d", perr.Offset, want)
+	}
+	i := strings.Index(input, `"`)
+	if perr.Field != 1 || perr.Snippet != input[i-16:] {
+		t.Errorf("field %d, snippet %q", perr.Field, perr.Snippet)
+	}
+}
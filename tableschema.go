@@ -0,0 +1,102 @@
+package csv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// tableSchema is a Frictionless Data Table Schema, as specified at
+// https://specs.frictionlessdata.io/table-schema/. Only the properties
+// with a counterpart in Schema and Contract are read.
+type tableSchema struct {
+	Fields      []tableField `json:"fields"`
+	FieldsMatch string       `json:"fieldsMatch"`
+}
+
+type tableField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	DecimalChar string `json:"decimalChar"`
+	GroupChar   string `json:"groupChar"`
+	Constraints struct {
+		Required bool              `json:"required"`
+		Pattern  string            `json:"pattern"`
+		Enum     []json.RawMessage `json:"enum"`
+	} `json:"constraints"`
+}
+
+// tableFieldTypes maps the types of Table Schema fields to FieldTypes.
+// Other types are not checked.
+var tableFieldTypes = map[string]FieldType{
+	"string":   TypeString,
+	"integer":  TypeInt,
+	"number":   TypeFloat,
+	"boolean":  TypeBool,
+	"date":     TypeTime,
+	"datetime": TypeTime,
+}
+
+// ReadTableSchema reads a Frictionless Data Table Schema in JSON from r,
+// and returns the Schema decoding the table and the Contract checking it.
+// Columns are named, typed, nullable unless required, and constrained by
+// pattern and enum as the fields of the schema; a number column with a
+// decimalChar or groupChar gets a NumberFormat. The table must have the
+// columns of the schema in order, or at least them in any order if
+// fieldsMatch is "subset"; the other values of fieldsMatch are not
+// supported. Empty fields are the only missing values.
+func ReadTableSchema(r io.Reader) (*Schema, *Contract, error) {
+	var ts tableSchema
+	if err := json.NewDecoder(r).Decode(&ts); err != nil {
+		return nil, nil, fmt.Errorf("csv: table schema: %v", err)
+	}
+	s := &Schema{Columns: make([]Column, len(ts.Fields))}
+	c := &Contract{Columns: make([]ContractColumn, len(ts.Fields))}
+	switch ts.FieldsMatch {
+	case "", "exact":
+		c.Strict = true
+	case "subset":
+	default:
+		return nil, nil, fmt.Errorf("csv: table schema: fieldsMatch %q is not supported", ts.FieldsMatch)
+	}
+	for i, f := range ts.Fields {
+		if f.Name == "" {
+			return nil, nil, fmt.Errorf("csv: table schema: field %d has no name", i)
+		}
+		typ := tableFieldTypes[f.Type]
+		if f.Type == "" {
+			typ = TypeString
+		}
+		s.Columns[i] = Column{Name: f.Name, Type: typ}
+		if f.DecimalChar != "" || f.GroupChar != "" {
+			if len(f.DecimalChar) > 1 || len(f.GroupChar) > 1 {
+				return nil, nil, fmt.Errorf("csv: table schema: field %q: decimalChar and groupChar must be single bytes", f.Name)
+			}
+			format := NumberFormat{Decimal: '.'}
+			if f.DecimalChar != "" {
+				format.Decimal = f.DecimalChar[0]
+			}
+			if f.GroupChar != "" {
+				format.Thousands = f.GroupChar[0]
+			}
+			s.Columns[i].NumberFormat = &format
+		}
+
+		col := ContractColumn{
+			Name:     f.Name,
+			Type:     typ,
+			Nullable: !f.Constraints.Required,
+			Pattern:  f.Constraints.Pattern,
+		}
+		for _, v := range f.Constraints.Enum {
+			// values are strings, or numbers and booleans as written
+			var str string
+			if json.Unmarshal(v, &str) != nil {
+				str = string(v)
+			}
+			col.Enum = append(col.Enum, str)
+		}
+		c.Columns[i] = col
+	}
+	return s, c, nil
+}
@@ -0,0 +1,84 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testTableSchema = `{
+	"fields": [
+		{"name": "id", "type": "integer", "constraints": {"required": true}},
+		{"name": "price", "type": "number", "decimalChar": ",", "groupChar": "."},
+		{"name": "size", "constraints": {"enum": ["S", "M", "L"]}},
+		{"name": "level", "type": "integer", "constraints": {"enum": [1, 2, 3]}},
+		{"name": "code", "type": "string", "constraints": {"pattern": "[A-Z]{3}"}},
+		{"name": "where", "type": "geopoint"}
+	]
+}`
+
+func TestReadTableSchema(t *testing.T) {
+	s, c, err := ReadTableSchema(strings.NewReader(testTableSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ContractColumn{
+		{Name: "id", Type: TypeInt},
+		{Name: "price", Type: TypeFloat, Nullable: true},
+		{Name: "size", Type: TypeString, Nullable: true, Enum: []string{"S", "M", "L"}},
+		{Name: "level", Type: TypeInt, Nullable: true, Enum: []string{"1", "2", "3"}},
+		{Name: "code", Type: TypeString, Nullable: true, Pattern: "[A-Z]{3}"},
+		{Name: "where", Nullable: true},
+	}
+	if !reflect.DeepEqual(c.Columns, want) || !c.Strict {
+		t.Errorf("contract %+v, want columns %+v", c, want)
+	}
+	if f := s.Columns[1].NumberFormat; f == nil || *f != EuropeanNumbers {
+		t.Errorf("price format %v, want %v", f, EuropeanNumbers)
+	}
+
+	in := "id,price,size,level,code,where\n" +
+		"1,\"1.234,5\",M,2,ABC,\"1,2\"\n" +
+		",x,XL,4,abcd,\n"
+	rep, err := Enforce(strings.NewReader(in), *c, WithSchema(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Violations) != 5 || rep.Violations[0].Record != 3 {
+		t.Errorf("violations %v", rep.Violations)
+	}
+}
+
+func TestReadTableSchemaErrors(t *testing.T) {
+	for _, in := range []string{
+		`{"fields": [{"type": "string"}]}`,
+		`{"fields": [{"name": "a", "decimalChar": "::"}]}`,
+		`{"fields": `,
+		`{"fields": [{"name": "a"}], "fieldsMatch": "superset"}`,
+		`{"fields": [{"name": "a"}], "fieldsMatch": "partial"}`,
+		`{"fields": [{"name": "a"}], "fieldsMatch": "equal"}`,
+	} {
+		if _, _, err := ReadTableSchema(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: no error", in)
+		}
+	}
+}
+
+func TestReadTableSchemaSubset(t *testing.T) {
+	_, c, err := ReadTableSchema(strings.NewReader(`{"fields": [{"name": "a"}, {"name": "b"}], "fieldsMatch": "subset"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := Enforce(strings.NewReader("b,c,a\n1,2,3\n"), *c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.OK() {
+		t.Errorf("violations %v", rep.Violations)
+	}
+	rep, _ = Enforce(strings.NewReader("b,c\n1,2\n"), *c)
+	if len(rep.Violations) != 1 || !errors.Is(rep.Violations[0], ErrMissingColumn) {
+		t.Errorf("violations %v, want a missing column", rep.Violations)
+	}
+}
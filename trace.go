@@ -0,0 +1,70 @@
+package csv
+
+import "io"
+
+// A Tracer starts the spans tracing the decoding of a stream. It is
+// independent of any tracing library; an adapter for OpenTelemetry
+// implements Start with trace.Tracer.Start, using the context of the
+// operation reading the stream, and Span with the returned trace.Span.
+type Tracer interface {
+	Start(name string) Span
+}
+
+// A Span is the trace of the decoding of one stream.
+type Span interface {
+	// SetAttribute sets an attribute of the span, such as "csv.records".
+	SetAttribute(key string, value interface{})
+	// AddEvent records an event, such as a checkpoint, with attributes.
+	AddEvent(name string, attrs map[string]interface{})
+	// RecordError records an error.
+	RecordError(err error)
+	// End ends the span.
+	End()
+}
+
+// WithTracer sets the Tracer of the decoder.
+func WithTracer(t Tracer) Option {
+	return func(d *Decoder) { d.Tracer = t }
+}
+
+// startSpan starts the span of the stream when the first record is read.
+func (d *Decoder) startSpan() {
+	if d.span == nil && !d.traced {
+		d.span = d.Tracer.Start("csv.decode")
+		d.traced = true
+	}
+}
+
+// traceResult records err in the span, ending it when the stream is done.
+func (d *Decoder) traceResult(err error) {
+	if d.span == nil || err == nil {
+		return
+	}
+	if d.reported(err) {
+		return
+	}
+	if err != io.EOF {
+		d.span.RecordError(err)
+	}
+	if err == io.EOF || d.err != nil {
+		d.endSpan()
+	}
+}
+
+// traceEvent adds an event to the span, if any.
+func (d *Decoder) traceEvent(name string, attrs map[string]interface{}) {
+	if d.span != nil {
+		d.span.AddEvent(name, attrs)
+	}
+}
+
+// endSpan ends the span, if any, recording the number of records read.
+func (d *Decoder) endSpan() {
+	if d.span == nil {
+		return
+	}
+	d.span.SetAttribute("csv.records", d.records)
+	d.span.SetAttribute("csv.bytes", d.offset+int64(d.scanp))
+	d.span.End()
+	d.span = nil
+}
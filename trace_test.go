@@ -0,0 +1,71 @@
+package csv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testSpan records the calls made to a Span.
+type testSpan struct {
+	name   string
+	attrs  map[string]interface{}
+	events []string
+	errs   []error
+	ended  int
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *testSpan) AddEvent(name string, attrs map[string]interface{}) {
+	s.events = append(s.events, name)
+}
+func (s *testSpan) RecordError(err error) { s.errs = append(s.errs, err) }
+func (s *testSpan) End()                  { s.ended++ }
+
+type testTracer struct{ spans []*testSpan }
+
+func (t *testTracer) Start(name string) Span {
+	s := &testSpan{name: name, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+func TestTracer(t *testing.T) {
+	tr := &testTracer{}
+	err := ForEach(strings.NewReader("a\nb\n"), func([]string) error { return nil }, WithTracer(tr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.spans) != 1 {
+		t.Fatalf("%d spans, want 1", len(tr.spans))
+	}
+	s := tr.spans[0]
+	if s.name != "csv.decode" || s.ended != 1 || s.attrs["csv.records"] != int64(2) || len(s.errs) != 0 {
+		t.Errorf("span %+v", s)
+	}
+
+	// a syntax error ends the span
+	tr = &testTracer{}
+	dec := NewDecoder(strings.NewReader("a\nb\"c\nd\n"))
+	dec.Tracer = tr
+	for i := 0; i < 4; i++ {
+		dec.Decode()
+	}
+	dec.Close()
+	s = tr.spans[0]
+	if len(tr.spans) != 1 || s.ended != 1 || len(s.errs) != 1 || !errors.Is(s.errs[0], ErrBareQuote) {
+		t.Errorf("syntax error: span %+v", s)
+	}
+
+	// sections are events
+	tr = &testTracer{}
+	dec = NewDecoder(strings.NewReader("h\n1\n\nh\n2\n"))
+	dec.Sections = true
+	dec.Tracer = tr
+	for _, ok := dec.NextSection(); ok; _, ok = dec.NextSection() {
+	}
+	dec.Close()
+	if s = tr.spans[0]; len(s.events) != 2 || s.ended != 1 {
+		t.Errorf("sections: span %+v", s)
+	}
+}
@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrTrailer is returned in a ParseError for a trailer record that does
+// not match the records before it, or that is followed by more records.
+var ErrTrailer = errors.New("trailer mismatch")
+
+// A Trailer describes a trailer record, such as "TRAILER,1042,88231.50",
+// that ends a file and summarizes its data records. Fields are indexed
+// from 0, which holds Tag, so a zero index disables a check.
+type Trailer struct {
+	// Tag is the first field of the trailer record.
+	Tag string
+	// CountField is the index of the field holding the number of data
+	// records, not counting the header read by ReadHeader.
+	CountField int
+	// TotalField is the index of the field holding the sum of the numbers
+	// in the data column TotalColumn, as used for hash totals.
+	TotalField  int
+	TotalColumn int
+}
+
+// TrailerRecord returns the fields of the trailer record, or nil if it has
+// not been read.
+func (d *Decoder) TrailerRecord() []string {
+	return d.trailer
+}
+
+// checkTrailer reports whether the current record is the trailer and
+// validates it. Other data records are counted towards it.
+func (d *Decoder) checkTrailer() (bool, error) {
+	t := d.Trailer
+	if t == nil || d.inHeader {
+		return false, nil
+	}
+	if string(d.field(0)) != t.Tag {
+		d.trailerCount++
+		if t.TotalField > 0 && t.TotalColumn < len(d.fieldIndexes) {
+			field := strings.TrimSpace(string(d.field(t.TotalColumn)))
+			if field != "" {
+				var v big.Rat
+				if _, ok := v.SetString(field); !ok {
+					d.column = 0 // report at start of record
+					d.err = &ConversionError{Field: t.TotalColumn, Value: field, Err: strconv.ErrSyntax}
+					return false, d.error(d.err)
+				}
+				d.trailerTotal.Add(&d.trailerTotal, &v)
+			}
+		}
+		return false, nil
+	}
+
+	d.trailer = d.fields(nil)
+	ok := true
+	if t.CountField > 0 {
+		n, err := strconv.ParseInt(strings.TrimSpace(fieldAt(d.trailer, t.CountField)), 10, 64)
+		ok = err == nil && n == d.trailerCount
+	}
+	if ok && t.TotalField > 0 {
+		var v big.Rat
+		_, valid := v.SetString(strings.TrimSpace(fieldAt(d.trailer, t.TotalField)))
+		ok = valid && v.Cmp(&d.trailerTotal) == 0
+	}
+	if !ok {
+		d.column = 0 // report at start of record
+		d.err = ErrTrailer
+		return true, d.error(d.err)
+	}
+	return true, nil
+}
+
+// afterTrailer ends the input after a valid trailer: it returns io.EOF, or
+// ErrTrailer if more records follow.
+func (d *Decoder) afterTrailer() error {
+	if _, err := d.peek(); err != nil {
+		d.err = io.EOF
+		return io.EOF
+	}
+	d.err = ErrTrailer
+	return &ParseError{Record: d.records + 1, Line: d.line + 1, Err: d.err}
+}
@@ -0,0 +1,99 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var trailerTests = []struct {
+	Name    string
+	Input   string
+	Records int
+	Trailer []string
+	Record  int64 // record of the expected error, if any
+}{
+	{
+		Name:    "Valid",
+		Input:   "id,amount\n1,10.25\n2,0.1\n3,\nTRAILER,3,10.35\n",
+		Records: 3,
+		Trailer: []string{"TRAILER", "3", "10.35"},
+	},
+	{
+		Name:    "NoTrailer",
+		Input:   "id,amount\n1,10.25\n",
+		Records: 1,
+	},
+	{
+		Name:    "BadCount",
+		Input:   "id,amount\n1,10.25\nTRAILER,2,10.25\n",
+		Records: 1,
+		Trailer: []string{"TRAILER", "2", "10.25"},
+		Record:  3,
+	},
+	{
+		Name:    "BadTotal",
+		Input:   "id,amount\n1,10.25\nTRAILER,1,10.2\n",
+		Records: 1,
+		Trailer: []string{"TRAILER", "1", "10.2"},
+		Record:  3,
+	},
+	{
+		Name:    "RecordAfterTrailer",
+		Input:   "id,amount\n1,5\nTRAILER,1,5\n\n2,5\n",
+		Records: 1,
+		Trailer: []string{"TRAILER", "1", "5"},
+		Record:  4,
+	},
+}
+
+func TestTrailer(t *testing.T) {
+	for _, tt := range trailerTests {
+		dec := NewDecoder(strings.NewReader(tt.Input))
+		dec.FieldsPerRecord = -1
+		dec.Trailer = &Trailer{Tag: "TRAILER", CountField: 1, TotalField: 2, TotalColumn: 1}
+		if _, err := dec.ReadHeader(); err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		var err error
+		for dec.More() {
+			if _, err = dec.Decode(); err != nil {
+				break
+			}
+			n++
+		}
+		if n != tt.Records {
+			t.Errorf("%s: %d records, want %d", tt.Name, n, tt.Records)
+		}
+		if got := dec.TrailerRecord(); !reflect.DeepEqual(got, tt.Trailer) {
+			t.Errorf("%s: trailer %q, want %q", tt.Name, got, tt.Trailer)
+		}
+		if tt.Record == 0 {
+			if err != nil && err != io.EOF {
+				t.Errorf("%s: unexpected error %v", tt.Name, err)
+			}
+			continue
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) || !errors.Is(err, ErrTrailer) || perr.Record != tt.Record {
+			t.Errorf("%s: error %v, want ErrTrailer at record %d", tt.Name, err, tt.Record)
+		}
+	}
+}
+
+func TestTrailerEndsInput(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("a\nT,1\n"))
+	dec.Trailer = &Trailer{Tag: "T", CountField: 1}
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Decode at trailer: %v, want io.EOF", err)
+	}
+	if dec.More() {
+		t.Error("More() after trailer")
+	}
+}
@@ -0,0 +1,180 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// A Transform rewrites the records of a stream, as applied by Reencode.
+type Transform interface {
+	// Header returns the output header given the input header. It is
+	// called once, before any record is applied.
+	Header(header []string) ([]string, error)
+	// Apply returns the output fields of a record of the input. The
+	// fields of r may be modified and returned.
+	Apply(r Record) ([]string, error)
+}
+
+// A TransformError is returned when a transform fails on a field.
+type TransformError struct {
+	Column string // name of the column
+	Value  string // value of the field
+	Err    error  // the actual error
+}
+
+func (e *TransformError) Error() string {
+	return fmt.Sprintf("column %s: cannot transform %q: %s", e.Column, e.Value, e.Err)
+}
+
+// Unwrap returns the actual error.
+func (e *TransformError) Unwrap() error {
+	return e.Err
+}
+
+// ReencodeOptions configures Reencode.
+type ReencodeOptions struct {
+	// Dialect describes the syntax of the input.
+	Dialect Dialect
+
+	// Delimiter is the field delimiter of the output. It is comma (',')
+	// when 0.
+	Delimiter byte
+	// If UseCRLF is true, output records are terminated by \r\n.
+	UseCRLF bool
+	// If NormalizeNewlines is true, the line breaks inside fields are
+	// also rewritten to \n, or to \r\n if UseCRLF is set, so the output
+	// uses a single kind of line ending throughout.
+	NormalizeNewlines bool
+	// If PreserveLineEndings is true, each output record is terminated
+	// like the input record it comes from, and the output lacks a final
+	// newline if the input does, overriding UseCRLF.
+	PreserveLineEndings bool
+	// LineEndings, if not nil, is set to the line terminators of the
+	// input when Reencode returns.
+	LineEndings *LineEndings
+
+	// Metrics, if not nil, receives the measurements of the decoder and
+	// the time spent in each transform.
+	Metrics Metrics
+}
+
+// Reencode reads records from r, whose first record is a header, passes
+// them through each of transforms in order, and writes the header and
+// records they return to w.
+func Reencode(r io.Reader, w io.Writer, opts ReencodeOptions, transforms ...Transform) error {
+	dec := NewDecoderDialect(r, opts.Dialect)
+	dec.FieldsPerRecord = -1
+	dec.Metrics = opts.Metrics
+	enc := NewEncoder(w)
+	if opts.Delimiter != 0 {
+		enc.Delimiter = opts.Delimiter
+	}
+	enc.UseCRLF = opts.UseCRLF
+	if opts.LineEndings != nil {
+		defer func() { *opts.LineEndings = dec.LineEndings() }()
+	}
+	write := enc.Encode
+	if opts.PreserveLineEndings {
+		write = func(record []string) error {
+			return enc.EncodeLine(record, dec.LineEnding())
+		}
+	}
+	encode := write
+	if opts.NormalizeNewlines {
+		encode = func(record []string) error {
+			for i, field := range record {
+				record[i] = normalizeNewlines(field, opts.UseCRLF)
+			}
+			return write(record)
+		}
+	}
+
+	if !dec.More() {
+		return nil
+	}
+	header, err := dec.Decode()
+	if err != nil {
+		return err
+	}
+	stages, header, err := newStages(header, transforms)
+	if err != nil {
+		return err
+	}
+	if err := encode(header); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			return err
+		}
+		if record, err = stages.apply(record, opts.Metrics); err != nil {
+			if opts.Metrics != nil {
+				opts.Metrics.Error(err)
+			}
+			dec.column = 0 // report at start of record
+			return dec.error(err)
+		}
+		if err := encode(record); err != nil {
+			return err
+		}
+	}
+	return enc.Flush()
+}
+
+// stages are transforms with the input header of each.
+type stages []stage
+
+type stage struct {
+	t      Transform
+	name   string // type of t, reported to Metrics
+	header Record // no fields; carries the input header and its index
+}
+
+// newStages computes the header of each transform and returns the stages
+// and the output header.
+func newStages(header []string, transforms []Transform) (stages, []string, error) {
+	s := make(stages, len(transforms))
+	for i, t := range transforms {
+		s[i] = stage{t: t, name: fmt.Sprintf("%T", t), header: NewRecord(header, nil)}
+		out, err := t.Header(header)
+		if err != nil {
+			return nil, nil, err
+		}
+		header = out
+	}
+	return s, header, nil
+}
+
+func (s stages) apply(record []string, m Metrics) ([]string, error) {
+	var err error
+	for _, st := range s {
+		r := st.header
+		r.Fields = record
+		var start time.Time
+		if m != nil {
+			start = time.Now()
+		}
+		if record, err = st.t.Apply(r); err != nil {
+			return nil, err
+		}
+		if m != nil {
+			m.Stage(st.name, time.Since(start))
+		}
+	}
+	return record, nil
+}
+
+// columnIndexes returns the index in header of each of names.
+func columnIndexes(header, names []string) ([]int, error) {
+	r := NewRecord(header, nil)
+	cols := make([]int, len(names))
+	for i, name := range names {
+		if cols[i] = r.Index(name); cols[i] < 0 {
+			return nil, &MismatchError{Report: &MismatchReport{Missing: []string{name}}}
+		}
+	}
+	return cols, nil
+}
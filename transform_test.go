@@ -0,0 +1,47 @@
+package csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// upper is a transform upper-casing every field, and the header.
+type upper struct{}
+
+func (upper) Header(header []string) ([]string, error) {
+	out := make([]string, len(header))
+	for i, name := range header {
+		out[i] = strings.ToUpper(name)
+	}
+	return out, nil
+}
+
+func (upper) Apply(r Record) ([]string, error) {
+	for i, field := range r.Fields {
+		r.Fields[i] = strings.ToUpper(field)
+	}
+	return r.Fields, nil
+}
+
+func TestReencode(t *testing.T) {
+	in := "name;city\nann;\"paris; fr\"\n"
+	var out bytes.Buffer
+	opts := ReencodeOptions{Dialect: Dialect{Delimiter: ';'}, Delimiter: '\t'}
+	if err := Reencode(strings.NewReader(in), &out, opts, upper{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "NAME\tCITY\nANN\tPARIS; FR\n"; got != want {
+		t.Errorf("output %q, want %q", got, want)
+	}
+}
+
+func TestReencodeEmpty(t *testing.T) {
+	var out bytes.Buffer
+	if err := Reencode(strings.NewReader(""), &out, ReencodeOptions{}, upper{}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output %q, want none", out.String())
+	}
+}
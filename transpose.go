@@ -0,0 +1,80 @@
+package csv
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrTransposeTooLarge is returned by ReadTransposed when its input does
+// not fit in TransposeOptions.MaxBytes.
+var ErrTransposeTooLarge = errors.New("csv: transposed input exceeds memory bound")
+
+// defaultTransposeSize is the default memory bound of ReadTransposed.
+const defaultTransposeSize = 64 << 20
+
+// TransposeOptions configures ReadTransposed.
+type TransposeOptions struct {
+	// Dialect describes the syntax of the input.
+	Dialect Dialect
+
+	// MaxBytes is the approximate number of bytes of fields held in memory.
+	// The whole input must be buffered before the first record can be
+	// produced, and ReadTransposed fails with ErrTransposeTooLarge beyond
+	// it. It defaults to 64 MiB.
+	MaxBytes int64
+}
+
+// ReadTransposed reads input in which each row holds one field of every
+// record, such as the exports of lab instruments, and calls fn with each
+// record in turn: the first record holds the first field of every row, the
+// second the second, and so on. Rows may differ in length; records are
+// padded with empty fields where a row is short. It stops at the first
+// error returned by the decoder or by fn and returns it.
+func ReadTransposed(r io.Reader, fn func([]string) error, opts TransposeOptions) error {
+	limit := opts.MaxBytes
+	if limit <= 0 {
+		limit = defaultTransposeSize
+	}
+
+	dec := NewDecoderDialect(r, opts.Dialect)
+	dec.FieldsPerRecord = -1
+	defer dec.Close()
+
+	var (
+		rows  [][]string
+		width int
+		size  int64
+	)
+	for dec.More() {
+		row, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for _, f := range row {
+			size += int64(len(f)) + 16 // string header
+		}
+		if size > limit {
+			return ErrTransposeTooLarge
+		}
+		if len(row) > width {
+			width = len(row)
+		}
+		rows = append(rows, row)
+	}
+
+	for j := 0; j < width; j++ {
+		record := make([]string, len(rows))
+		for i, row := range rows {
+			if j < len(row) {
+				record[i] = row[j]
+			}
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
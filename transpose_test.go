@@ -0,0 +1,48 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadTransposed(t *testing.T) {
+	in := "sample,A1,A2,A3\nod600,0.12,0.15\nstatus,ok,ok,\"bad, retry\"\n"
+	var got [][]string
+	err := ReadTransposed(strings.NewReader(in), func(record []string) error {
+		got = append(got, record)
+		return nil
+	}, TransposeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"sample", "od600", "status"},
+		{"A1", "0.12", "ok"},
+		{"A2", "0.15", "ok"},
+		{"A3", "", "bad, retry"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadTransposedErrors(t *testing.T) {
+	in := "a,b,c\nd,e,f\n"
+	noop := func([]string) error { return nil }
+	err := ReadTransposed(strings.NewReader(in), noop, TransposeOptions{MaxBytes: 64})
+	if err != ErrTransposeTooLarge {
+		t.Errorf("small bound: error %v, want ErrTransposeTooLarge", err)
+	}
+
+	errStop := errors.New("stop")
+	n := 0
+	err = ReadTransposed(strings.NewReader(in), func([]string) error {
+		n++
+		return errStop
+	}, TransposeOptions{})
+	if err != errStop || n != 1 {
+		t.Errorf("callback error: %v after %d records", err, n)
+	}
+}
@@ -0,0 +1,55 @@
+package csv
+
+import "strconv"
+
+// A TrimMode selects the white space trimmed from fields. Only spaces and
+// tabs outside quotes are trimmed.
+type TrimMode int
+
+const (
+	TrimNone     TrimMode = iota // keep white space
+	TrimLeading                  // trim before the field
+	TrimTrailing                 // trim after the field
+	TrimBoth                     // trim before and after the field
+)
+
+var trimModeNames = [...]string{"none", "leading", "trailing", "both"}
+
+func (m TrimMode) String() string {
+	if m < 0 || int(m) >= len(trimModeNames) {
+		return "TrimMode(" + strconv.Itoa(int(m)) + ")"
+	}
+	return trimModeNames[m]
+}
+
+// trimMode returns the trim mode of the i'th field.
+func (d *Decoder) trimMode(i int) TrimMode {
+	if col := d.schemaColumn(i); col != nil && col.Trim != nil {
+		return *col.Trim
+	}
+	return d.Trim
+}
+
+// beginField prepares the scanner for the field starting at the last of
+// fieldIndexes.
+func (d *Decoder) beginField() {
+	d.skipField = d.inputField < len(d.skipCols) && d.skipCols[d.inputField]
+	d.fieldTrim = d.trimMode(len(d.fieldIndexes) - 1)
+	d.scan.trimLeading = d.fieldTrim&TrimLeading != 0
+	d.scan.quoted = false
+}
+
+// endField trims trailing white space from the unquoted field ending at
+// the end of lineBuffer, in place.
+func (d *Decoder) endField() {
+	if d.fieldTrim&TrimTrailing == 0 || d.scan.quoted || d.skipField {
+		return
+	}
+	start := d.fieldIndexes[len(d.fieldIndexes)-1]
+	line := d.lineBuffer.Bytes()
+	end := len(line)
+	for end > start && (line[end-1] == ' ' || line[end-1] == '\t') {
+		end--
+	}
+	d.lineBuffer.Truncate(end)
+}
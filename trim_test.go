@@ -0,0 +1,122 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func trimMode(m TrimMode) *TrimMode { return &m }
+
+var trimTests = []struct {
+	Name   string
+	Input  string
+	Trim   TrimMode
+	Schema *Schema
+	Output [][]string
+}{
+	{
+		Name:   "None",
+		Input:  " a , b \n",
+		Output: [][]string{{" a ", " b "}},
+	},
+	{
+		Name:   "Leading",
+		Input:  " a , b \n",
+		Trim:   TrimLeading,
+		Output: [][]string{{"a ", "b "}},
+	},
+	{
+		Name:   "Trailing",
+		Input:  " a ,\tb\t\n c \r\n",
+		Trim:   TrimTrailing,
+		Output: [][]string{{" a", "\tb"}, {" c"}},
+	},
+	{
+		Name:   "Both",
+		Input:  "  a  ,  ,b  ",
+		Trim:   TrimBoth,
+		Output: [][]string{{"a", "", "b"}},
+	},
+	{
+		Name:   "Tabs",
+		Input:  "\ta,\t b\t ,c\n",
+		Trim:   TrimBoth,
+		Output: [][]string{{"a", "b", "c"}},
+	},
+	{
+		Name:   "Quoted",
+		Input:  ` " a ", "b "` + "\n",
+		Trim:   TrimLeading,
+		Output: [][]string{{" a ", "b "}},
+	},
+	{
+		Name:  "PerColumn",
+		Input: " a , b , c \n",
+		Trim:  TrimBoth,
+		Schema: &Schema{Columns: []Column{
+			{Trim: trimMode(TrimNone)},
+			{Trim: trimMode(TrimTrailing)},
+		}},
+		Output: [][]string{{" a ", " b", "c"}},
+	},
+}
+
+func TestTrim(t *testing.T) {
+	for _, tt := range trimTests {
+		dec := NewDecoder(strings.NewReader(tt.Input))
+		dec.FieldsPerRecord = -1
+		dec.Trim = tt.Trim
+		dec.Schema = tt.Schema
+
+		var out [][]string
+		for dec.More() {
+			record, err := dec.Decode()
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.Name, err)
+				break
+			}
+			out = append(out, record)
+		}
+		if !reflect.DeepEqual(out, tt.Output) {
+			t.Errorf("%s: out=%q want %q", tt.Name, out, tt.Output)
+		}
+	}
+}
+
+func TestTrimTabs(t *testing.T) {
+	dec := NewDecoderDialect(strings.NewReader(" a\t\t b\n"), Dialect{Delimiter: '\t'})
+	dec.Trim = TrimLeading
+	record, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "", "b"}; !reflect.DeepEqual(record, want) {
+		t.Errorf("tab delimiter: record %q, want %q", record, want)
+	}
+
+	// TrimLeadingSpace only trims spaces
+	dec = NewDecoder(strings.NewReader("a,\t b\n"))
+	dec.scan.TrimLeadingSpace = true
+	if record, err = dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "\t b"}; !reflect.DeepEqual(record, want) {
+		t.Errorf("TrimLeadingSpace: record %q, want %q", record, want)
+	}
+}
+
+func TestTrimPerColumnByName(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("id,name\n 1 , x \n"))
+	dec.Schema = &Schema{Columns: []Column{{Name: "name", Trim: trimMode(TrimBoth)}}}
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	record, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{" 1 ", "x"}; !reflect.DeepEqual(record, want) {
+		t.Errorf("record %q, want %q", record, want)
+	}
+}
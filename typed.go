@@ -0,0 +1,44 @@
+package csv
+
+import (
+	"io"
+	"iter"
+)
+
+// A TypedDecoder decodes the records of a stream into values of the
+// struct type T, binding columns to fields as DecodeStruct does. The
+// fields of T are resolved once per type. The embedded Decoder is used to
+// configure the stream and to read its header.
+type TypedDecoder[T any] struct {
+	*Decoder
+}
+
+// NewTypedDecoder returns a decoder of values of type T reading from r.
+func NewTypedDecoder[T any](r io.Reader) *TypedDecoder[T] {
+	return &TypedDecoder[T]{Decoder: NewDecoder(r)}
+}
+
+// Decode reads the next record into a new value of type T. It returns
+// io.EOF at the end of the input.
+func (d *TypedDecoder[T]) Decode() (T, error) {
+	var v T
+	err := d.Decoder.DecodeStruct(&v)
+	return v, err
+}
+
+// All returns an iterator over the values of the remaining records and
+// the errors decoding them. It stops at the end of the input or after an
+// error the decoder cannot recover from.
+func (d *TypedDecoder[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			v, err := d.Decode()
+			if err == io.EOF {
+				return
+			}
+			if !yield(v, err) || err != nil && d.err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type typedPerson struct {
+	Name string `csv:"name"`
+	Age  int    `csv:"age"`
+}
+
+func TestTypedDecoder(t *testing.T) {
+	dec := NewTypedDecoder[typedPerson](strings.NewReader("age,name\n30,ann\n9,bob\n"))
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	p, err := dec.Decode()
+	if err != nil || p != (typedPerson{"ann", 30}) {
+		t.Errorf("Decode = %+v, %v", p, err)
+	}
+	p, _ = dec.Decode()
+	if p != (typedPerson{"bob", 9}) {
+		t.Errorf("Decode = %+v", p)
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("at end: error %v, want io.EOF", err)
+	}
+}
+
+func TestTypedDecoderAll(t *testing.T) {
+	in := "name,age,sum\nann,30,6c74de58\nbob,x,6a29b98b\ncid,7,00000000\ndan,5,04c1ff51\n"
+	dec := NewTypedDecoder[typedPerson](strings.NewReader(in))
+	dec.Checksum = CRC32Checksum
+	dec.ReadHeader()
+
+	var names []string
+	var errs int
+	for p, err := range dec.All() {
+		if err != nil {
+			errs++
+			continue
+		}
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "ann,dan" || errs != 2 {
+		t.Errorf("names %q, %d errors", names, errs)
+	}
+
+	// stops after an error the decoder cannot recover from
+	dec = NewTypedDecoder[typedPerson](strings.NewReader("ann,1\nb\"ob,2\ncid,3\n"))
+	n := 0
+	for _, err := range dec.All() {
+		n++
+		if n == 2 && !errors.Is(err, ErrBareQuote) {
+			t.Errorf("error %v, want ErrBareQuote", err)
+		}
+	}
+	if n != 2 {
+		t.Errorf("%d values, want 2", n)
+	}
+}
@@ -0,0 +1,193 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+)
+
+// ErrUploadTooLarge is returned when an upload exceeds
+// UploadOptions.MaxBytes.
+var ErrUploadTooLarge = errors.New("csv: upload too large")
+
+// sniffSize is the number of bytes at the start of an upload used to
+// detect its delimiter, and sniffLines the number of lines looked at.
+const (
+	sniffSize  = 4096
+	sniffLines = 20
+)
+
+// sniffDelimiters are the delimiters UploadOptions.Sniff chooses from.
+const sniffDelimiters = ",;\t|"
+
+// UploadOptions configures the decoding of a file uploaded in a multipart
+// form.
+type UploadOptions struct {
+	// Field is the form field of the file. The first file in the form is
+	// read if it is empty.
+	Field string
+
+	// MaxBytes, if positive, is the size of the largest upload read.
+	// Reading a larger one fails with ErrUploadTooLarge once MaxBytes
+	// bytes were read.
+	MaxBytes int64
+
+	// Dialect is the dialect of the file. If Sniff is true, a byte order
+	// mark and a "sep=" line at the start of the file, as written by
+	// Excel, are skipped, the delimiter of the line is used, and if there
+	// is none and Dialect.Delimiter is 0, the delimiter is detected from
+	// the first records.
+	Dialect Dialect
+	Sniff   bool
+}
+
+// NewDecoderMultipart returns a decoder reading the file of a multipart
+// form, as returned by http.Request.MultipartReader, and its part, whose
+// FileName is the name of the uploaded file. The file is decoded as it
+// is received, without buffering it in memory or on disk. The parts
+// before it are skipped, and those after it are not read.
+func NewDecoderMultipart(mr *multipart.Reader, opts UploadOptions) (*Decoder, *multipart.Part, error) {
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, nil, errors.New("csv: no file in upload")
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if p.FileName() != "" && (opts.Field == "" || p.FormName() == opts.Field) {
+			d, err := NewDecoderPart(p, opts)
+			return d, p, err
+		}
+		p.Close()
+	}
+}
+
+// NewDecoderPart returns a decoder reading the uploaded file p.
+func NewDecoderPart(p *multipart.Part, opts UploadOptions) (*Decoder, error) {
+	var r io.Reader = p
+	if opts.MaxBytes > 0 {
+		r = &uploadLimitReader{r: r, n: opts.MaxBytes}
+	}
+	dialect := opts.Dialect
+	if opts.Sniff {
+		br := bufio.NewReaderSize(r, sniffSize)
+		sample, err := br.Peek(sniffSize)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		dialect = sniffDialect(sample, err == io.EOF, dialect)
+		r = br
+	}
+	return NewDecoderDialect(r, dialect), nil
+}
+
+// sniffDialect completes dialect from sample, the start of the input. A
+// byte order mark and a "sep=" line, as written by Excel, are skipped;
+// the delimiter of the line is used, and otherwise it is detected from the
+// records if dialect has none.
+func sniffDialect(sample []byte, complete bool, dialect Dialect) Dialect {
+	if bytes.HasPrefix(sample, []byte(bom)) {
+		dialect.SkipBOM = true
+		sample = sample[len(bom):]
+	}
+	if len(sample) >= 5 && bytes.EqualFold(sample[:4], []byte("sep=")) {
+		if rest := sample[5:]; len(rest) == 0 || rest[0] == '\n' || rest[0] == '\r' {
+			dialect.SepDirective = true
+			dialect.Delimiter = sample[4]
+			return dialect
+		}
+	}
+	if dialect.Delimiter == 0 {
+		dialect.Delimiter = sniffDelimiter(sample, complete)
+	}
+	return dialect
+}
+
+// sniffDelimiter returns the delimiter of sniffDelimiters splitting the
+// lines of sample into the same number of fields, the most fields if
+// several do. The last line is ignored unless complete, as it may be cut
+// short. It returns comma if no delimiter fits.
+func sniffDelimiter(sample []byte, complete bool) byte {
+	best, bestFields := byte(','), 1
+	for i := 0; i < len(sniffDelimiters); i++ {
+		if n := sniffFields(sample, sniffDelimiters[i], complete); n > bestFields {
+			best, bestFields = sniffDelimiters[i], n
+		}
+	}
+	return best
+}
+
+// sniffFields returns the number of fields of the lines of sample split
+// by delim, or 0 if it differs between lines.
+func sniffFields(sample []byte, delim byte, complete bool) int {
+	fields, want, lines := 1, 0, 0
+	quoted, blank := false, true
+	endLine := func() bool {
+		if !blank {
+			if want != 0 && fields != want {
+				return false
+			}
+			want = fields
+			lines++
+		}
+		fields, blank = 1, true
+		return true
+	}
+	for _, c := range sample {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\n':
+			if !endLine() {
+				return 0
+			}
+			if lines == sniffLines {
+				return want
+			}
+			continue
+		case c == delim:
+			fields++
+		}
+		if c != '\r' {
+			blank = false
+		}
+	}
+	if complete && !quoted && !endLine() {
+		return 0
+	}
+	return want
+}
+
+// An uploadLimitReader reads at most n bytes from r, and fails with
+// ErrUploadTooLarge if r has more.
+type uploadLimitReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (l *uploadLimitReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if l.n <= 0 {
+		// probe for more input
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			l.err = ErrUploadTooLarge
+			return 0, l.err
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
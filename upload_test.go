@@ -0,0 +1,107 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// multipartBody returns a multipart form with a text field and a file.
+func multipartBody(t *testing.T, file string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("comment", "monthly")
+	fw, err := w.CreateFormFile("data", "orders.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(fw, file)
+	w.Close()
+	return &body, w.Boundary()
+}
+
+func TestNewDecoderMultipart(t *testing.T) {
+	for _, tt := range []struct {
+		file  string
+		opts  UploadOptions
+		first []string
+	}{
+		{"id;name\n1;\"a;b\"\n", UploadOptions{Sniff: true}, []string{"id", "name"}},
+		{"id\tname\n1\tx\n", UploadOptions{Field: "data", Sniff: true}, []string{"id", "name"}},
+		{"id;name\n", UploadOptions{}, []string{"id;name"}},
+		{"a|b|c\n1|2|3", UploadOptions{Sniff: true}, []string{"a", "b", "c"}},
+		{"sep=;\r\nid;name\r\n1;x\r\n", UploadOptions{Sniff: true}, []string{"id", "name"}},
+		{"\ufeffSEP=|\nid|name\n", UploadOptions{Sniff: true}, []string{"id", "name"}},
+		{"sep=;\nid;name\n", UploadOptions{Sniff: true, Dialect: Dialect{Delimiter: ','}}, []string{"id", "name"}},
+		{"\ufeffid,name\n", UploadOptions{Sniff: true}, []string{"id", "name"}},
+	} {
+		body, boundary := multipartBody(t, tt.file)
+		dec, part, err := NewDecoderMultipart(multipart.NewReader(body, boundary), tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if part.FileName() != "orders.csv" {
+			t.Errorf("file name %q", part.FileName())
+		}
+		if fields, err := dec.Decode(); err != nil || !reflect.DeepEqual(fields, tt.first) {
+			t.Errorf("%q: Decode() = %q, %v, want %q", tt.file, fields, err, tt.first)
+		}
+	}
+}
+
+func TestNewDecoderMultipartNoFile(t *testing.T) {
+	body, boundary := multipartBody(t, "a\n")
+	if _, _, err := NewDecoderMultipart(multipart.NewReader(body, boundary), UploadOptions{Field: "other"}); err == nil {
+		t.Error("no error without the file field")
+	}
+}
+
+func TestUploadMaxBytes(t *testing.T) {
+	file := "id\n" + strings.Repeat("12345\n", 100)
+	for _, max := range []int64{int64(len(file)), 100} {
+		body, boundary := multipartBody(t, file)
+		dec, _, err := NewDecoderMultipart(multipart.NewReader(body, boundary), UploadOptions{MaxBytes: max})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for err == nil {
+			_, err = dec.Decode()
+		}
+		if max == 100 && !errors.Is(err, ErrUploadTooLarge) {
+			t.Errorf("limit %d: error %v, want %v", max, err, ErrUploadTooLarge)
+		}
+		if max != 100 && err != io.EOF {
+			t.Errorf("limit %d: error %v, want EOF", max, err)
+		}
+	}
+
+	// the sample read for sniffing is already too large
+	body, boundary := multipartBody(t, file)
+	_, _, err := NewDecoderMultipart(multipart.NewReader(body, boundary), UploadOptions{MaxBytes: 100, Sniff: true})
+	if !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("sniffing: error %v, want %v", err, ErrUploadTooLarge)
+	}
+}
+
+func TestSniffDelimiter(t *testing.T) {
+	for _, tt := range []struct {
+		sample   string
+		complete bool
+		want     byte
+	}{
+		{"a,b\n1,2\n", true, ','},
+		{"a;b;c\n1;2;3\n", true, ';'},
+		{"a;b\n1,2;3\n", true, ';'},
+		{"\"x,y\";z\n1;2\n", true, ';'},
+		{"a|b\n1|2\n3|4|", false, '|'},
+		{"single\ncolumn\n", true, ','},
+	} {
+		if got := sniffDelimiter([]byte(tt.sample), tt.complete); got != tt.want {
+			t.Errorf("sniffDelimiter(%q) = %q, want %q", tt.sample, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package csv
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// ErrInvalidUTF8 is returned in a ParseError for records that are not
+// valid UTF-8 under UTF8Error.
+var ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+// A UTF8Policy controls how the decoder handles invalid UTF-8.
+type UTF8Policy int
+
+const (
+	// UTF8PassThrough returns fields as read, without validation.
+	UTF8PassThrough UTF8Policy = iota
+	// UTF8Error makes Decode fail with ErrInvalidUTF8. The Offset of the
+	// ParseError is that of the first invalid byte.
+	UTF8Error
+	// UTF8Replace replaces each run of invalid bytes with U+FFFD.
+	UTF8Replace
+)
+
+// checkUTF8 applies InvalidUTF8 to the current record, whose raw bytes
+// start at buf[start].
+func (d *Decoder) checkUTF8(start int) error {
+	if d.InvalidUTF8 == UTF8PassThrough {
+		return nil
+	}
+	raw, valid := d.raw, false
+	if d.spilled > 0 {
+		// the start of the record is no longer buffered, and raw may
+		// start in the middle of a rune: check the decoded fields, and
+		// look for the invalid byte after the rune cut short
+		valid = utf8.Valid(d.lineBuffer.Bytes())
+		skip := 0
+		for skip < utf8.UTFMax-1 && skip < len(raw) && !utf8.RuneStart(raw[skip]) {
+			skip++
+		}
+		raw = raw[skip:]
+	} else {
+		valid = utf8.Valid(raw)
+	}
+	if valid {
+		return nil
+	}
+
+	if d.InvalidUTF8 == UTF8Error {
+		field := 0
+		for field+1 < len(d.fieldIndexes) && utf8.Valid(d.field(field)) {
+			field++
+		}
+		d.err = ErrInvalidUTF8
+		perr := &ParseError{
+			Record: d.records,
+			Line:   d.recordLine,
+			Field:  field,
+			Offset: d.offset + int64(start) - d.spilled,
+			Err:    d.err,
+		}
+		if i := invalidUTF8(raw); i < len(raw) {
+			i += len(d.raw) - len(raw)
+			perr.Offset = d.offset + int64(start+i)
+			perr.Snippet = snippet(d.buf, start+i)
+		}
+		return perr
+	}
+
+	// rewrite the fields into utf8Buf and swap it with lineBuffer
+	d.utf8Buf = d.utf8Buf[:0]
+	for i := range d.fieldIndexes {
+		field := d.field(i)
+		d.fieldIndexes[i] = len(d.utf8Buf)
+		d.utf8Buf = appendValidUTF8(d.utf8Buf, field)
+	}
+	d.lineBuffer.Reset()
+	d.lineBuffer.Write(d.utf8Buf)
+	return nil
+}
+
+// invalidUTF8 returns the index of the first invalid byte of b.
+func invalidUTF8(b []byte) int {
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return len(b)
+}
+
+// appendValidUTF8 appends b to dst with each run of invalid bytes replaced
+// by U+FFFD.
+func appendValidUTF8(dst, b []byte) []byte {
+	invalid := false
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			if !invalid {
+				dst = append(dst, "\uFFFD"...)
+			}
+			invalid = true
+		} else {
+			dst = append(dst, b[i:i+size]...)
+			invalid = false
+		}
+		i += size
+	}
+	return dst
+}
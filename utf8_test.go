@@ -0,0 +1,54 @@
+package csv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInvalidUTF8(t *testing.T) {
+	input := "a,b\nok,caf\xe9 \xff\xfe!\n\"x\xc3\",y\n"
+
+	dec := NewDecoder(strings.NewReader(input))
+	var out [][]string
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, record)
+	}
+	if out[1][1] != "caf\xe9 \xff\xfe!" {
+		t.Errorf("pass through changed field to %q", out[1][1])
+	}
+
+	dec = NewDecoder(strings.NewReader(input))
+	dec.InvalidUTF8 = UTF8Replace
+	out = nil
+	for dec.More() {
+		record, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, record)
+	}
+	want := [][]string{{"a", "b"}, {"ok", "caf\uFFFD \uFFFD!"}, {"x\uFFFD", "y"}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("replaced %q, want %q", out, want)
+	}
+
+	dec = NewDecoder(strings.NewReader(input))
+	dec.InvalidUTF8 = UTF8Error
+	err := firstError(dec)
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrInvalidUTF8) {
+		t.Fatalf("error %v, want ErrInvalidUTF8", err)
+	}
+	if want := int64(strings.IndexByte(input, 0xe9)); perr.Offset != want {
+		t.Errorf("offset %d, want %d", perr.Offset, want)
+	}
+	if perr.Record != 2 || perr.Line != 2 || perr.Field != 1 {
+		t.Errorf("error at record %d, line %d, field %d", perr.Record, perr.Line, perr.Field)
+	}
+}
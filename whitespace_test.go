@@ -0,0 +1,57 @@
+package csv
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWhitespaceDelimiter(t *testing.T) {
+	tests := []struct {
+		in      string
+		dialect Dialect
+		want    [][]string
+	}{
+		{"a  b\tc\n", Dialect{}, [][]string{{"a", "b", "c"}}},
+		{"  PID   CMD  \n  1  init\t\n", Dialect{}, [][]string{{"PID", "CMD"}, {"1", "init"}}},
+		{"\"a b\"  c\r\nd \"\"\r\n", Dialect{}, [][]string{{"a b", "c"}, {"d", ""}}},
+		{"a b\n\n   \n\t\nc d", Dialect{}, [][]string{{"a", "b"}, {"c", "d"}}},
+		{"a b  # note\nc d\n", Dialect{Comment: '#', InlineComments: true}, [][]string{{"a", "b"}, {"c", "d"}}},
+		{"a,b c\n", Dialect{}, [][]string{{"a,b", "c"}}},
+	}
+	for _, tt := range tests {
+		for _, size := range []int{1, 4096} {
+			tt.dialect.Whitespace = true
+			dec := NewDecoderDialect(strings.NewReader(tt.in), tt.dialect)
+			dec.SetBufferSize(size)
+			var got [][]string
+			for {
+				fields, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%q: %v", tt.in, err)
+				}
+				got = append(got, fields)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q, size %d: records %q, want %q", tt.in, size, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestWhitespaceFieldPos(t *testing.T) {
+	dec := NewDecoderDialect(strings.NewReader("  ab   cd\n"), Dialect{Whitespace: true})
+	if _, err := dec.Decode(); err != nil {
+		t.Fatal(err)
+	}
+	if line, col := dec.FieldPos(1); line != 1 || col != 8 {
+		t.Errorf("FieldPos(1) = %d, %d, want 1, 8", line, col)
+	}
+	if d := dec.Dialect(); !d.Whitespace || d.Delimiter != ' ' {
+		t.Errorf("Dialect() = %+v", d)
+	}
+}
@@ -0,0 +1,141 @@
+package csv
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// WindowOptions configures a WindowSink.
+type WindowOptions struct {
+	// TimeColumn is the column holding the time of each record. It is
+	// parsed with TimeLayout, or as RFC 3339 or a date and time such as
+	// "2006-01-02 15:04:05" if it is empty, in TimeLocation, or UTC if it
+	// is nil.
+	TimeColumn   int
+	TimeLayout   string
+	TimeLocation *time.Location
+
+	// Size is the length of the windows. Windows are aligned on multiples
+	// of Size since the zero time, so one-minute windows start on the
+	// minute. It defaults to one minute.
+	Size time.Duration
+
+	// AllowedLateness is how long a window stays open after records with
+	// a later time have been seen, for records arriving out of order.
+	AllowedLateness time.Duration
+
+	// GroupBy and Aggregations are the keys and aggregates computed for
+	// each window, as by an Aggregator.
+	GroupBy      []int
+	Aggregations []Aggregation
+
+	// Late, if not nil, receives the records arriving after their window
+	// was emitted. They are dropped otherwise.
+	Late Sink
+}
+
+// A WindowSink is a Sink grouping records into tumbling windows by the
+// time in one of their columns, and writing the aggregates of each window
+// to another sink once the window is complete. A window is complete once a
+// record more than AllowedLateness after its end has been seen; the rest
+// are written when the sink is closed.
+//
+// The records written have a column for the start and the end of their
+// window, in RFC 3339, followed by the group keys and the aggregates, one
+// record per group sorted by key. Windows are written in order.
+type WindowSink struct {
+	out     Sink
+	opts    WindowOptions
+	header  []string
+	windows map[int64]*Aggregator // by start, in nanoseconds
+	latest  time.Time             // latest time seen
+	seen    bool
+}
+
+// NewWindowSink returns a sink writing the aggregates of windows of the
+// records written to it to out.
+func NewWindowSink(out Sink, opts WindowOptions) *WindowSink {
+	if opts.Size <= 0 {
+		opts.Size = time.Minute
+	}
+	return &WindowSink{out: out, opts: opts, windows: make(map[int64]*Aggregator)}
+}
+
+func (s *WindowSink) Write(r Record) error {
+	if s.header == nil {
+		s.header = append([]string{"window_start", "window_end"},
+			NewAggregator(AggregateOptions{GroupBy: s.opts.GroupBy, Aggregations: s.opts.Aggregations}).Header(r.Header())...)
+	}
+	field := fieldAt(r.Fields, s.opts.TimeColumn)
+	t, err := parseTime(field, s.opts.TimeLayout, s.opts.TimeLocation)
+	if err != nil {
+		return &ConversionError{Field: s.opts.TimeColumn, Value: field, Type: reflect.TypeOf(t), Err: err}
+	}
+	start := t.Truncate(s.opts.Size)
+	if s.seen && !start.Add(s.opts.Size).After(s.watermark()) {
+		if s.opts.Late != nil {
+			return s.opts.Late.Write(r)
+		}
+		return nil
+	}
+
+	a := s.windows[start.UnixNano()]
+	if a == nil {
+		a = NewAggregator(AggregateOptions{GroupBy: s.opts.GroupBy, Aggregations: s.opts.Aggregations})
+		s.windows[start.UnixNano()] = a
+	}
+	if err := a.Add(r.Fields); err != nil {
+		return err
+	}
+	if !s.seen || t.After(s.latest) {
+		s.latest, s.seen = t, true
+		return s.emit(false)
+	}
+	return nil
+}
+
+// watermark returns the time before which windows are complete.
+func (s *WindowSink) watermark() time.Time {
+	return s.latest.Add(-s.opts.AllowedLateness)
+}
+
+// emit writes the complete windows, or all of them if all is true, in
+// order.
+func (s *WindowSink) emit(all bool) error {
+	var starts []int64
+	for start := range s.windows {
+		end := time.Unix(0, start).Add(s.opts.Size)
+		if all || !end.After(s.watermark()) {
+			starts = append(starts, start)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	for _, start := range starts {
+		t := time.Unix(0, start).In(s.latest.Location())
+		bounds := []string{t.Format(time.RFC3339Nano), t.Add(s.opts.Size).Format(time.RFC3339Nano)}
+		err := s.windows[start].Results(func(record []string) error {
+			return s.out.Write(NewRecord(s.header, append(bounds, record...)))
+		})
+		if err != nil {
+			return err
+		}
+		delete(s.windows, start)
+	}
+	return nil
+}
+
+// Close writes the windows not written yet and closes the output sink and
+// Late.
+func (s *WindowSink) Close() error {
+	err := s.emit(true)
+	if cerr := s.out.Close(); err == nil {
+		err = cerr
+	}
+	if s.opts.Late != nil {
+		if cerr := s.opts.Late.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWindowSink(t *testing.T) {
+	in := "time,sensor,temp\n" +
+		"2024-01-01T10:00:05Z,a,20\n" +
+		"2024-01-01T10:00:40Z,b,10\n" +
+		"2024-01-01T10:00:50Z,a,22\n" +
+		"2024-01-01T10:01:10Z,a,30\n" + // opens the second window
+		"2024-01-01T10:00:55Z,b,12\n" + // out of order, within the lateness
+		"2024-01-01T10:01:40Z,a,31\n" + // completes the first window
+		"2024-01-01T10:00:59Z,b,99\n" + // too late
+		"2024-01-01T10:02:00Z,b,5\n"
+
+	var got [][]string
+	var header []string
+	var late []string
+	out := SinkFunc(func(r Record) error {
+		header = r.Header()
+		got = append(got, append([]string(nil), r.Fields...))
+		return nil
+	})
+	s := NewWindowSink(out, WindowOptions{
+		TimeColumn:      0,
+		AllowedLateness: 30 * time.Second,
+		GroupBy:         []int{1},
+		Aggregations:    []Aggregation{{Func: Count}, {Func: Avg, Column: 2}},
+		Late: SinkFunc(func(r Record) error {
+			late = append(late, r.Fields[2])
+			return nil
+		}),
+	})
+
+	dec := NewDecoder(strings.NewReader(in))
+	if _, err := dec.ReadHeader(); err != nil {
+		t.Fatal(err)
+	}
+	emitted := 0
+	for dec.More() {
+		r, err := dec.DecodeRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Write(r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Fields[0] == "2024-01-01T10:01:40Z" {
+			emitted = len(got)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"window_start", "window_end", "sensor", "count", "avg(temp)"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header %q, want %q", header, want)
+	}
+	want := [][]string{
+		{"2024-01-01T10:00:00Z", "2024-01-01T10:01:00Z", "a", "2", "21"},
+		{"2024-01-01T10:00:00Z", "2024-01-01T10:01:00Z", "b", "2", "11"},
+		{"2024-01-01T10:01:00Z", "2024-01-01T10:02:00Z", "a", "2", "30.5"},
+		{"2024-01-01T10:02:00Z", "2024-01-01T10:03:00Z", "b", "1", "5"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+	if emitted != 2 {
+		t.Errorf("%d records emitted once the first window completed, want 2", emitted)
+	}
+	if !reflect.DeepEqual(late, []string{"99"}) {
+		t.Errorf("late records %q", late)
+	}
+}
+
+func TestWindowSinkBadTime(t *testing.T) {
+	s := NewWindowSink(SinkFunc(func(Record) error { return nil }), WindowOptions{TimeLayout: "15:04"})
+	if err := s.Write(NewRecord(nil, []string{"later"})); err == nil {
+		t.Error("bad time accepted")
+	}
+}